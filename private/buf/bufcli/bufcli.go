@@ -26,6 +26,7 @@ import (
 
 	"github.com/bufbuild/buf/private/buf/bufapp"
 	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/buf/bufgen"
 	"github.com/bufbuild/buf/private/buf/bufwire"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufapiclient"
@@ -77,6 +78,10 @@ const (
 	inputHashtagFlagShortName = "#"
 
 	userPromptAttempts = 3
+
+	// generateCacheMaxSizeBytes is the size of the generate cache above which the least
+	// recently used entries are deleted.
+	generateCacheMaxSizeBytes = 512 << 20
 )
 
 var (
@@ -146,6 +151,11 @@ var (
 	// These digests are used to make sure that the data written is actually what we expect, and if it is not,
 	// we clear an entry from the cache, i.e. delete the relevant data directory.
 	v1CacheModuleSumRelDirPath = normalpath.Join("v1", "module", "sum")
+	// v1CacheGenerateRelDirPath is the relative path to the cache directory where plugin responses
+	// for buf generate are stored.
+	//
+	// Normalized.
+	v1CacheGenerateRelDirPath = normalpath.Join("v1", "generate")
 )
 
 // GlobalFlags contains global flags for buf commands.
@@ -449,6 +459,20 @@ func NewWireImageWriter(
 	)
 }
 
+// NewWireProtoEncodingReader returns a new ProtoEncodingReader.
+func NewWireProtoEncodingReader(
+	logger *zap.Logger,
+	storageosProvider storageos.Provider,
+) bufwire.ProtoEncodingReader {
+	return bufwire.NewProtoEncodingReader(
+		logger,
+		buffetch.NewProtoEncodingReader(
+			logger,
+			storageosProvider,
+		),
+	)
+}
+
 // NewWireProtoEncodingWriter returns a new ProtoEncodingWriter.
 func NewWireProtoEncodingWriter(
 	logger *zap.Logger,
@@ -513,6 +537,27 @@ func NewModuleReaderAndCreateCacheDirs(
 	return moduleReader, nil
 }
 
+// NewGenerateCacheAndCreateCacheDir returns a new bufgen.Cache while creating the
+// required cache directory.
+func NewGenerateCacheAndCreateCacheDir(container appflag.Container) (bufgen.Cache, error) {
+	cacheGenerateDirPath := normalpath.Join(container.CacheDirPath(), v1CacheGenerateRelDirPath)
+	if err := checkExistingCacheDirs(
+		container.CacheDirPath(),
+		container.CacheDirPath(),
+		cacheGenerateDirPath,
+	); err != nil {
+		return nil, err
+	}
+	if err := createCacheDirs(cacheGenerateDirPath); err != nil {
+		return nil, err
+	}
+	return bufgen.NewCache(
+		container.Logger(),
+		normalpath.Unnormalize(cacheGenerateDirPath),
+		generateCacheMaxSizeBytes,
+	), nil
+}
+
 // NewConfig creates a new Config.
 func NewConfig(container appflag.Container) (*bufapp.Config, error) {
 	externalConfig := bufapp.ExternalConfig{}
@@ -36,6 +36,15 @@ const (
 	ImageEncodingJSON
 )
 
+const (
+	// MessageEncodingBin is the binary message encoding.
+	MessageEncodingBin MessageEncoding = iota + 1
+	// MessageEncodingJSON is the JSON message encoding.
+	MessageEncodingJSON
+	// MessageEncodingTxt is the protobuf text message encoding.
+	MessageEncodingTxt
+)
+
 var (
 	// ImageFormatsString is the string representation of all image formats.
 	//
@@ -59,11 +68,16 @@ var (
 	//
 	// This does not include deprecated formats.
 	AllFormatsString = stringutil.SliceToString(allFormatsNotDeprecated)
+	// ProtoEncodingFormatsString is the string representation of all proto encoding formats.
+	ProtoEncodingFormatsString = stringutil.SliceToString(protoEncodingFormats)
 )
 
 // ImageEncoding is the encoding of the image.
 type ImageEncoding int
 
+// MessageEncoding is the encoding of the message.
+type MessageEncoding int
+
 // PathResolver resolves external paths to paths.
 type PathResolver interface {
 	// PathForExternalPath takes a path external to the asset and converts it to
@@ -123,6 +137,17 @@ type ProtoFileRef interface {
 	internalProtoFileRef() internal.ProtoFileRef
 }
 
+// ProtoEncodingRef is a proto encoding file reference.
+type ProtoEncodingRef interface {
+	// Path is the path to the reference.
+	//
+	// This will be empty for stdio and null files.
+	Path() string
+	MessageEncoding() MessageEncoding
+	IsNull() bool
+	internalSingleRef() internal.SingleRef
+}
+
 // ImageRefParser is an image ref parser for Buf.
 type ImageRefParser interface {
 	// GetImageRef gets the reference for the image file.
@@ -152,6 +177,12 @@ type SourceOrModuleRefParser interface {
 	GetSourceOrModuleRef(ctx context.Context, value string) (SourceOrModuleRef, error)
 }
 
+// ProtoEncodingRefParser is a proto encoding ref parser for Buf.
+type ProtoEncodingRefParser interface {
+	// GetProtoEncodingRef gets the reference for the message file.
+	GetProtoEncodingRef(ctx context.Context, value string) (ProtoEncodingRef, error)
+}
+
 // RefParser is a ref parser for Buf.
 type RefParser interface {
 	ImageRefParser
@@ -186,6 +217,26 @@ func NewImageRefParser(logger *zap.Logger) ImageRefParser {
 	return newImageRefParser(logger)
 }
 
+// NewProtoEncodingRefParser returns a new RefParser for messages only.
+//
+// This defaults to binary, unless ProtoEncodingRefParserWithDefaultMessageEncoding is set.
+func NewProtoEncodingRefParser(logger *zap.Logger, options ...ProtoEncodingRefParserOption) ProtoEncodingRefParser {
+	return newProtoEncodingRefParser(logger, options...)
+}
+
+// ProtoEncodingRefParserOption is an option for NewProtoEncodingRefParser.
+type ProtoEncodingRefParserOption func(*protoEncodingRefParserOptions)
+
+// ProtoEncodingRefParserWithDefaultMessageEncoding sets the MessageEncoding to use
+// when the format cannot be determined from the path, such as for stdin or stdout.
+//
+// The default is MessageEncodingBin.
+func ProtoEncodingRefParserWithDefaultMessageEncoding(messageEncoding MessageEncoding) ProtoEncodingRefParserOption {
+	return func(protoEncodingRefParserOptions *protoEncodingRefParserOptions) {
+		protoEncodingRefParserOptions.defaultMessageEncoding = messageEncoding
+	}
+}
+
 // NewSourceRefParser returns a new RefParser for sources only.
 //
 // This defaults to dir or module.
@@ -205,6 +256,23 @@ func NewSourceOrModuleRefParser(logger *zap.Logger) SourceOrModuleRefParser {
 	return newSourceOrModuleRefParser(logger)
 }
 
+// IsLocalRef returns true if the Ref is a local directory or a local .proto file,
+// that is if the files of the Ref can be written back to disk.
+func IsLocalRef(ref Ref) bool {
+	switch ref.internalRef().(type) {
+	case internal.DirRef, internal.ProtoFileRef:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsDirRef returns true if the Ref is a local directory.
+func IsDirRef(ref Ref) bool {
+	_, ok := ref.internalRef().(internal.DirRef)
+	return ok
+}
+
 // ReadBucketCloser is a bucket returned from GetBucket.
 // We need to surface the internal.ReadBucketCloser
 // interface to other packages, so we use a type
@@ -256,6 +324,18 @@ func GetSourceBucketWithWorkspacesDisabled() GetSourceBucketOption {
 	}
 }
 
+// ProtoEncodingReader is a message file reader.
+type ProtoEncodingReader interface {
+	// GetMessageFile gets the message file.
+	//
+	// The returned file will be uncompressed.
+	GetMessageFile(
+		ctx context.Context,
+		container app.EnvStdinContainer,
+		protoEncodingRef ProtoEncodingRef,
+	) (io.ReadCloser, error)
+}
+
 // ModuleFetcher is a module fetcher.
 type ModuleFetcher interface {
 	// GetModule gets the module.
@@ -329,6 +409,17 @@ func NewSourceReader(
 	)
 }
 
+// NewProtoEncodingReader returns a new ProtoEncodingReader.
+func NewProtoEncodingReader(
+	logger *zap.Logger,
+	storageosProvider storageos.Provider,
+) ProtoEncodingReader {
+	return newProtoEncodingReader(
+		logger,
+		storageosProvider,
+	)
+}
+
 // NewModuleFetcher returns a new ModuleFetcher.
 func NewModuleFetcher(
 	logger *zap.Logger,
@@ -352,6 +443,12 @@ type Writer interface {
 		container app.EnvStdoutContainer,
 		imageRef ImageRef,
 	) (io.WriteCloser, error)
+	// PutMessageFile puts the message file.
+	PutMessageFile(
+		ctx context.Context,
+		container app.EnvStdoutContainer,
+		protoEncodingRef ProtoEncodingRef,
+	) (io.WriteCloser, error)
 	// PutSingleFile puts the file to the path, which can be
 	// a path in file system, or stdout represented by "-".
 	PutSingleFile(
@@ -373,3 +470,7 @@ func NewWriter(
 type getSourceBucketOptions struct {
 	workspacesDisabled bool
 }
+
+type protoEncodingRefParserOptions struct {
+	defaultMessageEncoding MessageEncoding
+}
@@ -37,6 +37,8 @@ const (
 	formatZip = "zip"
 	// formatProtoFile is the proto file format
 	formatProtoFile = "protofile"
+	// formatTxt is the protobuf text format.
+	formatTxt = "txt"
 )
 
 var (
@@ -122,6 +124,13 @@ var (
 		formatZip,
 	}
 
+	// sorted
+	protoEncodingFormats = []string{
+		formatBin,
+		formatJSON,
+		formatTxt,
+	}
+
 	deprecatedCompressionFormatToReplacementFormat = map[string]string{
 		formatBingz:  formatBin,
 		formatJSONGZ: formatJSON,
@@ -68,6 +68,11 @@ func NewCannotSpecifyTagWithRefError() error {
 	return fmt.Errorf(`cannot specify "tag" with "ref"`)
 }
 
+// NewCannotSpecifyMergeBaseWithBranchTagOrRefError is a fetch error.
+func NewCannotSpecifyMergeBaseWithBranchTagOrRefError() error {
+	return fmt.Errorf(`cannot specify "merge-base" with "branch", "tag", or "ref"`)
+}
+
 // NewDepthParseError is a fetch error.
 func NewDepthParseError(s string) error {
 	return fmt.Errorf(`could not parse "depth" value %q`, s)
@@ -519,10 +519,14 @@ type RawRef struct {
 	// This is defined as anything that can be given to git checkout.
 	GitRef string
 	// Only set for git formats
+	// Specifies a branch to check out the merge base of HEAD with.
+	// Not allowed with GitBranch, GitTag, or GitRef.
+	GitMergeBase string
+	// Only set for git formats
 	GitRecurseSubmodules bool
 	// Only set for git formats.
-	// The depth to use when cloning a repository. Only allowed when GitRef
-	// is set. Defaults to 50 if unset.
+	// The depth to use when cloning a repository. Defaults to 50 if GitRef
+	// or GitMergeBase is set, and 1 otherwise.
 	GitDepth uint32
 	// Only set for archive formats
 	ArchiveStripComponents uint32
@@ -153,6 +153,8 @@ func (a *refParser) getRawRef(value string) (*RawRef, error) {
 			rawRef.GitTag = value
 		case "ref":
 			rawRef.GitRef = value
+		case "merge-base":
+			rawRef.GitMergeBase = value
 		case "depth":
 			depth, err := strconv.ParseUint(value, 10, 32)
 			if err != nil {
@@ -213,16 +215,20 @@ func (a *refParser) getRawRef(value string) (*RawRef, error) {
 		if rawRef.GitRef != "" && rawRef.GitTag != "" {
 			return nil, NewCannotSpecifyTagWithRefError()
 		}
+		if rawRef.GitMergeBase != "" && (rawRef.GitBranch != "" || rawRef.GitTag != "" || rawRef.GitRef != "") {
+			return nil, NewCannotSpecifyMergeBaseWithBranchTagOrRefError()
+		}
 		if rawRef.GitDepth == 0 {
 			// Default to 1
 			rawRef.GitDepth = 1
-			if rawRef.GitRef != "" {
-				// Default to 50 when using ref
+			if rawRef.GitRef != "" || rawRef.GitMergeBase != "" {
+				// Default to 50 when using ref or merge-base, as
+				// the history is needed to resolve the commit
 				rawRef.GitDepth = 50
 			}
 		}
 	} else {
-		if rawRef.GitBranch != "" || rawRef.GitTag != "" || rawRef.GitRef != "" || rawRef.GitRecurseSubmodules || rawRef.GitDepth > 0 {
+		if rawRef.GitBranch != "" || rawRef.GitTag != "" || rawRef.GitRef != "" || rawRef.GitMergeBase != "" || rawRef.GitRecurseSubmodules || rawRef.GitDepth > 0 {
 			return nil, NewOptionsInvalidForFormatError(rawRef.Format, value)
 		}
 	}
@@ -336,7 +342,7 @@ func getDirRef(
 func getGitRef(
 	rawRef *RawRef,
 ) (ParsedGitRef, error) {
-	gitRefName, err := getGitRefName(rawRef.Path, rawRef.GitBranch, rawRef.GitTag, rawRef.GitRef)
+	gitRefName, err := getGitRefName(rawRef.Path, rawRef.GitBranch, rawRef.GitTag, rawRef.GitRef, rawRef.GitMergeBase)
 	if err != nil {
 		return nil, err
 	}
@@ -359,10 +365,17 @@ func getModuleRef(
 	)
 }
 
-func getGitRefName(path string, branch string, tag string, ref string) (git.Name, error) {
-	if branch == "" && tag == "" && ref == "" {
+func getGitRefName(path string, branch string, tag string, ref string, mergeBase string) (git.Name, error) {
+	if branch == "" && tag == "" && ref == "" && mergeBase == "" {
 		return nil, nil
 	}
+	if mergeBase != "" {
+		if branch != "" || tag != "" || ref != "" {
+			// already did this in getRawRef but just in case
+			return nil, NewCannotSpecifyMergeBaseWithBranchTagOrRefError()
+		}
+		return git.NewMergeBaseName(mergeBase), nil
+	}
 	if branch != "" && tag != "" {
 		// already did this in getRawRef but just in case
 		return nil, NewCannotSpecifyGitBranchAndTagError()
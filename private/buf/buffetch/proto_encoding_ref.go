@@ -0,0 +1,52 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffetch
+
+import (
+	"github.com/bufbuild/buf/private/buf/buffetch/internal"
+)
+
+var _ ProtoEncodingRef = &protoEncodingRef{}
+
+type protoEncodingRef struct {
+	singleRef       internal.SingleRef
+	messageEncoding MessageEncoding
+}
+
+func newProtoEncodingRef(
+	singleRef internal.SingleRef,
+	messageEncoding MessageEncoding,
+) *protoEncodingRef {
+	return &protoEncodingRef{
+		singleRef:       singleRef,
+		messageEncoding: messageEncoding,
+	}
+}
+
+func (r *protoEncodingRef) Path() string {
+	return r.singleRef.Path()
+}
+
+func (r *protoEncodingRef) MessageEncoding() MessageEncoding {
+	return r.messageEncoding
+}
+
+func (r *protoEncodingRef) IsNull() bool {
+	return r.singleRef.FileScheme() == internal.FileSchemeNull
+}
+
+func (r *protoEncodingRef) internalSingleRef() internal.SingleRef {
+	return r.singleRef
+}
@@ -109,6 +109,20 @@ func newSourceReader(
 	}
 }
 
+func newProtoEncodingReader(
+	logger *zap.Logger,
+	storageosProvider storageos.Provider,
+) *reader {
+	return &reader{
+		internalReader: internal.NewReader(
+			logger,
+			storageosProvider,
+			internal.WithReaderLocal(),
+			internal.WithReaderStdio(),
+		),
+	}
+}
+
 func newModuleFetcher(
 	logger *zap.Logger,
 	storageosProvider storageos.Provider,
@@ -135,6 +149,14 @@ func (a *reader) GetImageFile(
 	return a.internalReader.GetFile(ctx, container, imageRef.internalFileRef())
 }
 
+func (a *reader) GetMessageFile(
+	ctx context.Context,
+	container app.EnvStdinContainer,
+	protoEncodingRef ProtoEncodingRef,
+) (io.ReadCloser, error) {
+	return a.internalReader.GetFile(ctx, container, protoEncodingRef.internalSingleRef())
+}
+
 func (a *reader) GetSourceBucket(
 	ctx context.Context,
 	container app.EnvStdinContainer,
@@ -110,6 +110,25 @@ func newImageRefParser(logger *zap.Logger) *refParser {
 	}
 }
 
+func newProtoEncodingRefParser(logger *zap.Logger, options ...ProtoEncodingRefParserOption) *refParser {
+	protoEncodingRefParserOptions := &protoEncodingRefParserOptions{
+		defaultMessageEncoding: MessageEncodingBin,
+	}
+	for _, option := range options {
+		option(protoEncodingRefParserOptions)
+	}
+	return &refParser{
+		logger: logger.Named("buffetch"),
+		fetchRefParser: internal.NewRefParser(
+			logger,
+			internal.WithRawRefProcessor(newRawRefProcessorProtoEncoding(protoEncodingRefParserOptions.defaultMessageEncoding)),
+			internal.WithSingleFormat(formatBin),
+			internal.WithSingleFormat(formatJSON),
+			internal.WithSingleFormat(formatTxt),
+		),
+	}
+}
+
 func newSourceRefParser(logger *zap.Logger) *refParser {
 	return &refParser{
 		logger: logger.Named("buffetch"),
@@ -259,6 +278,28 @@ func (a *refParser) GetImageRef(
 	return newImageRef(parsedSingleRef, imageEncoding), nil
 }
 
+func (a *refParser) GetProtoEncodingRef(
+	ctx context.Context,
+	value string,
+) (ProtoEncodingRef, error) {
+	ctx, span := trace.StartSpan(ctx, "get_proto_encoding_ref")
+	defer span.End()
+	parsedRef, err := a.getParsedRef(ctx, value, protoEncodingFormats)
+	if err != nil {
+		return nil, err
+	}
+	parsedSingleRef, ok := parsedRef.(internal.ParsedSingleRef)
+	if !ok {
+		// this should never happen
+		return nil, fmt.Errorf("invalid ParsedRef type for message: %T", parsedRef)
+	}
+	messageEncoding, err := parseMessageEncoding(parsedSingleRef.Format())
+	if err != nil {
+		return nil, err
+	}
+	return newProtoEncodingRef(parsedSingleRef, messageEncoding), nil
+}
+
 func (a *refParser) GetSourceRef(
 	ctx context.Context,
 	value string,
@@ -269,6 +310,9 @@ func (a *refParser) GetSourceRef(
 	if err != nil {
 		return nil, err
 	}
+	if protoFileRef, ok := parsedRef.(internal.ProtoFileRef); ok {
+		return newProtoFileRef(protoFileRef), nil
+	}
 	parsedBucketRef, ok := parsedRef.(internal.ParsedBucketRef)
 	if !ok {
 		// this should never happen
@@ -516,6 +560,55 @@ func processRawRefImage(rawRef *internal.RawRef) error {
 	return nil
 }
 
+func newRawRefProcessorProtoEncoding(defaultMessageEncoding MessageEncoding) func(*internal.RawRef) error {
+	return func(rawRef *internal.RawRef) error {
+		// if format option is not set and path is "-", use the default
+		var format string
+		var compressionType internal.CompressionType
+		if rawRef.Path == "-" || app.IsDevNull(rawRef.Path) || app.IsDevStdin(rawRef.Path) || app.IsDevStdout(rawRef.Path) {
+			format = messageEncodingToFormat(defaultMessageEncoding)
+		} else {
+			switch filepath.Ext(rawRef.Path) {
+			case ".bin":
+				format = formatBin
+			case ".json":
+				format = formatJSON
+			case ".txt":
+				format = formatTxt
+			case ".gz":
+				compressionType = internal.CompressionTypeGzip
+				switch filepath.Ext(strings.TrimSuffix(rawRef.Path, filepath.Ext(rawRef.Path))) {
+				case ".bin":
+					format = formatBin
+				case ".json":
+					format = formatJSON
+				case ".txt":
+					format = formatTxt
+				default:
+					return fmt.Errorf("path %q had .gz extension with unknown format", rawRef.Path)
+				}
+			case ".zst":
+				compressionType = internal.CompressionTypeZstd
+				switch filepath.Ext(strings.TrimSuffix(rawRef.Path, filepath.Ext(rawRef.Path))) {
+				case ".bin":
+					format = formatBin
+				case ".json":
+					format = formatJSON
+				case ".txt":
+					format = formatTxt
+				default:
+					return fmt.Errorf("path %q had .zst extension with unknown format", rawRef.Path)
+				}
+			default:
+				format = messageEncodingToFormat(defaultMessageEncoding)
+			}
+		}
+		rawRef.Format = format
+		rawRef.CompressionType = compressionType
+		return nil
+	}
+}
+
 func processRawRefModule(rawRef *internal.RawRef) error {
 	rawRef.Format = formatMod
 	return nil
@@ -532,6 +625,30 @@ func parseImageEncoding(format string) (ImageEncoding, error) {
 	}
 }
 
+func parseMessageEncoding(format string) (MessageEncoding, error) {
+	switch format {
+	case formatBin:
+		return MessageEncodingBin, nil
+	case formatJSON:
+		return MessageEncodingJSON, nil
+	case formatTxt:
+		return MessageEncodingTxt, nil
+	default:
+		return 0, fmt.Errorf("invalid format for message: %q", format)
+	}
+}
+
+func messageEncodingToFormat(messageEncoding MessageEncoding) string {
+	switch messageEncoding {
+	case MessageEncodingJSON:
+		return formatJSON
+	case MessageEncodingTxt:
+		return formatTxt
+	default:
+		return formatBin
+	}
+}
+
 // TODO: this is a terrible heuristic, and we shouldn't be using what amounts
 // to heuristics here (technically this is a documentable rule, but still)
 func assumeModuleOrDir(path string) (string, error) {
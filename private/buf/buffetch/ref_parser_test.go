@@ -402,6 +402,45 @@ func TestGetParsedRefSuccess(t *testing.T) {
 		),
 		"ssh://user@hello.com:path/to/dir.git#ref=refs/remotes/origin/HEAD,branch=main,depth=10",
 	)
+	testGetParsedRefSuccess(
+		t,
+		internal.NewDirectParsedGitRef(
+			formatGit,
+			".git",
+			internal.GitSchemeLocal,
+			git.NewRefName("HEAD~1"),
+			false,
+			50,
+			"",
+		),
+		".git#ref=HEAD~1",
+	)
+	testGetParsedRefSuccess(
+		t,
+		internal.NewDirectParsedGitRef(
+			formatGit,
+			".git",
+			internal.GitSchemeLocal,
+			git.NewMergeBaseName("main"),
+			false,
+			50,
+			"",
+		),
+		".git#merge-base=main",
+	)
+	testGetParsedRefSuccess(
+		t,
+		internal.NewDirectParsedGitRef(
+			formatGit,
+			".git",
+			internal.GitSchemeLocal,
+			git.NewMergeBaseName("origin/main"),
+			false,
+			100,
+			"",
+		),
+		".git#merge-base=origin/main,depth=100",
+	)
 	testGetParsedRefSuccess(
 		t,
 		internal.NewDirectParsedGitRef(
@@ -947,6 +986,26 @@ func TestGetParsedRefError(t *testing.T) {
 		internal.NewCannotSpecifyTagWithRefError(),
 		"path/to/foo#format=git,tag=foo,ref=bar",
 	)
+	testGetParsedRefError(
+		t,
+		internal.NewCannotSpecifyMergeBaseWithBranchTagOrRefError(),
+		"path/to/foo#format=git,merge-base=main,branch=foo",
+	)
+	testGetParsedRefError(
+		t,
+		internal.NewCannotSpecifyMergeBaseWithBranchTagOrRefError(),
+		"path/to/foo#format=git,merge-base=main,tag=foo",
+	)
+	testGetParsedRefError(
+		t,
+		internal.NewCannotSpecifyMergeBaseWithBranchTagOrRefError(),
+		"path/to/foo#format=git,merge-base=main,ref=HEAD~1",
+	)
+	testGetParsedRefError(
+		t,
+		internal.NewOptionsInvalidForFormatError(formatDir, "path/to/some/foo#merge-base=main"),
+		"path/to/some/foo#merge-base=main",
+	)
 	testGetParsedRefError(
 		t,
 		internal.NewDepthParseError("bar"),
@@ -1064,6 +1123,32 @@ func TestGetParsedRefError(t *testing.T) {
 	)
 }
 
+func TestGetProtoEncodingRef(t *testing.T) {
+	t.Parallel()
+	testGetProtoEncodingRef(t, "-", MessageEncodingBin, nil)
+	testGetProtoEncodingRef(t, "-", MessageEncodingJSON, []ProtoEncodingRefParserOption{ProtoEncodingRefParserWithDefaultMessageEncoding(MessageEncodingJSON)})
+	testGetProtoEncodingRef(t, "-#format=txt", MessageEncodingTxt, nil)
+	testGetProtoEncodingRef(t, "path/to/file.bin", MessageEncodingBin, nil)
+	testGetProtoEncodingRef(t, "path/to/file.json", MessageEncodingJSON, nil)
+	testGetProtoEncodingRef(t, "path/to/file.txt", MessageEncodingTxt, nil)
+	testGetProtoEncodingRef(t, "path/to/file.txt.gz", MessageEncodingTxt, nil)
+	testGetProtoEncodingRef(t, "path/to/file", MessageEncodingBin, nil)
+	testGetProtoEncodingRef(t, "path/to/file.json#format=bin", MessageEncodingBin, nil)
+	_, err := newProtoEncodingRefParser(zap.NewNop()).GetProtoEncodingRef(context.Background(), "path/to/file#format=dir")
+	assert.Error(t, err)
+}
+
+func testGetProtoEncodingRef(
+	t *testing.T,
+	value string,
+	expectedMessageEncoding MessageEncoding,
+	options []ProtoEncodingRefParserOption,
+) {
+	protoEncodingRef, err := newProtoEncodingRefParser(zap.NewNop(), options...).GetProtoEncodingRef(context.Background(), value)
+	require.NoError(t, err)
+	assert.Equal(t, expectedMessageEncoding, protoEncodingRef.MessageEncoding())
+}
+
 func testGetParsedRefSuccess(
 	t *testing.T,
 	expectedRef internal.ParsedRef,
@@ -47,6 +47,14 @@ func (w *writer) PutImageFile(
 	return w.internalWriter.PutFile(ctx, container, imageRef.internalFileRef())
 }
 
+func (w *writer) PutMessageFile(
+	ctx context.Context,
+	container app.EnvStdoutContainer,
+	protoEncodingRef ProtoEncodingRef,
+) (io.WriteCloser, error) {
+	return w.internalWriter.PutFile(ctx, container, protoEncodingRef.internalSingleRef())
+}
+
 func (w *writer) PutSingleFile(
 	ctx context.Context,
 	container app.EnvStdoutContainer,
@@ -0,0 +1,45 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufformat formats .proto files.
+package bufformat
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/jhump/protoreflect/desc/protoparse"
+)
+
+// Format formats the given .proto file data.
+//
+// The path is only used for error messages.
+//
+// All comments are preserved. Imports are sorted, and the file header is
+// ordered as syntax, package, imports, and then file options. All other
+// declarations keep their original order.
+func Format(path string, data []byte) ([]byte, error) {
+	parser := protoparse.Parser{
+		Accessor: func(string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		},
+	}
+	fileNodes, err := parser.ParseToAST(path)
+	if err != nil {
+		return nil, err
+	}
+	formatter := newFormatter()
+	formatter.writeFile(fileNodes[0])
+	return formatter.bytes(), nil
+}
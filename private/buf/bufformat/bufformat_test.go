@@ -0,0 +1,223 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufformat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+	testFormat(
+		t,
+		"header",
+		`// Header comment.
+syntax="proto3";
+option go_package="foo/bar";
+// Package comment.
+package  foo.v1;
+import "google/protobuf/timestamp.proto";
+import public "b.proto";
+option java_multiple_files=true;
+
+option java_package="foo.bar";
+message Foo {}
+import "a.proto"; // a
+`,
+		`// Header comment.
+syntax = "proto3";
+
+// Package comment.
+package foo.v1;
+
+import "a.proto"; // a
+import public "b.proto";
+import "google/protobuf/timestamp.proto";
+
+option go_package = "foo/bar";
+option java_multiple_files = true;
+option java_package = "foo.bar";
+
+message Foo {}
+`,
+	)
+	testFormat(
+		t,
+		"comments",
+		`syntax = "proto3";
+// Detached comment.
+
+// Foo comment.
+message Foo {
+  // Detached field comment.
+
+      // One comment.
+  string one = 1;   // One trailing comment.
+  // Two comment.
+  string /* inline */ two = 2;
+
+
+  string three = 3;
+  // Trailing three comment.
+}
+message Bar {
+    // Empty comment.
+}
+/* Final
+ * comment. */
+`,
+		`syntax = "proto3";
+// Detached comment.
+
+// Foo comment.
+message Foo {
+  // Detached field comment.
+
+  // One comment.
+  string one = 1; // One trailing comment.
+  // Two comment.
+  string /* inline */ two = 2;
+
+  string three = 3;
+  // Trailing three comment.
+}
+
+message Bar {
+  // Empty comment.
+}
+/* Final
+ * comment. */
+`,
+	)
+	testFormat(
+		t,
+		"declarations",
+		`syntax = "proto2";
+package foo;;
+message Foo {
+  optional group Bar = 1 [deprecated=true] { optional int32 baz = 2 [default=-5]; }
+  map<string,Foo> foos = 3;
+  oneof value { string s = 4; int32 i = 5; }
+  extensions 100 to max;
+  reserved 6 to 8, 10;
+  reserved "qux";
+  message Empty {
+  }
+}
+extend Foo { optional int32 ext = 100; }
+enum Enum { ENUM_ZERO = 0; ENUM_NEG = -1; }
+service Service {
+  rpc Unary ( Foo ) returns ( Foo ) ;
+  rpc Stream(stream Foo) returns (stream .foo.Foo) { option deprecated = true; }
+}
+`,
+		`syntax = "proto2";
+
+package foo;
+
+message Foo {
+  optional group Bar = 1 [deprecated = true] {
+    optional int32 baz = 2 [default = -5];
+  }
+  map<string, Foo> foos = 3;
+  oneof value {
+    string s = 4;
+    int32 i = 5;
+  }
+  extensions 100 to max;
+  reserved 6 to 8, 10;
+  reserved "qux";
+  message Empty {}
+}
+
+extend Foo {
+  optional int32 ext = 100;
+}
+
+enum Enum {
+  ENUM_ZERO = 0;
+  ENUM_NEG = -1;
+}
+
+service Service {
+  rpc Unary(Foo) returns (Foo);
+  rpc Stream(stream Foo) returns (stream .foo.Foo) {
+    option deprecated = true;
+  }
+}
+`,
+	)
+	testFormat(
+		t,
+		"options",
+		`syntax = "proto3";
+option (a) = {b: 1, c <d: "e">; [f.g]: [1, 2] h: "i"
+  "j"};
+option (k) = "l" "m";
+message Foo {
+  string one = 1 [(a) = {}, deprecated = true];
+  string two = 2 [
+    (a) = {b: 1},
+    deprecated = true
+  ];
+}
+`,
+		`syntax = "proto3";
+
+option (a) = {
+  b: 1
+  c {
+    d: "e"
+  }
+  [f.g]: [1, 2]
+  h: "i"
+    "j"
+};
+option (k) = "l" "m";
+
+message Foo {
+  string one = 1 [(a) = {}, deprecated = true];
+  string two = 2 [
+    (a) = {
+      b: 1
+    },
+    deprecated = true
+  ];
+}
+`,
+	)
+}
+
+func TestFormatError(t *testing.T) {
+	t.Parallel()
+	_, err := Format("foo.proto", []byte(`syntax = "proto3"; message Foo {`))
+	assert.Error(t, err)
+}
+
+func testFormat(t *testing.T, name string, input string, expected string) {
+	t.Run(name, func(t *testing.T) {
+		t.Parallel()
+		formatted, err := Format("foo.proto", []byte(input))
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(formatted))
+		// Formatting must be idempotent.
+		reformatted, err := Format("foo.proto", formatted)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(reformatted))
+	})
+}
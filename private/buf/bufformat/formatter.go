@@ -0,0 +1,510 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufformat
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc/protoparse/ast"
+)
+
+const indentString = "  "
+
+// formatter writes a formatted ast.FileNode.
+//
+// Every token in the AST is either written or explicitly dropped, and the
+// comments attached to dropped tokens are carried over to the next written
+// token, so that no comments are lost.
+type formatter struct {
+	buffer *bytes.Buffer
+	indent int
+
+	// lineStart is true if nothing has been written on the current line.
+	lineStart bool
+	// declStart is true if the next token starts a declaration. If the next
+	// token is at the start of a line and declStart is false, the token
+	// continues a declaration and is indented one more level.
+	declStart bool
+	// blockStart is true if nothing has been written since an opening brace.
+	blockStart bool
+	// pendingBlankLine is true if a blank line should be written before
+	// the next line.
+	pendingBlankLine bool
+	// pendingComments are the comments of dropped tokens that have not been
+	// written yet.
+	pendingComments []ast.Comment
+	// previousText is the last text written, used to determine spacing.
+	previousText string
+	// lastLine is the line in the original source of the last token or
+	// comment written, used to preserve blank lines.
+	lastLine int
+}
+
+func newFormatter() *formatter {
+	return &formatter{
+		buffer:    bytes.NewBuffer(nil),
+		lineStart: true,
+		declStart: true,
+	}
+}
+
+func (f *formatter) bytes() []byte {
+	return f.buffer.Bytes()
+}
+
+func (f *formatter) writeFile(fileNode *ast.FileNode) {
+	var (
+		packageNodes []ast.Node
+		importNodes  []*ast.ImportNode
+		optionNodes  []ast.Node
+		otherNodes   []ast.Node
+	)
+	for _, decl := range fileNode.Decls {
+		switch decl := decl.(type) {
+		case *ast.PackageNode:
+			packageNodes = append(packageNodes, decl)
+		case *ast.ImportNode:
+			importNodes = append(importNodes, decl)
+		case *ast.OptionNode:
+			optionNodes = append(optionNodes, decl)
+		default:
+			otherNodes = append(otherNodes, decl)
+		}
+	}
+	sort.SliceStable(
+		importNodes,
+		func(i int, j int) bool {
+			return importNodes[i].Name.AsString() < importNodes[j].Name.AsString()
+		},
+	)
+	sortedImportNodes := make([]ast.Node, len(importNodes))
+	for i, importNode := range importNodes {
+		sortedImportNodes[i] = importNode
+	}
+	if fileNode.Syntax != nil {
+		f.writeDecl(fileNode.Syntax)
+	}
+	f.writeFileDecls(packageNodes, false)
+	f.writeFileDecls(sortedImportNodes, false)
+	f.writeFileDecls(optionNodes, false)
+	f.writeFileDecls(otherNodes, true)
+	f.writeFinalComments(append(f.takePendingComments(), fileNode.FinalComments...))
+	f.endLine()
+}
+
+// writeFileDecls writes a group of top-level declarations.
+//
+// The group is separated from the previous group by a blank line. If
+// separate is true, each declaration in the group is also separated by
+// a blank line.
+func (f *formatter) writeFileDecls(decls []ast.Node, separate bool) {
+	for i, decl := range decls {
+		if emptyDeclNode, ok := decl.(*ast.EmptyDeclNode); ok {
+			f.dropToken(emptyDeclNode.Semicolon)
+			continue
+		}
+		if i == 0 || separate {
+			f.pendingBlankLine = true
+		}
+		f.writeDecl(decl)
+	}
+}
+
+func (f *formatter) writeFinalComments(comments []ast.Comment) {
+	for _, comment := range comments {
+		f.endLine()
+		if comment.Start.Line-f.lastLine > 1 {
+			f.pendingBlankLine = true
+		}
+		f.writeIndent(false)
+		f.writeComment(comment)
+		f.endLine()
+	}
+}
+
+func (f *formatter) writeDecl(node ast.Node) {
+	f.endLine()
+	f.declStart = true
+	switch node := node.(type) {
+	case *ast.MessageNode:
+		f.writeInline(node.Keyword)
+		f.writeInline(node.Name)
+		f.writeBody(node.OpenBrace, messageElementsToNodes(node.Decls), node.CloseBrace)
+	case *ast.GroupNode:
+		if node.Label.KeywordNode != nil {
+			f.writeInline(node.Label.KeywordNode)
+		}
+		f.writeInline(node.Keyword)
+		f.writeInline(node.Name)
+		f.writeInline(node.Equals)
+		f.writeInline(node.Tag)
+		if node.Options != nil {
+			f.writeInline(node.Options)
+		}
+		f.writeBody(node.OpenBrace, messageElementsToNodes(node.Decls), node.CloseBrace)
+	case *ast.OneOfNode:
+		f.writeInline(node.Keyword)
+		f.writeInline(node.Name)
+		decls := make([]ast.Node, len(node.Decls))
+		for i, decl := range node.Decls {
+			decls[i] = decl
+		}
+		f.writeBody(node.OpenBrace, decls, node.CloseBrace)
+	case *ast.EnumNode:
+		f.writeInline(node.Keyword)
+		f.writeInline(node.Name)
+		decls := make([]ast.Node, len(node.Decls))
+		for i, decl := range node.Decls {
+			decls[i] = decl
+		}
+		f.writeBody(node.OpenBrace, decls, node.CloseBrace)
+	case *ast.ExtendNode:
+		f.writeInline(node.Keyword)
+		f.writeInline(node.Extendee)
+		decls := make([]ast.Node, len(node.Decls))
+		for i, decl := range node.Decls {
+			decls[i] = decl
+		}
+		f.writeBody(node.OpenBrace, decls, node.CloseBrace)
+	case *ast.ServiceNode:
+		f.writeInline(node.Keyword)
+		f.writeInline(node.Name)
+		decls := make([]ast.Node, len(node.Decls))
+		for i, decl := range node.Decls {
+			decls[i] = decl
+		}
+		f.writeBody(node.OpenBrace, decls, node.CloseBrace)
+	case *ast.RPCNode:
+		f.writeInline(node.Keyword)
+		f.writeInline(node.Name)
+		f.writeRPCType(node.Input, false)
+		f.writeInline(node.Returns)
+		f.writeRPCType(node.Output, true)
+		if node.OpenBrace == nil {
+			f.writeInline(node.Semicolon)
+			break
+		}
+		decls := make([]ast.Node, len(node.Decls))
+		for i, decl := range node.Decls {
+			decls[i] = decl
+		}
+		f.writeBody(node.OpenBrace, decls, node.CloseBrace)
+	default:
+		// Syntax, package, import, option, field, map field, enum value,
+		// extension range and reserved declarations are written on a
+		// single line.
+		f.writeInline(node)
+	}
+	f.endLine()
+}
+
+// writeBody writes a brace-enclosed list of declarations.
+func (f *formatter) writeBody(openBrace *ast.RuneNode, decls []ast.Node, closeBrace *ast.RuneNode) {
+	f.writeOpenBrace(openBrace, "{")
+	hasElements := false
+	for _, decl := range decls {
+		if emptyDeclNode, ok := decl.(*ast.EmptyDeclNode); ok {
+			f.dropToken(emptyDeclNode.Semicolon)
+			continue
+		}
+		if f.hasBlankLineBefore(decl) {
+			f.pendingBlankLine = true
+		}
+		f.writeDecl(decl)
+		hasElements = true
+	}
+	f.writeCloseBrace(closeBrace, "}", hasElements)
+}
+
+func (f *formatter) writeRPCType(rpcTypeNode *ast.RPCTypeNode, space bool) {
+	f.writeToken(rpcTypeNode.OpenParen, "(", space)
+	if rpcTypeNode.Stream != nil {
+		f.writeInline(rpcTypeNode.Stream)
+	}
+	f.writeInline(rpcTypeNode.MessageType)
+	f.writeInline(rpcTypeNode.CloseParen)
+}
+
+// writeInline writes the node as part of the current line, except for
+// message literals, compact options that spanned multiple lines, and
+// string concatenations that spanned multiple lines.
+func (f *formatter) writeInline(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.MessageLiteralNode:
+		f.writeMessageLiteral(node)
+	case *ast.CompactOptionsNode:
+		// The options are written one per line if the first option
+		// was not on the same line as the opening bracket.
+		if node.Options[0].Start().Line == node.OpenBracket.Start().Line {
+			f.writeChildren(node)
+			return
+		}
+		f.writeOpenBrace(node.OpenBracket, "[")
+		for i, optionNode := range node.Options {
+			f.endLine()
+			f.declStart = true
+			f.writeInline(optionNode)
+			if i < len(node.Commas) {
+				f.writeInline(node.Commas[i])
+			}
+		}
+		f.writeCloseBrace(node.CloseBracket, "]", true)
+	case *ast.CompoundStringLiteralNode:
+		multiline := node.Start().Line != node.End().Line
+		for i, child := range node.Children() {
+			if i > 0 && multiline {
+				f.endLine()
+			}
+			f.writeInline(child)
+		}
+	case *ast.CompoundIdentNode:
+		for i, child := range node.Children() {
+			token := child.(ast.TerminalNode)
+			f.writeToken(token, token.RawText(), i == 0 && f.spaceAfterPrevious())
+		}
+	case ast.TerminalNode:
+		f.writeToken(node, node.RawText(), f.needsSpace(node.RawText()))
+	case ast.CompositeNode:
+		f.writeChildren(node)
+	}
+}
+
+func (f *formatter) writeChildren(node ast.CompositeNode) {
+	for _, child := range node.Children() {
+		f.writeInline(child)
+	}
+}
+
+// writeMessageLiteral writes a message literal with one field per line.
+//
+// Separators between fields are optional and are dropped.
+func (f *formatter) writeMessageLiteral(messageLiteralNode *ast.MessageLiteralNode) {
+	f.writeOpenBrace(messageLiteralNode.Open, "{")
+	for i, messageFieldNode := range messageLiteralNode.Elements {
+		if f.hasBlankLineBefore(messageFieldNode) {
+			f.pendingBlankLine = true
+		}
+		f.endLine()
+		f.declStart = true
+		f.writeInline(messageFieldNode.Name)
+		if messageFieldNode.Sep != nil {
+			f.writeInline(messageFieldNode.Sep)
+		}
+		f.writeInline(messageFieldNode.Val)
+		if sep := messageLiteralNode.Seps[i]; sep != nil {
+			f.dropToken(sep)
+		}
+	}
+	f.writeCloseBrace(messageLiteralNode.Close, "}", len(messageLiteralNode.Elements) > 0)
+}
+
+// writeOpenBrace writes an opening brace or bracket and increases the indent.
+//
+// Trailing comments of the opening brace are written as part of the enclosed
+// block.
+func (f *formatter) writeOpenBrace(openBrace *ast.RuneNode, text string) {
+	f.writeLeadingComments(f.takeLeadingComments(openBrace), openBrace)
+	f.writeText(openBrace, text, f.needsSpace(text))
+	f.blockStart = true
+	f.indent++
+	f.writeTrailingComments(openBrace)
+}
+
+// writeCloseBrace decreases the indent and writes a closing brace or bracket
+// on its own line if the enclosed block has elements or comments, and on the
+// current line otherwise.
+//
+// Leading comments of the closing brace are written as part of the enclosed
+// block.
+func (f *formatter) writeCloseBrace(closeBrace *ast.RuneNode, text string, hasElements bool) {
+	comments := f.takeLeadingComments(closeBrace)
+	if len(comments) > 0 {
+		if comments[0].Start.Line-f.lastLine > 1 {
+			f.pendingBlankLine = true
+		}
+		f.endLine()
+		f.declStart = true
+		f.writeLeadingComments(comments, closeBrace)
+	}
+	f.indent--
+	if hasElements || len(comments) > 0 {
+		f.endLine()
+	}
+	f.declStart = true
+	f.pendingBlankLine = false
+	f.blockStart = false
+	f.writeText(closeBrace, text, false)
+	f.writeTrailingComments(closeBrace)
+}
+
+// writeToken writes the token and its comments, using the given text
+// in place of the raw text of the token.
+func (f *formatter) writeToken(token ast.TerminalNode, text string, space bool) {
+	f.writeLeadingComments(f.takeLeadingComments(token), token)
+	f.writeText(token, text, space)
+	f.writeTrailingComments(token)
+}
+
+// dropToken drops the token, writing its trailing comments in place and
+// carrying its leading comments over to the next written token.
+func (f *formatter) dropToken(token ast.TerminalNode) {
+	f.pendingComments = append(f.pendingComments, token.LeadingComments()...)
+	f.lastLine = token.End().Line
+	f.writeTrailingComments(token)
+}
+
+func (f *formatter) takeLeadingComments(token ast.TerminalNode) []ast.Comment {
+	return append(f.takePendingComments(), token.LeadingComments()...)
+}
+
+func (f *formatter) takePendingComments() []ast.Comment {
+	comments := f.pendingComments
+	f.pendingComments = nil
+	return comments
+}
+
+func (f *formatter) writeLeadingComments(comments []ast.Comment, token ast.TerminalNode) {
+	for i, comment := range comments {
+		if !f.lineStart {
+			f.buffer.WriteString(" ")
+			f.writeComment(comment)
+			if isLineComment(comment) {
+				f.endLine()
+			}
+			continue
+		}
+		if i > 0 && comment.Start.Line-f.lastLine > 1 {
+			f.pendingBlankLine = true
+		}
+		f.writeIndent(!f.declStart)
+		f.writeComment(comment)
+		f.endLine()
+	}
+	if len(comments) > 0 && f.lineStart && token.Start().Line-f.lastLine > 1 {
+		f.pendingBlankLine = true
+	}
+}
+
+func (f *formatter) writeTrailingComments(token ast.TerminalNode) {
+	for _, comment := range token.TrailingComments() {
+		sameLine := !f.lineStart && comment.Start.Line == f.lastLine
+		if sameLine {
+			f.buffer.WriteString(" ")
+		} else {
+			f.endLine()
+			if comment.Start.Line-f.lastLine > 1 {
+				f.pendingBlankLine = true
+			}
+			f.writeIndent(false)
+		}
+		f.writeComment(comment)
+		if !sameLine || isLineComment(comment) {
+			f.endLine()
+		}
+	}
+}
+
+func (f *formatter) writeComment(comment ast.Comment) {
+	text := strings.TrimRightFunc(comment.Text, isSpace)
+	f.buffer.WriteString(text)
+	f.previousText = text
+	f.lastLine = comment.Start.Line + strings.Count(text, "\n")
+}
+
+func (f *formatter) writeText(token ast.TerminalNode, text string, space bool) {
+	if f.lineStart {
+		f.writeIndent(!f.declStart)
+	} else if space {
+		f.buffer.WriteString(" ")
+	}
+	f.buffer.WriteString(text)
+	f.lineStart = false
+	f.declStart = false
+	f.previousText = text
+	f.lastLine = token.End().Line
+}
+
+// writeIndent writes the indentation for a new line, preceded by a blank
+// line if one is pending.
+func (f *formatter) writeIndent(continuation bool) {
+	if f.pendingBlankLine && !f.blockStart && f.buffer.Len() > 0 {
+		f.buffer.WriteString("\n")
+	}
+	f.pendingBlankLine = false
+	f.blockStart = false
+	indent := f.indent
+	if continuation {
+		indent++
+	}
+	f.buffer.WriteString(strings.Repeat(indentString, indent))
+	f.lineStart = false
+}
+
+func (f *formatter) endLine() {
+	if !f.lineStart {
+		f.buffer.WriteString("\n")
+		f.lineStart = true
+	}
+}
+
+// hasBlankLineBefore returns true if there is a blank line in the original
+// source between the last written token or comment and the node, including
+// the node's leading comments.
+func (f *formatter) hasBlankLineBefore(node ast.Node) bool {
+	line := node.Start().Line
+	if comments := node.LeadingComments(); len(comments) > 0 {
+		line = comments[0].Start.Line
+	}
+	return line-f.lastLine > 1
+}
+
+func (f *formatter) needsSpace(text string) bool {
+	if !f.spaceAfterPrevious() {
+		return false
+	}
+	switch text {
+	case ")", "]", ">", "<", ",", ";", ".", ":", "/":
+		return false
+	default:
+		return true
+	}
+}
+
+func (f *formatter) spaceAfterPrevious() bool {
+	switch f.previousText {
+	case "", "(", "[", "<", ".", "/", "-", "+":
+		return false
+	default:
+		return true
+	}
+}
+
+func messageElementsToNodes(messageElements []ast.MessageElement) []ast.Node {
+	nodes := make([]ast.Node, len(messageElements))
+	for i, messageElement := range messageElements {
+		nodes[i] = messageElement
+	}
+	return nodes
+}
+
+func isLineComment(comment ast.Comment) bool {
+	return strings.HasPrefix(comment.Text, "//")
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
@@ -20,8 +20,11 @@ package bufgen
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"strconv"
+	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
@@ -32,6 +35,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
 )
 
 const (
@@ -52,6 +56,10 @@ const (
 	StrategyAll Strategy = 2
 )
 
+// ErrOutOfDate is returned by Generate if GenerateWithCheck is used and the
+// generated files on disk are out of date.
+var ErrOutOfDate = errors.New("generated files are out of date")
+
 // Strategy is a generation stategy.
 type Strategy int
 
@@ -124,6 +132,48 @@ func NewGenerator(
 	)
 }
 
+// Cache caches the CodeGeneratorResponses of plugins.
+type Cache interface {
+	// Get gets the CodeGeneratorResponse for the key.
+	//
+	// Returns false if there is no CodeGeneratorResponse for the key.
+	Get(ctx context.Context, key string) (*pluginpb.CodeGeneratorResponse, bool, error)
+	// Put puts the CodeGeneratorResponse for the key.
+	Put(ctx context.Context, key string, response *pluginpb.CodeGeneratorResponse) error
+}
+
+// NewCache returns a new Cache that stores CodeGeneratorResponses in the directory.
+//
+// Once the total size of the cache exceeds maxSizeBytes, the least recently used
+// CodeGeneratorResponses are deleted.
+func NewCache(
+	logger *zap.Logger,
+	dirPath string,
+	maxSizeBytes int64,
+) Cache {
+	return newCache(
+		logger,
+		dirPath,
+		maxSizeBytes,
+	)
+}
+
+// WriteManagedFileOptions applies managed mode to the image according to the
+// config, and writes the resulting FileOptions of each file in the image to
+// the writer instead of generating.
+//
+// Each file path is written on its own line, followed by one indented
+// line per option set for the file.
+func WriteManagedFileOptions(
+	ctx context.Context,
+	logger *zap.Logger,
+	writer io.Writer,
+	config *Config,
+	image bufimage.Image,
+) error {
+	return writeManagedFileOptions(ctx, logger, writer, config, image)
+}
+
 // GenerateOption is an option for Generate.
 type GenerateOption func(*generateOptions)
 
@@ -137,6 +187,34 @@ func GenerateWithBaseOutDirPath(baseOutDirPath string) GenerateOption {
 	}
 }
 
+// GenerateWithCache returns a new GenerateOption that uses the given Cache.
+//
+// Plugin invocations are keyed by the CodeGeneratorRequests, the plugin binary
+// or pinned remote plugin version, and the plugin options. On a hit, the cached
+// CodeGeneratorResponse is used instead of invoking the plugin. Remote plugins
+// without a pinned version are never cached.
+//
+// The default is to not use a cache.
+func GenerateWithCache(cache Cache) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.cache = cache
+	}
+}
+
+// GenerateWithCheck returns a new GenerateOption that compares the generated
+// files with the files on disk instead of writing them.
+//
+// The paths of the files that are out of date are written to pathWriter, one per
+// line, and a unified diff of the changes is written to diffWriter. Either writer
+// can be nil. If any files are out of date, Generate returns ErrOutOfDate.
+func GenerateWithCheck(pathWriter io.Writer, diffWriter io.Writer) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.check = true
+		generateOptions.checkPathWriter = pathWriter
+		generateOptions.checkDiffWriter = diffWriter
+	}
+}
+
 // GenerateWithIncludeImports says to also generate imports.
 //
 // Note that this does NOT result in the Well-Known Types being generated, use
@@ -156,12 +234,44 @@ func GenerateWithIncludeWellKnownTypes() GenerateOption {
 	}
 }
 
+// GenerateWithPartialInput says that the image only contains a subset of the
+// input files, for example because paths were given.
+//
+// Plugins with Clean set then never delete files generated by the previous run,
+// as they may have been generated from the other input files.
+func GenerateWithPartialInput() GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.partialInput = true
+	}
+}
+
 // Config is a configuration.
 type Config struct {
 	// Required
 	PluginConfigs []*PluginConfig
 	// Optional
 	ManagedConfig *ManagedConfig
+	// Optional
+	//
+	// If empty, the input is given on the command line.
+	InputConfigs []*InputConfig
+}
+
+// InputConfig is an input configuration.
+type InputConfig struct {
+	// Required
+	//
+	// The buffetch ref of the input, relative to the current directory.
+	Ref string
+	// Optional
+	Paths []string
+	// Optional
+	ExcludePaths []string
+	// Optional
+	//
+	// If set, the input is filtered to these fully-qualified type names
+	// and their dependencies.
+	Types []string
 }
 
 // PluginConfig is a plugin configuration.
@@ -177,6 +287,37 @@ type PluginConfig struct {
 	Path string
 	// Required
 	Strategy Strategy
+	// Optional
+	//
+	// If set, files that were generated to Out by the previous run but are
+	// no longer generated are deleted.
+	Clean bool
+	// Optional
+	//
+	// If set, only the files within these paths are generated by the plugin.
+	Paths []string
+	// Optional
+	//
+	// If set, the image given to the plugin is filtered to these fully-qualified
+	// type names and their dependencies.
+	Types []string
+	// Optional, exclusive with Remote
+	//
+	// If set, the plugin is stopped if it does not complete within the timeout.
+	Timeout time.Duration
+	// Optional, exclusive with Remote
+	//
+	// If not nil, only the environment variables with these keys are passed to the plugin.
+	Env []string
+	// Optional, exclusive with Remote
+	//
+	// If set, the plugin errors if its CodeGeneratorResponse is larger than this.
+	MaxOutputBytes int64
+	// Optional, exclusive with Remote
+	//
+	// If set, the version of the plugin must satisfy this constraint, such as ">= 1.28".
+	// See appprotoexec.ValidateVersionConstraint for the format.
+	Version string
 }
 
 // PluginName returns this PluginConfig's plugin name.
@@ -196,13 +337,20 @@ func (p *PluginConfig) PluginName() string {
 
 // ManagedConfig is the managed mode configuration.
 type ManagedConfig struct {
-	CcEnableArenas        *bool
-	JavaMultipleFiles     *bool
-	JavaStringCheckUtf8   *bool
-	JavaPackagePrefix     *JavaPackagePrefixConfig
-	OptimizeFor           *descriptorpb.FileOptions_OptimizeMode
-	GoPackagePrefixConfig *GoPackagePrefixConfig
-	Override              map[string]map[string]string
+	CcEnableArenas            *bool
+	JavaMultipleFiles         *bool
+	JavaStringCheckUtf8       *bool
+	JavaPackagePrefix         *JavaPackagePrefixConfig
+	OptimizeFor               *descriptorpb.FileOptions_OptimizeMode
+	GoPackagePrefixConfig     *GoPackagePrefixConfig
+	ObjcClassPrefixConfig     *ObjcClassPrefixConfig
+	SwiftPrefixConfig         *SwiftPrefixConfig
+	PhpClassPrefixConfig      *PhpClassPrefixConfig
+	CcGenericServicesConfig   *GenericServicesConfig
+	JavaGenericServicesConfig *GenericServicesConfig
+	PyGenericServicesConfig   *GenericServicesConfig
+	JSTypeConfig              *JSTypeConfig
+	Override                  map[string]map[string]string
 }
 
 // JavaPackagePrefixConfig is the java_package prefix configuration.
@@ -221,6 +369,48 @@ type GoPackagePrefixConfig struct {
 	Override map[bufmoduleref.ModuleIdentity]string
 }
 
+// ObjcClassPrefixConfig is the objc_class_prefix configuration.
+type ObjcClassPrefixConfig struct {
+	// Default is empty if the prefix should be derived from the package name.
+	Default string
+	Except  []bufmoduleref.ModuleIdentity
+	// bufmoduleref.ModuleIdentity -> objc_class_prefix.
+	Override map[bufmoduleref.ModuleIdentity]string
+}
+
+// SwiftPrefixConfig is the swift_prefix configuration.
+type SwiftPrefixConfig struct {
+	Default string
+	Except  []bufmoduleref.ModuleIdentity
+	// bufmoduleref.ModuleIdentity -> swift_prefix.
+	Override map[bufmoduleref.ModuleIdentity]string
+}
+
+// PhpClassPrefixConfig is the php_class_prefix configuration.
+type PhpClassPrefixConfig struct {
+	Default string
+	Except  []bufmoduleref.ModuleIdentity
+	// bufmoduleref.ModuleIdentity -> php_class_prefix.
+	Override map[bufmoduleref.ModuleIdentity]string
+}
+
+// GenericServicesConfig is the configuration for one of the
+// cc_generic_services, java_generic_services, or py_generic_services options.
+type GenericServicesConfig struct {
+	Default bool
+	Except  []bufmoduleref.ModuleIdentity
+	// bufmoduleref.ModuleIdentity -> option value.
+	Override map[bufmoduleref.ModuleIdentity]bool
+}
+
+// JSTypeConfig is the jstype field option configuration.
+type JSTypeConfig struct {
+	Default descriptorpb.FieldOptions_JSType
+	Except  []bufmoduleref.ModuleIdentity
+	// Field type, fully-qualified message name, or fully-qualified field name -> jstype.
+	Override map[string]string
+}
+
 // ReadConfig reads the configuration from the OS or an override, if any.
 //
 // Only use in CLI tools.
@@ -264,16 +454,32 @@ type ExternalConfigV1 struct {
 	Version string                   `json:"version,omitempty" yaml:"version,omitempty"`
 	Plugins []ExternalPluginConfigV1 `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 	Managed ExternalManagedConfigV1  `json:"managed,omitempty" yaml:"managed,omitempty"`
+	Inputs  []ExternalInputConfigV1  `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+}
+
+// ExternalInputConfigV1 is an external input configuration.
+type ExternalInputConfigV1 struct {
+	Ref          string   `json:"ref,omitempty" yaml:"ref,omitempty"`
+	Paths        []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+	ExcludePaths []string `json:"exclude_paths,omitempty" yaml:"exclude_paths,omitempty"`
+	Types        []string `json:"types,omitempty" yaml:"types,omitempty"`
 }
 
 // ExternalPluginConfigV1 is an external plugin configuration.
 type ExternalPluginConfigV1 struct {
-	Name     string      `json:"name,omitempty" yaml:"name,omitempty"`
-	Remote   string      `json:"remote,omitempty" yaml:"remote,omitempty"`
-	Out      string      `json:"out,omitempty" yaml:"out,omitempty"`
-	Opt      interface{} `json:"opt,omitempty" yaml:"opt,omitempty"`
-	Path     string      `json:"path,omitempty" yaml:"path,omitempty"`
-	Strategy string      `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	Name           string      `json:"name,omitempty" yaml:"name,omitempty"`
+	Remote         string      `json:"remote,omitempty" yaml:"remote,omitempty"`
+	Out            string      `json:"out,omitempty" yaml:"out,omitempty"`
+	Opt            interface{} `json:"opt,omitempty" yaml:"opt,omitempty"`
+	Path           string      `json:"path,omitempty" yaml:"path,omitempty"`
+	Strategy       string      `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	Clean          bool        `json:"clean,omitempty" yaml:"clean,omitempty"`
+	Paths          []string    `json:"paths,omitempty" yaml:"paths,omitempty"`
+	Types          []string    `json:"types,omitempty" yaml:"types,omitempty"`
+	Timeout        string      `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Env            []string    `json:"env,omitempty" yaml:"env,omitempty"`
+	MaxOutputBytes int64       `json:"max_output_bytes,omitempty" yaml:"max_output_bytes,omitempty"`
+	Version        string      `json:"version,omitempty" yaml:"version,omitempty"`
 }
 
 // ExternalManagedConfigV1 is an external managed mode configuration.
@@ -287,6 +493,13 @@ type ExternalManagedConfigV1 struct {
 	JavaPackagePrefix   ExternalJavaPackagePrefixConfigV1 `json:"java_package_prefix,omitempty" yaml:"java_package_prefix,omitempty"`
 	OptimizeFor         string                            `json:"optimize_for,omitempty" yaml:"optimize_for,omitempty"`
 	GoPackagePrefix     ExternalGoPackagePrefixConfigV1   `json:"go_package_prefix,omitempty" yaml:"go_package_prefix,omitempty"`
+	ObjcClassPrefix     ExternalObjcClassPrefixConfigV1   `json:"objc_class_prefix,omitempty" yaml:"objc_class_prefix,omitempty"`
+	SwiftPrefix         ExternalSwiftPrefixConfigV1       `json:"swift_prefix,omitempty" yaml:"swift_prefix,omitempty"`
+	PhpClassPrefix      ExternalPhpClassPrefixConfigV1    `json:"php_class_prefix,omitempty" yaml:"php_class_prefix,omitempty"`
+	CcGenericServices   ExternalGenericServicesConfigV1   `json:"cc_generic_services,omitempty" yaml:"cc_generic_services,omitempty"`
+	JavaGenericServices ExternalGenericServicesConfigV1   `json:"java_generic_services,omitempty" yaml:"java_generic_services,omitempty"`
+	PyGenericServices   ExternalGenericServicesConfigV1   `json:"py_generic_services,omitempty" yaml:"py_generic_services,omitempty"`
+	JSType              ExternalJSTypeConfigV1            `json:"jstype,omitempty" yaml:"jstype,omitempty"`
 	Override            map[string]map[string]string      `json:"override,omitempty" yaml:"override,omitempty"`
 }
 
@@ -298,6 +511,13 @@ func (e ExternalManagedConfigV1) IsEmpty() bool {
 		e.JavaPackagePrefix.IsEmpty() &&
 		e.OptimizeFor == "" &&
 		e.GoPackagePrefix.IsEmpty() &&
+		e.ObjcClassPrefix.IsEmpty() &&
+		e.SwiftPrefix.IsEmpty() &&
+		e.PhpClassPrefix.IsEmpty() &&
+		e.CcGenericServices.IsEmpty() &&
+		e.JavaGenericServices.IsEmpty() &&
+		e.PyGenericServices.IsEmpty() &&
+		e.JSType.IsEmpty() &&
 		len(e.Override) == 0
 }
 
@@ -361,6 +581,77 @@ func (e ExternalGoPackagePrefixConfigV1) IsEmpty() bool {
 		len(e.Override) == 0
 }
 
+// ExternalObjcClassPrefixConfigV1 is the external objc_class_prefix configuration.
+type ExternalObjcClassPrefixConfigV1 struct {
+	Default  string            `json:"default,omitempty" yaml:"default,omitempty"`
+	Except   []string          `json:"except,omitempty" yaml:"except,omitempty"`
+	Override map[string]string `json:"override,omitempty" yaml:"override,omitempty"`
+}
+
+// IsEmpty returns true if the config is empty.
+func (e ExternalObjcClassPrefixConfigV1) IsEmpty() bool {
+	return e.Default == "" &&
+		len(e.Except) == 0 &&
+		len(e.Override) == 0
+}
+
+// ExternalSwiftPrefixConfigV1 is the external swift_prefix configuration.
+type ExternalSwiftPrefixConfigV1 struct {
+	Default  string            `json:"default,omitempty" yaml:"default,omitempty"`
+	Except   []string          `json:"except,omitempty" yaml:"except,omitempty"`
+	Override map[string]string `json:"override,omitempty" yaml:"override,omitempty"`
+}
+
+// IsEmpty returns true if the config is empty.
+func (e ExternalSwiftPrefixConfigV1) IsEmpty() bool {
+	return e.Default == "" &&
+		len(e.Except) == 0 &&
+		len(e.Override) == 0
+}
+
+// ExternalPhpClassPrefixConfigV1 is the external php_class_prefix configuration.
+type ExternalPhpClassPrefixConfigV1 struct {
+	Default  string            `json:"default,omitempty" yaml:"default,omitempty"`
+	Except   []string          `json:"except,omitempty" yaml:"except,omitempty"`
+	Override map[string]string `json:"override,omitempty" yaml:"override,omitempty"`
+}
+
+// IsEmpty returns true if the config is empty.
+func (e ExternalPhpClassPrefixConfigV1) IsEmpty() bool {
+	return e.Default == "" &&
+		len(e.Except) == 0 &&
+		len(e.Override) == 0
+}
+
+// ExternalGenericServicesConfigV1 is the external configuration for one of the
+// cc_generic_services, java_generic_services, or py_generic_services options.
+type ExternalGenericServicesConfigV1 struct {
+	Default  *bool           `json:"default,omitempty" yaml:"default,omitempty"`
+	Except   []string        `json:"except,omitempty" yaml:"except,omitempty"`
+	Override map[string]bool `json:"override,omitempty" yaml:"override,omitempty"`
+}
+
+// IsEmpty returns true if the config is empty.
+func (e ExternalGenericServicesConfigV1) IsEmpty() bool {
+	return e.Default == nil &&
+		len(e.Except) == 0 &&
+		len(e.Override) == 0
+}
+
+// ExternalJSTypeConfigV1 is the external jstype field option configuration.
+type ExternalJSTypeConfigV1 struct {
+	Default  string            `json:"default,omitempty" yaml:"default,omitempty"`
+	Except   []string          `json:"except,omitempty" yaml:"except,omitempty"`
+	Override map[string]string `json:"override,omitempty" yaml:"override,omitempty"`
+}
+
+// IsEmpty returns true if the config is empty.
+func (e ExternalJSTypeConfigV1) IsEmpty() bool {
+	return e.Default == "" &&
+		len(e.Except) == 0 &&
+		len(e.Override) == 0
+}
+
 // ExternalConfigV1Beta1 is an external configuration.
 type ExternalConfigV1Beta1 struct {
 	Version string                        `json:"version,omitempty" yaml:"version,omitempty"`
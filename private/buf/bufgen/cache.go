@@ -0,0 +1,233 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// cacheKeyVersion is written first to every cache key, and should be
+// incremented whenever the contents of the cache keys change.
+const cacheKeyVersion = "v1"
+
+const cacheTempFilePrefix = ".tmp-"
+
+type cache struct {
+	logger       *zap.Logger
+	dirPath      string
+	maxSizeBytes int64
+	// pruneLock makes sure that only one prune runs at a time within this process.
+	pruneLock sync.Mutex
+}
+
+func newCache(
+	logger *zap.Logger,
+	dirPath string,
+	maxSizeBytes int64,
+) *cache {
+	return &cache{
+		logger:       logger.Named("bufgen"),
+		dirPath:      dirPath,
+		maxSizeBytes: maxSizeBytes,
+	}
+}
+
+func (c *cache) Get(ctx context.Context, key string) (*pluginpb.CodeGeneratorResponse, bool, error) {
+	filePath := c.filePath(key)
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	response := &pluginpb.CodeGeneratorResponse{}
+	if err := proto.Unmarshal(data, response); err != nil {
+		// The entry is corrupt, treat it as a miss so that it is overwritten.
+		c.logger.Debug("cache_entry_corrupt", zap.String("key", key), zap.Error(err))
+		return nil, false, nil
+	}
+	// The modification time is used as the last access time when pruning.
+	now := time.Now()
+	if err := os.Chtimes(filePath, now, now); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, false, err
+	}
+	return response, true, nil
+}
+
+func (c *cache) Put(ctx context.Context, key string, response *pluginpb.CodeGeneratorResponse) error {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(response)
+	if err != nil {
+		return err
+	}
+	filePath := c.filePath(key)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	// Write to a temporary file and rename so that concurrent readers never see a partial entry.
+	file, err := os.CreateTemp(filepath.Dir(filePath), cacheTempFilePrefix)
+	if err != nil {
+		return err
+	}
+	tempFilePath := file.Name()
+	if _, err := file.Write(data); err != nil {
+		return multierr.Append(err, multierr.Append(file.Close(), os.Remove(tempFilePath)))
+	}
+	if err := file.Close(); err != nil {
+		return multierr.Append(err, os.Remove(tempFilePath))
+	}
+	if err := os.Rename(tempFilePath, filePath); err != nil {
+		return multierr.Append(err, os.Remove(tempFilePath))
+	}
+	return c.prune()
+}
+
+// prune deletes the least recently used entries until the total size of the
+// cache is at most maxSizeBytes.
+func (c *cache) prune() error {
+	c.pruneLock.Lock()
+	defer c.pruneLock.Unlock()
+	var entries []cacheEntry
+	var totalSizeBytes int64
+	if err := filepath.Walk(c.dirPath, func(path string, fileInfo fs.FileInfo, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				// Another process may have pruned this entry.
+				return nil
+			}
+			return err
+		}
+		// Temporary files are still being written by a Put.
+		if fileInfo.Mode().IsRegular() && !strings.HasPrefix(fileInfo.Name(), cacheTempFilePrefix) {
+			entries = append(entries, cacheEntry{path: path, fileInfo: fileInfo})
+			totalSizeBytes += fileInfo.Size()
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if totalSizeBytes <= c.maxSizeBytes {
+		return nil
+	}
+	sort.Slice(entries, func(i int, j int) bool {
+		return entries[i].fileInfo.ModTime().Before(entries[j].fileInfo.ModTime())
+	})
+	for _, entry := range entries {
+		if totalSizeBytes <= c.maxSizeBytes {
+			break
+		}
+		if err := os.Remove(entry.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		totalSizeBytes -= entry.fileInfo.Size()
+	}
+	return nil
+}
+
+// filePath returns the path of the entry for the key.
+//
+// Entries are sharded by the first two characters of the key.
+func (c *cache) filePath(key string) string {
+	return filepath.Join(c.dirPath, key[:2], key)
+}
+
+type cacheEntry struct {
+	path     string
+	fileInfo fs.FileInfo
+}
+
+// cacheKeyHasher computes cache keys.
+type cacheKeyHasher struct {
+	hash hash.Hash
+}
+
+func newCacheKeyHasher() *cacheKeyHasher {
+	cacheKeyHasher := &cacheKeyHasher{
+		hash: sha256.New(),
+	}
+	cacheKeyHasher.addString(cacheKeyVersion)
+	return cacheKeyHasher
+}
+
+// addBytes adds the data to the key.
+//
+// The data is prefixed with its length so that adjacent values cannot be
+// confused with each other.
+func (c *cacheKeyHasher) addBytes(data []byte) {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(data)))
+	_, _ = c.hash.Write(length[:])
+	_, _ = c.hash.Write(data)
+}
+
+func (c *cacheKeyHasher) addString(value string) {
+	c.addBytes([]byte(value))
+}
+
+func (c *cacheKeyHasher) addBool(value bool) {
+	if value {
+		c.addString("true")
+	} else {
+		c.addString("false")
+	}
+}
+
+func (c *cacheKeyHasher) addMessage(message proto.Message) error {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	if err != nil {
+		return err
+	}
+	c.addBytes(data)
+	return nil
+}
+
+// addFile adds the digest of the file content to the key.
+func (c *cacheKeyHasher) addFile(filePath string) (retErr error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, file.Close())
+	}()
+	fileHash := sha256.New()
+	if _, err := io.Copy(fileHash, file); err != nil {
+		return err
+	}
+	c.addBytes(fileHash.Sum(nil))
+	return nil
+}
+
+func (c *cacheKeyHasher) key() string {
+	return hex.EncodeToString(c.hash.Sum(nil))
+}
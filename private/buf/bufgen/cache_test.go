@@ -0,0 +1,138 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestCacheGetPut(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	cache := NewCache(zap.NewNop(), t.TempDir(), 1<<20)
+	key := testCacheKey("a")
+	_, ok, err := cache.Get(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	response := testNewResponse("a.txt", "a")
+	require.NoError(t, cache.Put(ctx, key, response))
+	cachedResponse, ok, err := cache.Get(ctx, key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, proto.Equal(response, cachedResponse))
+}
+
+func TestCachePrune(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dirPath := t.TempDir()
+	responseSize := int64(proto.Size(testNewResponse("a.txt", "a")))
+	// Room for two responses.
+	cache := NewCache(zap.NewNop(), dirPath, 2*responseSize)
+	keyA := testCacheKey("a")
+	keyB := testCacheKey("b")
+	keyC := testCacheKey("c")
+	require.NoError(t, cache.Put(ctx, keyA, testNewResponse("a.txt", "a")))
+	require.NoError(t, cache.Put(ctx, keyB, testNewResponse("b.txt", "b")))
+	// Make the entry for a the least recently used, and then access b.
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dirPath, keyA[:2], keyA), past, past))
+	require.NoError(t, os.Chtimes(filepath.Join(dirPath, keyB[:2], keyB), past.Add(time.Minute), past.Add(time.Minute)))
+	_, ok, err := cache.Get(ctx, keyB)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, cache.Put(ctx, keyC, testNewResponse("c.txt", "c")))
+	_, ok, err = cache.Get(ctx, keyA)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, ok, err = cache.Get(ctx, keyB)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	_, ok, err = cache.Get(ctx, keyC)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCacheKeyHasher(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, testCacheKey("a", "b"), testCacheKey("a", "b"))
+	assert.NotEqual(t, testCacheKey("a", "b"), testCacheKey("b", "a"))
+	// Values are length-prefixed, so moving bytes between values changes the key.
+	assert.NotEqual(t, testCacheKey("ab", ""), testCacheKey("a", "b"))
+}
+
+func TestLocalPluginCacheKey(t *testing.T) {
+	t.Parallel()
+	pluginPath := filepath.Join(t.TempDir(), "protoc-gen-test")
+	require.NoError(t, os.WriteFile(pluginPath, []byte("#!/bin/sh\n"), 0700))
+	requests := []*pluginpb.CodeGeneratorRequest{
+		{
+			FileToGenerate: []string{"a.proto"},
+		},
+	}
+	key := func(envContainer app.EnvContainer, env []string, version string) string {
+		cacheKey, err := localPluginCacheKey(
+			envContainer,
+			&PluginConfig{
+				Name:    "test",
+				Path:    pluginPath,
+				Env:     env,
+				Version: version,
+			},
+			requests,
+		)
+		require.NoError(t, err)
+		return cacheKey
+	}
+	fooEnvContainer := app.NewEnvContainer(map[string]string{"FOO": "foo", "BAR": "bar"})
+	barEnvContainer := app.NewEnvContainer(map[string]string{"FOO": "foo", "BAR": "baz"})
+	assert.Equal(t, key(fooEnvContainer, nil, ""), key(fooEnvContainer, nil, ""))
+	assert.NotEqual(t, key(fooEnvContainer, nil, ""), key(fooEnvContainer, nil, ">= 1.28"))
+	assert.NotEqual(t, key(fooEnvContainer, nil, ""), key(fooEnvContainer, []string{}, ""))
+	// Only the values of the allowed environment variables are part of the key.
+	assert.Equal(t, key(fooEnvContainer, []string{"FOO"}, ""), key(barEnvContainer, []string{"FOO"}, ""))
+	assert.NotEqual(t, key(fooEnvContainer, []string{"FOO", "BAR"}, ""), key(barEnvContainer, []string{"FOO", "BAR"}, ""))
+	assert.Equal(t, key(fooEnvContainer, []string{"FOO", "BAR"}, ""), key(fooEnvContainer, []string{"BAR", "FOO"}, ""))
+}
+
+func testCacheKey(values ...string) string {
+	cacheKeyHasher := newCacheKeyHasher()
+	for _, value := range values {
+		cacheKeyHasher.addString(value)
+	}
+	return cacheKeyHasher.key()
+}
+
+func testNewResponse(name string, content string) *pluginpb.CodeGeneratorResponse {
+	return &pluginpb.CodeGeneratorResponse{
+		File: []*pluginpb.CodeGeneratorResponse_File{
+			{
+				Name:    proto.String(name),
+				Content: proto.String(content),
+			},
+		},
+	}
+}
@@ -20,9 +20,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin"
+	"github.com/bufbuild/buf/private/pkg/app/appproto/appprotoexec"
+	"github.com/bufbuild/buf/private/pkg/app/appproto/appprotoos"
 	"github.com/bufbuild/buf/private/pkg/encoding"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/storage"
@@ -140,24 +143,82 @@ func newConfigV1(externalConfig ExternalConfigV1, id string) (*Config, error) {
 		if err != nil {
 			return nil, err
 		}
-		pluginConfigs = append(
-			pluginConfigs,
-			&PluginConfig{
-				Name:     plugin.Name,
-				Remote:   plugin.Remote,
-				Out:      plugin.Out,
-				Opt:      opt,
-				Path:     plugin.Path,
-				Strategy: strategy,
+		var timeout time.Duration
+		if plugin.Timeout != "" {
+			timeout, err = time.ParseDuration(plugin.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid timeout for plugin %s: %w", id, plugin.Name, err)
+			}
+			if timeout <= 0 {
+				return nil, fmt.Errorf("%s: timeout for plugin %s must be positive", id, plugin.Name)
+			}
+		}
+		pluginConfig := &PluginConfig{
+			Name:           plugin.Name,
+			Remote:         plugin.Remote,
+			Out:            plugin.Out,
+			Opt:            opt,
+			Path:           plugin.Path,
+			Strategy:       strategy,
+			Clean:          plugin.Clean,
+			Types:          plugin.Types,
+			Timeout:        timeout,
+			Env:            plugin.Env,
+			MaxOutputBytes: plugin.MaxOutputBytes,
+			Version:        plugin.Version,
+		}
+		pluginConfig.Paths, err = normalizeConfigPaths(plugin.Paths)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid paths for plugin %s: %w", id, pluginConfig.PluginName(), err)
+		}
+		pluginConfigs = append(pluginConfigs, pluginConfig)
+	}
+	var inputConfigs []*InputConfig
+	for _, input := range externalConfig.Inputs {
+		paths, err := normalizeConfigPaths(input.Paths)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid paths for input %s: %w", id, input.Ref, err)
+		}
+		excludePaths, err := normalizeConfigPaths(input.ExcludePaths)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid exclude_paths for input %s: %w", id, input.Ref, err)
+		}
+		inputConfigs = append(
+			inputConfigs,
+			&InputConfig{
+				Ref:          input.Ref,
+				Paths:        paths,
+				ExcludePaths: excludePaths,
+				Types:        input.Types,
 			},
 		)
 	}
 	return &Config{
 		PluginConfigs: pluginConfigs,
 		ManagedConfig: managedConfig,
+		InputConfigs:  inputConfigs,
 	}, nil
 }
 
+// normalizeConfigPaths normalizes and validates the relative paths.
+func normalizeConfigPaths(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	normalizedPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
+		normalizedPath, err := normalpath.NormalizeAndValidate(path)
+		if err != nil {
+			return nil, err
+		}
+		normalizedPaths = append(normalizedPaths, normalizedPath)
+	}
+	if err := normalpath.ValidatePathsNormalizedValidatedUnique(normalizedPaths); err != nil {
+		return nil, err
+	}
+	return normalizedPaths, nil
+}
+
 func validateExternalConfigV1(externalConfig ExternalConfigV1, id string) error {
 	if len(externalConfig.Plugins) == 0 {
 		return fmt.Errorf("%s: no plugins set", id)
@@ -172,6 +233,9 @@ func validateExternalConfigV1(externalConfig ExternalConfigV1, id string) error
 		if plugin.Out == "" {
 			return fmt.Errorf("%s: plugin %s out is required", id, plugin.Name)
 		}
+		if plugin.Clean && appprotoos.IsArchivePath(plugin.Out) {
+			return fmt.Errorf("%s: clean cannot be set for plugin out %s, which is an archive", id, plugin.Out)
+		}
 		if plugin.Remote != "" {
 			if _, _, _, _, err := bufplugin.ParsePluginVersionPath(plugin.Remote); err != nil {
 				return fmt.Errorf("%s: invalid remote plugin name: %w", id, err)
@@ -183,13 +247,32 @@ func validateExternalConfigV1(externalConfig ExternalConfigV1, id string) error
 			if plugin.Strategy != "" {
 				return fmt.Errorf("%s: remote plugin %s cannot specify a strategy", id, plugin.Remote)
 			}
+			if plugin.Timeout != "" || plugin.Env != nil || plugin.MaxOutputBytes != 0 {
+				return fmt.Errorf("%s: remote plugin %s cannot specify a timeout, env, or max_output_bytes", id, plugin.Remote)
+			}
+			if plugin.Version != "" {
+				return fmt.Errorf("%s: remote plugin %s cannot specify a version, set the version in the remote plugin name instead", id, plugin.Remote)
+			}
 			continue
 		}
+		if plugin.MaxOutputBytes < 0 {
+			return fmt.Errorf("%s: max_output_bytes for plugin %s must be positive", id, plugin.Name)
+		}
+		if plugin.Version != "" {
+			if err := appprotoexec.ValidateVersionConstraint(plugin.Version); err != nil {
+				return fmt.Errorf("%s: invalid version for plugin %s: %w", id, plugin.Name, err)
+			}
+		}
 		// Check that the plugin name doesn't look like a remote plugin
 		if _, _, _, _, err := bufplugin.ParsePluginVersionPath(plugin.Name); err == nil {
 			return fmt.Errorf("%s: invalid plugin name, did you mean to use a remote plugin?", id)
 		}
 	}
+	for _, input := range externalConfig.Inputs {
+		if input.Ref == "" {
+			return fmt.Errorf("%s: input ref is required", id)
+		}
+	}
 	return nil
 }
 
@@ -219,34 +302,57 @@ func newManagedConfigV1(externalManagedConfig ExternalManagedConfigV1) (*Managed
 	if err != nil {
 		return nil, err
 	}
+	objcClassPrefixConfig, err := newObjcClassPrefixConfigV1(externalManagedConfig.ObjcClassPrefix)
+	if err != nil {
+		return nil, err
+	}
+	swiftPrefixConfig, err := newSwiftPrefixConfigV1(externalManagedConfig.SwiftPrefix)
+	if err != nil {
+		return nil, err
+	}
+	phpClassPrefixConfig, err := newPhpClassPrefixConfigV1(externalManagedConfig.PhpClassPrefix)
+	if err != nil {
+		return nil, err
+	}
+	ccGenericServicesConfig, err := newGenericServicesConfigV1("cc_generic_services", externalManagedConfig.CcGenericServices)
+	if err != nil {
+		return nil, err
+	}
+	javaGenericServicesConfig, err := newGenericServicesConfigV1("java_generic_services", externalManagedConfig.JavaGenericServices)
+	if err != nil {
+		return nil, err
+	}
+	pyGenericServicesConfig, err := newGenericServicesConfigV1("py_generic_services", externalManagedConfig.PyGenericServices)
+	if err != nil {
+		return nil, err
+	}
+	jsTypeConfig, err := newJSTypeConfigV1(externalManagedConfig.JSType)
+	if err != nil {
+		return nil, err
+	}
 	override := externalManagedConfig.Override
 	for overrideID, overrideValue := range override {
-		for importPath := range overrideValue {
-			normalizedImportPath, err := normalpath.NormalizeAndValidate(importPath)
-			if err != nil {
-				return nil, fmt.Errorf(
-					"failed to normalize import path: %s provided for override: %s",
-					importPath,
-					overrideID,
-				)
-			}
-			if importPath != normalizedImportPath {
-				return nil, fmt.Errorf(
-					"override can only take normalized import paths, invalid import path: %s provided for override: %s",
-					importPath,
-					overrideID,
-				)
+		for key := range overrideValue {
+			if err := validateOverrideKey(overrideID, key); err != nil {
+				return nil, err
 			}
 		}
 	}
 	return &ManagedConfig{
-		CcEnableArenas:        externalManagedConfig.CcEnableArenas,
-		JavaMultipleFiles:     externalManagedConfig.JavaMultipleFiles,
-		JavaStringCheckUtf8:   externalManagedConfig.JavaStringCheckUtf8,
-		JavaPackagePrefix:     javaPackagePrefixConfig,
-		OptimizeFor:           optimizeFor,
-		GoPackagePrefixConfig: goPackagePrefixConfig,
-		Override:              override,
+		CcEnableArenas:            externalManagedConfig.CcEnableArenas,
+		JavaMultipleFiles:         externalManagedConfig.JavaMultipleFiles,
+		JavaStringCheckUtf8:       externalManagedConfig.JavaStringCheckUtf8,
+		JavaPackagePrefix:         javaPackagePrefixConfig,
+		OptimizeFor:               optimizeFor,
+		GoPackagePrefixConfig:     goPackagePrefixConfig,
+		ObjcClassPrefixConfig:     objcClassPrefixConfig,
+		SwiftPrefixConfig:         swiftPrefixConfig,
+		PhpClassPrefixConfig:      phpClassPrefixConfig,
+		CcGenericServicesConfig:   ccGenericServicesConfig,
+		JavaGenericServicesConfig: javaGenericServicesConfig,
+		PyGenericServicesConfig:   pyGenericServicesConfig,
+		JSTypeConfig:              jsTypeConfig,
+		Override:                  override,
 	}, nil
 }
 
@@ -336,6 +442,189 @@ func newGoPackagePrefixConfigV1(externalGoPackagePrefixConfig ExternalGoPackageP
 	}, nil
 }
 
+func newObjcClassPrefixConfigV1(externalObjcClassPrefixConfig ExternalObjcClassPrefixConfigV1) (*ObjcClassPrefixConfig, error) {
+	if externalObjcClassPrefixConfig.IsEmpty() {
+		return nil, nil
+	}
+	// The default is optional, an empty default results in the prefix being derived from the package name.
+	except, override, err := newModuleIdentityStringConfigV1(
+		"objc_class_prefix",
+		externalObjcClassPrefixConfig.Except,
+		externalObjcClassPrefixConfig.Override,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjcClassPrefixConfig{
+		Default:  externalObjcClassPrefixConfig.Default,
+		Except:   except,
+		Override: override,
+	}, nil
+}
+
+func newSwiftPrefixConfigV1(externalSwiftPrefixConfig ExternalSwiftPrefixConfigV1) (*SwiftPrefixConfig, error) {
+	if externalSwiftPrefixConfig.IsEmpty() {
+		return nil, nil
+	}
+	if externalSwiftPrefixConfig.Default == "" {
+		return nil, errors.New("swift_prefix setting requires a default value")
+	}
+	except, override, err := newModuleIdentityStringConfigV1(
+		"swift_prefix",
+		externalSwiftPrefixConfig.Except,
+		externalSwiftPrefixConfig.Override,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &SwiftPrefixConfig{
+		Default:  externalSwiftPrefixConfig.Default,
+		Except:   except,
+		Override: override,
+	}, nil
+}
+
+func newPhpClassPrefixConfigV1(externalPhpClassPrefixConfig ExternalPhpClassPrefixConfigV1) (*PhpClassPrefixConfig, error) {
+	if externalPhpClassPrefixConfig.IsEmpty() {
+		return nil, nil
+	}
+	if externalPhpClassPrefixConfig.Default == "" {
+		return nil, errors.New("php_class_prefix setting requires a default value")
+	}
+	except, override, err := newModuleIdentityStringConfigV1(
+		"php_class_prefix",
+		externalPhpClassPrefixConfig.Except,
+		externalPhpClassPrefixConfig.Override,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &PhpClassPrefixConfig{
+		Default:  externalPhpClassPrefixConfig.Default,
+		Except:   except,
+		Override: override,
+	}, nil
+}
+
+func newGenericServicesConfigV1(optionName string, externalGenericServicesConfig ExternalGenericServicesConfigV1) (*GenericServicesConfig, error) {
+	if externalGenericServicesConfig.IsEmpty() {
+		return nil, nil
+	}
+	if externalGenericServicesConfig.Default == nil {
+		return nil, fmt.Errorf("%s setting requires a default value", optionName)
+	}
+	except, seenModuleIdentities, err := newModuleIdentityExceptV1(optionName, externalGenericServicesConfig.Except)
+	if err != nil {
+		return nil, err
+	}
+	override := make(map[bufmoduleref.ModuleIdentity]bool, len(externalGenericServicesConfig.Override))
+	for moduleName, value := range externalGenericServicesConfig.Override {
+		moduleIdentity, err := newModuleIdentityOverrideV1(optionName, moduleName, seenModuleIdentities)
+		if err != nil {
+			return nil, err
+		}
+		override[moduleIdentity] = value
+	}
+	return &GenericServicesConfig{
+		Default:  *externalGenericServicesConfig.Default,
+		Except:   except,
+		Override: override,
+	}, nil
+}
+
+func newJSTypeConfigV1(externalJSTypeConfig ExternalJSTypeConfigV1) (*JSTypeConfig, error) {
+	if externalJSTypeConfig.IsEmpty() {
+		return nil, nil
+	}
+	if externalJSTypeConfig.Default == "" {
+		return nil, errors.New("jstype setting requires a default value")
+	}
+	defaultValue, ok := descriptorpb.FieldOptions_JSType_value[externalJSTypeConfig.Default]
+	if !ok {
+		return nil, fmt.Errorf(
+			"invalid jstype default; expected one of %v",
+			enumMapToStringSlice(descriptorpb.FieldOptions_JSType_value),
+		)
+	}
+	except, _, err := newModuleIdentityExceptV1("jstype", externalJSTypeConfig.Except)
+	if err != nil {
+		return nil, err
+	}
+	override := make(map[string]string, len(externalJSTypeConfig.Override))
+	for name, value := range externalJSTypeConfig.Override {
+		if _, ok := descriptorpb.FieldOptions_JSType_value[value]; !ok {
+			return nil, fmt.Errorf(
+				"invalid jstype override value for %q; expected one of %v",
+				name,
+				enumMapToStringSlice(descriptorpb.FieldOptions_JSType_value),
+			)
+		}
+		override[name] = value
+	}
+	return &JSTypeConfig{
+		Default:  descriptorpb.FieldOptions_JSType(defaultValue),
+		Except:   except,
+		Override: override,
+	}, nil
+}
+
+// newModuleIdentityStringConfigV1 parses the except and override module names for
+// a managed mode option with string values.
+func newModuleIdentityStringConfigV1(
+	optionName string,
+	externalExcept []string,
+	externalOverride map[string]string,
+) ([]bufmoduleref.ModuleIdentity, map[bufmoduleref.ModuleIdentity]string, error) {
+	except, seenModuleIdentities, err := newModuleIdentityExceptV1(optionName, externalExcept)
+	if err != nil {
+		return nil, nil, err
+	}
+	override := make(map[bufmoduleref.ModuleIdentity]string, len(externalOverride))
+	for moduleName, value := range externalOverride {
+		moduleIdentity, err := newModuleIdentityOverrideV1(optionName, moduleName, seenModuleIdentities)
+		if err != nil {
+			return nil, nil, err
+		}
+		if value == "" {
+			return nil, nil, fmt.Errorf("invalid %s override: %q has an empty value", optionName, moduleIdentity.IdentityString())
+		}
+		override[moduleIdentity] = value
+	}
+	return except, override, nil
+}
+
+// newModuleIdentityExceptV1 parses the except module names for a managed mode option,
+// and returns the identity strings that have been seen so far.
+func newModuleIdentityExceptV1(optionName string, externalExcept []string) ([]bufmoduleref.ModuleIdentity, map[string]struct{}, error) {
+	seenModuleIdentities := make(map[string]struct{}, len(externalExcept))
+	except := make([]bufmoduleref.ModuleIdentity, 0, len(externalExcept))
+	for _, moduleName := range externalExcept {
+		moduleIdentity, err := bufmoduleref.ModuleIdentityForString(moduleName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s except: %w", optionName, err)
+		}
+		if _, ok := seenModuleIdentities[moduleIdentity.IdentityString()]; ok {
+			return nil, nil, fmt.Errorf("invalid %s except: %q is defined multiple times", optionName, moduleIdentity.IdentityString())
+		}
+		seenModuleIdentities[moduleIdentity.IdentityString()] = struct{}{}
+		except = append(except, moduleIdentity)
+	}
+	return except, seenModuleIdentities, nil
+}
+
+// newModuleIdentityOverrideV1 parses an override module name for a managed mode option.
+func newModuleIdentityOverrideV1(optionName string, moduleName string, seenModuleIdentities map[string]struct{}) (bufmoduleref.ModuleIdentity, error) {
+	moduleIdentity, err := bufmoduleref.ModuleIdentityForString(moduleName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s override key: %w", optionName, err)
+	}
+	if _, ok := seenModuleIdentities[moduleIdentity.IdentityString()]; ok {
+		return nil, fmt.Errorf("invalid %s override: %q is already defined as an except", optionName, moduleIdentity.IdentityString())
+	}
+	seenModuleIdentities[moduleIdentity.IdentityString()] = struct{}{}
+	return moduleIdentity, nil
+}
+
 func newConfigV1Beta1(externalConfig ExternalConfigV1Beta1, id string) (*Config, error) {
 	managedConfig, err := newManagedConfigV1Beta1(externalConfig.Options, externalConfig.Managed)
 	if err != nil {
@@ -19,6 +19,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagemodify"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
@@ -380,6 +381,151 @@ func TestReadConfigV1(t *testing.T) {
 	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error7.yaml"))
 	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error8.yaml"))
 	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error9.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error10.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error11.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error12.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error13.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error14.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error15.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error16.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error17.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error18.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error19.yaml"))
+	testReadConfigError(t, provider, readBucket, filepath.Join("testdata", "v1", "gen_error20.yaml"))
+
+	successConfig7 := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+				Clean:    true,
+			},
+		},
+	}
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success7.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig7, config)
+
+	successConfig8 := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+			},
+			{
+				Name:     "ts",
+				Out:      "gen/ts",
+				Strategy: StrategyDirectory,
+				Paths:    []string{"acme/public/v1", "acme/shared/v1/shared.proto"},
+				Types:    []string{"acme.public.v1.UserService"},
+			},
+		},
+		InputConfigs: []*InputConfig{
+			{
+				Ref: "proto",
+			},
+			{
+				Ref:          "https://github.com/acme/apis.git#branch=main",
+				Paths:        []string{"acme/billing"},
+				ExcludePaths: []string{"acme/billing/internal"},
+				Types:        []string{"acme.billing.v1.Invoice"},
+			},
+		},
+	}
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success8.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig8, config)
+
+	successConfig9 := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:           "go",
+				Out:            "gen/go",
+				Strategy:       StrategyDirectory,
+				Timeout:        30 * time.Second,
+				Env:            []string{"HOME", "PATH"},
+				MaxOutputBytes: 1048576,
+			},
+		},
+	}
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success9.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig9, config)
+
+	weatherModuleIdentity, err := bufmoduleref.NewModuleIdentity("buf.build", "acme", "weather")
+	require.NoError(t, err)
+	successConfig10 := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+			},
+		},
+		ManagedConfig: &ManagedConfig{
+			ObjcClassPrefixConfig: &ObjcClassPrefixConfig{
+				Except:   []bufmoduleref.ModuleIdentity{weatherModuleIdentity},
+				Override: make(map[bufmoduleref.ModuleIdentity]string),
+			},
+			SwiftPrefixConfig: &SwiftPrefixConfig{
+				Default:  "ACME",
+				Except:   make([]bufmoduleref.ModuleIdentity, 0),
+				Override: make(map[bufmoduleref.ModuleIdentity]string),
+			},
+			PhpClassPrefixConfig: &PhpClassPrefixConfig{
+				Default:  "Acme",
+				Except:   make([]bufmoduleref.ModuleIdentity, 0),
+				Override: make(map[bufmoduleref.ModuleIdentity]string),
+			},
+			JavaGenericServicesConfig: &GenericServicesConfig{
+				Default:  true,
+				Except:   []bufmoduleref.ModuleIdentity{weatherModuleIdentity},
+				Override: make(map[bufmoduleref.ModuleIdentity]bool),
+			},
+		},
+	}
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success10.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig10, config)
+
+	successConfig11 := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+			},
+		},
+		ManagedConfig: &ManagedConfig{
+			JSTypeConfig: &JSTypeConfig{
+				Default: descriptorpb.FieldOptions_JS_STRING,
+				Except:  make([]bufmoduleref.ModuleIdentity, 0),
+				Override: map[string]string{
+					"uint64":                     "JS_NUMBER",
+					"acme.weather.v1.Reading.id": "JS_NORMAL",
+				},
+			},
+		},
+	}
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success11.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig11, config)
+
+	successConfig12 := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+				Version:  ">= 1.28, < 2",
+			},
+		},
+	}
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success12.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig12, config)
 
 	successConfig = &Config{
 		PluginConfigs: []*PluginConfig{
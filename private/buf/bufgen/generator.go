@@ -17,7 +17,9 @@ package bufgen
 import (
 	"context"
 	"fmt"
+	"io"
 	"path/filepath"
+	"sort"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagemodify"
@@ -39,6 +41,7 @@ import (
 type generator struct {
 	logger                *zap.Logger
 	storageosProvider     storageos.Provider
+	runner                command.Runner
 	appprotoexecGenerator appprotoexec.Generator
 	registryProvider      registryv1alpha1apiclient.Provider
 }
@@ -52,6 +55,7 @@ func newGenerator(
 	return &generator{
 		logger:                logger,
 		storageosProvider:     storageosProvider,
+		runner:                runner,
 		appprotoexecGenerator: appprotoexec.NewGenerator(logger, storageosProvider, runner),
 		registryProvider:      registryProvider,
 	}
@@ -93,6 +97,11 @@ func (g *generator) Generate(
 		generateOptions.baseOutDirPath,
 		generateOptions.includeImports,
 		generateOptions.includeWellKnownTypes,
+		generateOptions.cache,
+		generateOptions.check,
+		generateOptions.checkPathWriter,
+		generateOptions.checkDiffWriter,
+		generateOptions.partialInput,
 	)
 }
 
@@ -104,7 +113,15 @@ func (g *generator) generate(
 	baseOutDirPath string,
 	includeImports bool,
 	includeWellKnownTypes bool,
+	cache Cache,
+	check bool,
+	checkPathWriter io.Writer,
+	checkDiffWriter io.Writer,
+	partialInput bool,
 ) error {
+	if err := g.checkPluginVersions(ctx, container, config); err != nil {
+		return err
+	}
 	if err := modifyImage(ctx, g.logger, config, image); err != nil {
 		return err
 	}
@@ -116,15 +133,22 @@ func (g *generator) generate(
 		image,
 		includeImports,
 		includeWellKnownTypes,
+		cache,
 	)
 	if err != nil {
 		return err
 	}
 	// Apply the CodeGeneratorResponses in the order they were specified.
+	responseWriterOptions := []appprotoos.ResponseWriterOption{
+		appprotoos.ResponseWriterWithCreateOutDirIfNotExists(),
+	}
+	if partialInput {
+		responseWriterOptions = append(responseWriterOptions, appprotoos.ResponseWriterWithPartialInput())
+	}
 	responseWriter := appprotoos.NewResponseWriter(
 		g.logger,
 		g.storageosProvider,
-		appprotoos.ResponseWriterWithCreateOutDirIfNotExists(),
+		responseWriterOptions...,
 	)
 	for i, pluginConfig := range config.PluginConfigs {
 		out := pluginConfig.Out
@@ -135,20 +159,79 @@ func (g *generator) generate(
 		if response == nil {
 			return fmt.Errorf("failed to get plugin response for %s", pluginConfig.PluginName())
 		}
+		var addResponseOptions []appprotoos.AddResponseOption
+		if pluginConfig.Clean {
+			addResponseOptions = append(addResponseOptions, appprotoos.AddResponseWithClean())
+		}
 		if err := responseWriter.AddResponse(
 			ctx,
 			response,
 			out,
+			addResponseOptions...,
 		); err != nil {
 			return fmt.Errorf("plugin %s: %v", pluginConfig.PluginName(), err)
 		}
 	}
+	if check {
+		return g.checkResponses(ctx, responseWriter, checkPathWriter, checkDiffWriter)
+	}
 	if err := responseWriter.Close(); err != nil {
 		return err
 	}
 	return nil
 }
 
+// checkResponses diffs the responses against the files on disk without writing them.
+func (g *generator) checkResponses(
+	ctx context.Context,
+	responseWriter appprotoos.ResponseWriter,
+	pathWriter io.Writer,
+	diffWriter io.Writer,
+) error {
+	changedPaths, err := responseWriter.Diff(ctx, g.runner, diffWriter)
+	if err != nil {
+		return err
+	}
+	if len(changedPaths) == 0 {
+		return nil
+	}
+	if pathWriter != nil {
+		for _, changedPath := range changedPaths {
+			if _, err := fmt.Fprintln(pathWriter, changedPath); err != nil {
+				return err
+			}
+		}
+	}
+	return ErrOutOfDate
+}
+
+// checkPluginVersions verifies that the local plugins satisfy their version
+// constraints before any plugins are executed.
+func (g *generator) checkPluginVersions(
+	ctx context.Context,
+	container app.EnvStdioContainer,
+	config *Config,
+) error {
+	for _, pluginConfig := range config.PluginConfigs {
+		if pluginConfig.Remote != "" || pluginConfig.Version == "" {
+			continue
+		}
+		if err := appprotoexec.CheckVersion(
+			ctx,
+			container,
+			g.runner,
+			pluginConfig.Name,
+			pluginConfig.Version,
+			appprotoexec.HandlerWithPluginPath(pluginConfig.Path),
+			appprotoexec.HandlerWithTimeout(pluginConfig.Timeout),
+			appprotoexec.HandlerWithEnvKeys(pluginConfig.Env),
+		); err != nil {
+			return fmt.Errorf("plugin %s: %w", pluginConfig.PluginName(), err)
+		}
+	}
+	return nil
+}
+
 func (g *generator) execPlugins(
 	ctx context.Context,
 	container app.EnvStdioContainer,
@@ -157,6 +240,7 @@ func (g *generator) execPlugins(
 	image bufimage.Image,
 	includeImports bool,
 	includeWellKnownTypes bool,
+	cache Cache,
 ) ([]*pluginpb.CodeGeneratorResponse, error) {
 	imageProvider := newImageProvider(image)
 	// Collect all of the plugin jobs so that they can be executed in parallel.
@@ -165,15 +249,31 @@ func (g *generator) execPlugins(
 	for i, pluginConfig := range config.PluginConfigs {
 		index := i
 		currentPluginConfig := pluginConfig
+		pluginImage := image
+		pluginImageProvider := imageProvider
+		if len(pluginConfig.Paths) > 0 || len(pluginConfig.Types) > 0 {
+			var err error
+			pluginImage, err = imageForPluginConfig(image, pluginConfig)
+			if err != nil {
+				return nil, fmt.Errorf("plugin %s: %w", pluginConfig.PluginName(), err)
+			}
+			if pluginImage == nil {
+				g.logger.Debug("no_files_to_generate", zap.String("plugin", pluginConfig.PluginName()))
+				responses[index] = &pluginpb.CodeGeneratorResponse{}
+				continue
+			}
+			pluginImageProvider = newImageProvider(pluginImage)
+		}
 		if pluginConfig.Remote != "" {
 			jobs = append(jobs, func(ctx context.Context) error {
 				response, err := g.execRemotePlugin(
 					ctx,
 					container,
-					image,
+					pluginImage,
 					currentPluginConfig,
 					includeImports,
 					includeWellKnownTypes,
+					cache,
 				)
 				if err != nil {
 					return err
@@ -188,10 +288,11 @@ func (g *generator) execPlugins(
 					ctx,
 					container,
 					g.appprotoexecGenerator,
-					imageProvider,
+					pluginImageProvider,
 					currentPluginConfig,
 					includeImports,
 					includeWellKnownTypes,
+					cache,
 				)
 				if err != nil {
 					return err
@@ -239,27 +340,56 @@ func (g *generator) execLocalPlugin(
 	pluginConfig *PluginConfig,
 	includeImports bool,
 	includeWellKnownTypes bool,
+	cache Cache,
 ) (*pluginpb.CodeGeneratorResponse, error) {
 	pluginImages, err := imageProvider.GetImages(pluginConfig.Strategy)
 	if err != nil {
 		return nil, err
 	}
+	requests := bufimage.ImagesToCodeGeneratorRequests(
+		pluginImages,
+		pluginConfig.Opt,
+		nil,
+		includeImports,
+		includeWellKnownTypes,
+	)
+	var cacheKey string
+	if cache != nil {
+		cacheKey, err = localPluginCacheKey(container, pluginConfig, requests)
+		if err != nil {
+			// If the plugin binary cannot be found, the plugin invocation will report the error.
+			g.logger.Debug("cache_key", zap.String("plugin", pluginConfig.PluginName()), zap.Error(err))
+		}
+	}
+	if cacheKey != "" {
+		response, ok, err := cache.Get(ctx, cacheKey)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			g.logger.Debug("cache_hit", zap.String("plugin", pluginConfig.PluginName()))
+			return response, nil
+		}
+	}
 	response, err := appprotoexecGenerator.Generate(
 		ctx,
 		container,
 		pluginConfig.Name,
-		bufimage.ImagesToCodeGeneratorRequests(
-			pluginImages,
-			pluginConfig.Opt,
-			nil,
-			includeImports,
-			includeWellKnownTypes,
-		),
+		requests,
 		appprotoexec.GenerateWithPluginPath(pluginConfig.Path),
+		appprotoexec.GenerateWithTimeout(pluginConfig.Timeout),
+		appprotoexec.GenerateWithEnvKeys(pluginConfig.Env),
+		appprotoexec.GenerateWithMaxOutputBytes(pluginConfig.MaxOutputBytes),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("plugin %s: %v", pluginConfig.PluginName(), err)
 	}
+	// Responses with errors are not cached so that the plugin is retried.
+	if cacheKey != "" && response.GetError() == "" {
+		if err := cache.Put(ctx, cacheKey, response); err != nil {
+			return nil, err
+		}
+	}
 	return response, nil
 }
 
@@ -270,11 +400,28 @@ func (g *generator) execRemotePlugin(
 	pluginConfig *PluginConfig,
 	includeImports bool,
 	includeWellKnownTypes bool,
+	cache Cache,
 ) (*pluginpb.CodeGeneratorResponse, error) {
 	remote, owner, name, version, err := bufplugin.ParsePluginVersionPath(pluginConfig.Remote)
 	if err != nil {
 		return nil, fmt.Errorf("invalid plugin path: %w", err)
 	}
+	var cacheKey string
+	// Only pinned versions are cached, as the latest version of a plugin can change.
+	if cache != nil && version != "" {
+		cacheKey, err = remotePluginCacheKey(pluginConfig, image, includeImports, includeWellKnownTypes)
+		if err != nil {
+			return nil, err
+		}
+		response, ok, err := cache.Get(ctx, cacheKey)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			g.logger.Debug("cache_hit", zap.String("plugin", pluginConfig.PluginName()))
+			return response, nil
+		}
+	}
 	generateService, err := g.registryProvider.NewGenerateService(ctx, remote)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create generate service for remote %q: %w", remote, err)
@@ -319,7 +466,72 @@ func (g *generator) execRemotePlugin(
 		}
 		g.logger.Sugar().Warn(warnMsg)
 	}
-	return responses[0], nil
+	response := responses[0]
+	// Responses with errors are not cached so that the plugin is retried.
+	if cacheKey != "" && response.GetError() == "" {
+		if err := cache.Put(ctx, cacheKey, response); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// localPluginCacheKey returns the cache key for a local plugin invocation.
+//
+// If the plugin has an env allow-list, the allowed environment variables
+// are part of the key, as they are the only variables the plugin can read.
+func localPluginCacheKey(
+	container app.EnvContainer,
+	pluginConfig *PluginConfig,
+	requests []*pluginpb.CodeGeneratorRequest,
+) (string, error) {
+	binaryPath, err := appprotoexec.LookPath(
+		pluginConfig.Name,
+		appprotoexec.HandlerWithPluginPath(pluginConfig.Path),
+	)
+	if err != nil {
+		return "", err
+	}
+	cacheKeyHasher := newCacheKeyHasher()
+	cacheKeyHasher.addString("local")
+	cacheKeyHasher.addString(pluginConfig.Name)
+	cacheKeyHasher.addString(pluginConfig.Version)
+	if err := cacheKeyHasher.addFile(binaryPath); err != nil {
+		return "", err
+	}
+	cacheKeyHasher.addBool(pluginConfig.Env != nil)
+	envKeys := make([]string, len(pluginConfig.Env))
+	copy(envKeys, pluginConfig.Env)
+	sort.Strings(envKeys)
+	for _, envKey := range envKeys {
+		cacheKeyHasher.addString(envKey)
+		cacheKeyHasher.addString(container.Env(envKey))
+	}
+	for _, request := range requests {
+		if err := cacheKeyHasher.addMessage(request); err != nil {
+			return "", err
+		}
+	}
+	return cacheKeyHasher.key(), nil
+}
+
+// remotePluginCacheKey returns the cache key for a remote plugin invocation.
+func remotePluginCacheKey(
+	pluginConfig *PluginConfig,
+	image bufimage.Image,
+	includeImports bool,
+	includeWellKnownTypes bool,
+) (string, error) {
+	cacheKeyHasher := newCacheKeyHasher()
+	cacheKeyHasher.addString("remote")
+	cacheKeyHasher.addString(pluginConfig.Remote)
+	cacheKeyHasher.addString(pluginConfig.Opt)
+	cacheKeyHasher.addBool(includeImports)
+	cacheKeyHasher.addBool(includeWellKnownTypes)
+	if err := cacheKeyHasher.addMessage(bufimage.ImageToProtoImage(image)); err != nil {
+		return "", err
+	}
+	return cacheKeyHasher.key(), nil
 }
 
 // modifyImage modifies the image according to the given configuration (i.e. managed mode).
@@ -335,26 +547,57 @@ func modifyImage(
 		return nil
 	}
 	sweeper := bufimagemodify.NewFileOptionSweeper()
-	modifier, err := newModifier(logger, config.ManagedConfig, sweeper)
+	overrides := resolveOverrides(logger, config.ManagedConfig.Override, image)
+	modifier, err := newModifier(logger, config.ManagedConfig, overrides, sweeper)
 	if err != nil {
 		return err
 	}
 	modifier = bufimagemodify.Merge(modifier, bufimagemodify.ModifierFunc(sweeper.Sweep))
+	if config.ManagedConfig.JSTypeConfig != nil {
+		fieldOptionSweeper := bufimagemodify.NewFieldOptionSweeper()
+		jsTypeModifier, err := bufimagemodify.JSType(
+			logger,
+			fieldOptionSweeper,
+			config.ManagedConfig.JSTypeConfig.Default,
+			config.ManagedConfig.JSTypeConfig.Except,
+			config.ManagedConfig.JSTypeConfig.Override,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to construct jstype modifier: %w", err)
+		}
+		modifier = bufimagemodify.Merge(modifier, jsTypeModifier)
+		modifier = bufimagemodify.Merge(modifier, bufimagemodify.ModifierFunc(fieldOptionSweeper.Sweep))
+	}
 	return modifier.Modify(ctx, image)
 }
 
 func newModifier(
 	logger *zap.Logger,
 	managedConfig *ManagedConfig,
+	overrides map[string]map[string]string,
 	sweeper bufimagemodify.Sweeper,
 ) (bufimagemodify.Modifier, error) {
 	modifier := bufimagemodify.NewMultiModifier(
-		bufimagemodify.JavaOuterClassname(logger, sweeper, managedConfig.Override[bufimagemodify.JavaOuterClassNameID]),
-		bufimagemodify.ObjcClassPrefix(logger, sweeper, managedConfig.Override[bufimagemodify.ObjcClassPrefixID]),
-		bufimagemodify.CsharpNamespace(logger, sweeper, managedConfig.Override[bufimagemodify.CsharpNamespaceID]),
-		bufimagemodify.PhpNamespace(logger, sweeper, managedConfig.Override[bufimagemodify.PhpNamespaceID]),
-		bufimagemodify.PhpMetadataNamespace(logger, sweeper, managedConfig.Override[bufimagemodify.PhpMetadataNamespaceID]),
-		bufimagemodify.RubyPackage(logger, sweeper, managedConfig.Override[bufimagemodify.RubyPackageID]),
+		bufimagemodify.JavaOuterClassname(logger, sweeper, overrides[bufimagemodify.JavaOuterClassNameID]),
+		bufimagemodify.CsharpNamespace(logger, sweeper, overrides[bufimagemodify.CsharpNamespaceID]),
+		bufimagemodify.PhpNamespace(logger, sweeper, overrides[bufimagemodify.PhpNamespaceID]),
+		bufimagemodify.PhpMetadataNamespace(logger, sweeper, overrides[bufimagemodify.PhpMetadataNamespaceID]),
+		bufimagemodify.RubyPackage(logger, sweeper, overrides[bufimagemodify.RubyPackageID]),
+	)
+	objcClassPrefix := &ObjcClassPrefixConfig{}
+	if managedConfig.ObjcClassPrefixConfig != nil {
+		objcClassPrefix = managedConfig.ObjcClassPrefixConfig
+	}
+	modifier = bufimagemodify.Merge(
+		modifier,
+		bufimagemodify.ObjcClassPrefix(
+			logger,
+			sweeper,
+			objcClassPrefix.Default,
+			objcClassPrefix.Except,
+			objcClassPrefix.Override,
+			overrides[bufimagemodify.ObjcClassPrefixID],
+		),
 	)
 	javaPackagePrefix := &JavaPackagePrefixConfig{Default: bufimagemodify.DefaultJavaPackagePrefix}
 	if managedConfig.JavaPackagePrefix != nil {
@@ -366,7 +609,7 @@ func newModifier(
 		javaPackagePrefix.Default,
 		javaPackagePrefix.Except,
 		javaPackagePrefix.Override,
-		managedConfig.Override[bufimagemodify.JavaPackageID],
+		overrides[bufimagemodify.JavaPackageID],
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct java_package modifier: %w", err)
@@ -383,7 +626,7 @@ func newModifier(
 		logger,
 		sweeper,
 		javaMultipleFilesValue,
-		managedConfig.Override[bufimagemodify.JavaMultipleFilesID],
+		overrides[bufimagemodify.JavaMultipleFilesID],
 	)
 	if err != nil {
 		return nil, err
@@ -394,7 +637,7 @@ func newModifier(
 			logger,
 			sweeper,
 			*managedConfig.CcEnableArenas,
-			managedConfig.Override[bufimagemodify.CcEnableArenasID],
+			overrides[bufimagemodify.CcEnableArenasID],
 		)
 		if err != nil {
 			return nil, err
@@ -406,7 +649,7 @@ func newModifier(
 			logger,
 			sweeper,
 			*managedConfig.JavaStringCheckUtf8,
-			managedConfig.Override[bufimagemodify.JavaStringCheckUtf8ID],
+			overrides[bufimagemodify.JavaStringCheckUtf8ID],
 		)
 		if err != nil {
 			return nil, err
@@ -418,7 +661,7 @@ func newModifier(
 			logger,
 			sweeper,
 			*managedConfig.OptimizeFor,
-			managedConfig.Override[bufimagemodify.OptimizeForID],
+			overrides[bufimagemodify.OptimizeForID],
 		)
 		if err != nil {
 			return nil, err
@@ -435,7 +678,7 @@ func newModifier(
 			managedConfig.GoPackagePrefixConfig.Default,
 			managedConfig.GoPackagePrefixConfig.Except,
 			managedConfig.GoPackagePrefixConfig.Override,
-			managedConfig.Override[bufimagemodify.GoPackageID],
+			overrides[bufimagemodify.GoPackageID],
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to construct go_package modifier: %w", err)
@@ -445,6 +688,76 @@ func newModifier(
 			goPackageModifier,
 		)
 	}
+	if managedConfig.SwiftPrefixConfig != nil {
+		swiftPrefixModifier, err := bufimagemodify.SwiftPrefix(
+			logger,
+			sweeper,
+			managedConfig.SwiftPrefixConfig.Default,
+			managedConfig.SwiftPrefixConfig.Except,
+			managedConfig.SwiftPrefixConfig.Override,
+			overrides[bufimagemodify.SwiftPrefixID],
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct swift_prefix modifier: %w", err)
+		}
+		modifier = bufimagemodify.Merge(modifier, swiftPrefixModifier)
+	}
+	if managedConfig.PhpClassPrefixConfig != nil {
+		phpClassPrefixModifier, err := bufimagemodify.PhpClassPrefix(
+			logger,
+			sweeper,
+			managedConfig.PhpClassPrefixConfig.Default,
+			managedConfig.PhpClassPrefixConfig.Except,
+			managedConfig.PhpClassPrefixConfig.Override,
+			overrides[bufimagemodify.PhpClassPrefixID],
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct php_class_prefix modifier: %w", err)
+		}
+		modifier = bufimagemodify.Merge(modifier, phpClassPrefixModifier)
+	}
+	if managedConfig.CcGenericServicesConfig != nil {
+		ccGenericServicesModifier, err := bufimagemodify.CcGenericServices(
+			logger,
+			sweeper,
+			managedConfig.CcGenericServicesConfig.Default,
+			managedConfig.CcGenericServicesConfig.Except,
+			managedConfig.CcGenericServicesConfig.Override,
+			overrides[bufimagemodify.CcGenericServicesID],
+		)
+		if err != nil {
+			return nil, err
+		}
+		modifier = bufimagemodify.Merge(modifier, ccGenericServicesModifier)
+	}
+	if managedConfig.JavaGenericServicesConfig != nil {
+		javaGenericServicesModifier, err := bufimagemodify.JavaGenericServices(
+			logger,
+			sweeper,
+			managedConfig.JavaGenericServicesConfig.Default,
+			managedConfig.JavaGenericServicesConfig.Except,
+			managedConfig.JavaGenericServicesConfig.Override,
+			overrides[bufimagemodify.JavaGenericServicesID],
+		)
+		if err != nil {
+			return nil, err
+		}
+		modifier = bufimagemodify.Merge(modifier, javaGenericServicesModifier)
+	}
+	if managedConfig.PyGenericServicesConfig != nil {
+		pyGenericServicesModifier, err := bufimagemodify.PyGenericServices(
+			logger,
+			sweeper,
+			managedConfig.PyGenericServicesConfig.Default,
+			managedConfig.PyGenericServicesConfig.Except,
+			managedConfig.PyGenericServicesConfig.Override,
+			overrides[bufimagemodify.PyGenericServicesID],
+		)
+		if err != nil {
+			return nil, err
+		}
+		modifier = bufimagemodify.Merge(modifier, pyGenericServicesModifier)
+	}
 	return modifier, nil
 }
 
@@ -483,6 +796,11 @@ type generateOptions struct {
 	baseOutDirPath        string
 	includeImports        bool
 	includeWellKnownTypes bool
+	cache                 Cache
+	check                 bool
+	checkPathWriter       io.Writer
+	checkDiffWriter       io.Writer
+	partialInput          bool
 }
 
 func newGenerateOptions() *generateOptions {
@@ -19,6 +19,11 @@ import (
 	"sync"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	imagev1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/image/v1"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"google.golang.org/protobuf/proto"
 )
 
 // imageProvider is used to provide the images used
@@ -58,3 +63,46 @@ func (p *imageProvider) GetImages(strategy Strategy) ([]bufimage.Image, error) {
 		return nil, fmt.Errorf("unknown strategy: %v", strategy)
 	}
 }
+
+// imageForPluginConfig returns the image filtered to the paths and types
+// of the PluginConfig.
+//
+// Returns nil if none of the files in the image are within the paths.
+func imageForPluginConfig(image bufimage.Image, pluginConfig *PluginConfig) (bufimage.Image, error) {
+	if len(pluginConfig.Paths) > 0 {
+		if !imageHasNonImportWithinPaths(image, pluginConfig.Paths) {
+			return nil, nil
+		}
+		var err error
+		// The paths may not exist if the input is restricted with --path.
+		image, err = bufimage.ImageWithOnlyPathsAllowNotExist(image, pluginConfig.Paths, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(pluginConfig.Types) > 0 {
+		// ImageFilteredByTypes modifies the FileDescriptorProtos in place, and the
+		// image is shared with the other plugins.
+		clonedImage, err := bufimage.NewImageForProto(
+			proto.Clone(bufimage.ImageToProtoImage(image)).(*imagev1.Image),
+		)
+		if err != nil {
+			return nil, err
+		}
+		image, err = bufimageutil.ImageFilteredByTypes(clonedImage, pluginConfig.Types...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return image, nil
+}
+
+func imageHasNonImportWithinPaths(image bufimage.Image, paths []string) bool {
+	pathMap := stringutil.SliceToMap(paths)
+	for _, imageFile := range image.Files() {
+		if !imageFile.IsImport() && normalpath.MapHasEqualOrContainingPath(pathMap, imageFile.Path(), normalpath.Relative) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func writeManagedFileOptions(
+	ctx context.Context,
+	logger *zap.Logger,
+	writer io.Writer,
+	config *Config,
+	image bufimage.Image,
+) error {
+	if err := modifyImage(ctx, logger, config, image); err != nil {
+		return err
+	}
+	for _, imageFile := range image.Files() {
+		if _, err := fmt.Fprintln(writer, imageFile.Path()); err != nil {
+			return err
+		}
+		options := imageFile.Proto().GetOptions()
+		if options == nil {
+			continue
+		}
+		for _, line := range fileOptionLines(options.ProtoReflect()) {
+			if _, err := fmt.Fprintf(writer, "  %s\n", line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fileOptionLines returns a "name: value" line for each of the set
+// options, sorted by field number.
+func fileOptionLines(message protoreflect.Message) []string {
+	var fieldDescriptors []protoreflect.FieldDescriptor
+	message.Range(
+		func(fieldDescriptor protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			fieldDescriptors = append(fieldDescriptors, fieldDescriptor)
+			return true
+		},
+	)
+	sort.Slice(
+		fieldDescriptors,
+		func(i int, j int) bool {
+			return fieldDescriptors[i].Number() < fieldDescriptors[j].Number()
+		},
+	)
+	lines := make([]string, 0, len(fieldDescriptors))
+	for _, fieldDescriptor := range fieldDescriptors {
+		name := string(fieldDescriptor.Name())
+		if fieldDescriptor.IsExtension() {
+			name = "(" + string(fieldDescriptor.FullName()) + ")"
+		}
+		lines = append(lines, name+": "+formatOptionValue(fieldDescriptor, message.Get(fieldDescriptor)))
+	}
+	return lines
+}
+
+func formatOptionValue(fieldDescriptor protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	if fieldDescriptor.IsList() || fieldDescriptor.IsMap() {
+		return value.String()
+	}
+	switch fieldDescriptor.Kind() {
+	case protoreflect.StringKind:
+		return strconv.Quote(value.String())
+	case protoreflect.EnumKind:
+		if enumValueDescriptor := fieldDescriptor.Enum().Values().ByNumber(value.Enum()); enumValueDescriptor != nil {
+			return string(enumValueDescriptor.Name())
+		}
+		return strconv.Itoa(int(value.Enum()))
+	default:
+		return value.String()
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"go.uber.org/zap"
+)
+
+const (
+	// overridePackagePrefix is the prefix of override keys that match
+	// all of the files with the given package.
+	overridePackagePrefix = "package:"
+	// overrideModulePrefix is the prefix of override keys that match
+	// all of the files in the given module.
+	overrideModulePrefix = "module:"
+)
+
+// validateOverrideKey validates a key of the managed mode override
+// configuration for the given override ID.
+//
+// A key is either a file path, a glob over file paths, a package name
+// prefixed with "package:", or a module prefixed with "module:".
+func validateOverrideKey(overrideID string, key string) error {
+	switch {
+	case strings.HasPrefix(key, overridePackagePrefix):
+		if strings.TrimPrefix(key, overridePackagePrefix) == "" {
+			return fmt.Errorf("empty package provided for override: %s", overrideID)
+		}
+		return nil
+	case strings.HasPrefix(key, overrideModulePrefix):
+		if _, err := bufmoduleref.ModuleIdentityForString(strings.TrimPrefix(key, overrideModulePrefix)); err != nil {
+			return fmt.Errorf("invalid module: %s provided for override: %s: %w", key, overrideID, err)
+		}
+		return nil
+	}
+	normalizedImportPath, err := normalpath.NormalizeAndValidate(key)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to normalize import path: %s provided for override: %s",
+			key,
+			overrideID,
+		)
+	}
+	if key != normalizedImportPath {
+		return fmt.Errorf(
+			"override can only take normalized import paths, invalid import path: %s provided for override: %s",
+			key,
+			overrideID,
+		)
+	}
+	if isOverrideGlob(key) {
+		if _, err := path.Match(key, ""); err != nil {
+			return fmt.Errorf("invalid glob: %s provided for override: %s: %w", key, overrideID, err)
+		}
+	}
+	return nil
+}
+
+// resolveOverrides resolves the override keys of each override ID into the
+// paths of the files in the image that they apply to.
+//
+// If more than one key matches a file, the most specific key is used, in order:
+//
+//  1. The file path.
+//  2. A glob over file paths. If multiple globs match, the longest one is used.
+//  3. The package of the file.
+//  4. The module of the file.
+//
+// File paths are always retained so that unused file overrides are still reported
+// by the modifiers.
+func resolveOverrides(
+	logger *zap.Logger,
+	overrides map[string]map[string]string,
+	image bufimage.Image,
+) map[string]map[string]string {
+	if len(overrides) == 0 {
+		return overrides
+	}
+	resolvedOverrides := make(map[string]map[string]string, len(overrides))
+	for overrideID, override := range overrides {
+		resolvedOverride := make(map[string]string, len(override))
+		var globs []string
+		for key, value := range override {
+			switch {
+			case strings.HasPrefix(key, overridePackagePrefix), strings.HasPrefix(key, overrideModulePrefix):
+			case isOverrideGlob(key):
+				globs = append(globs, key)
+			default:
+				resolvedOverride[key] = value
+			}
+		}
+		sort.Slice(
+			globs,
+			func(i int, j int) bool {
+				if len(globs[i]) != len(globs[j]) {
+					return len(globs[i]) > len(globs[j])
+				}
+				return globs[i] < globs[j]
+			},
+		)
+		usedKeys := make(map[string]struct{})
+		for _, imageFile := range image.Files() {
+			if _, ok := resolvedOverride[imageFile.Path()]; ok {
+				continue
+			}
+			if key, ok := overrideKeyForFile(override, globs, imageFile); ok {
+				resolvedOverride[imageFile.Path()] = override[key]
+				usedKeys[key] = struct{}{}
+			}
+		}
+		for key := range override {
+			if _, ok := resolvedOverride[key]; ok {
+				continue
+			}
+			if _, ok := usedKeys[key]; !ok {
+				logger.Sugar().Warnf("%s override for %q was unused", overrideID, key)
+			}
+		}
+		resolvedOverrides[overrideID] = resolvedOverride
+	}
+	return resolvedOverrides
+}
+
+// overrideKeyForFile returns the most specific glob, package, or module key that
+// matches the image file. The globs must be sorted from most to least specific.
+func overrideKeyForFile(
+	override map[string]string,
+	globs []string,
+	imageFile bufimage.ImageFile,
+) (string, bool) {
+	for _, glob := range globs {
+		if matchOverrideGlob(glob, imageFile.Path()) {
+			return glob, true
+		}
+	}
+	if pkg := imageFile.Proto().GetPackage(); pkg != "" {
+		if _, ok := override[overridePackagePrefix+pkg]; ok {
+			return overridePackagePrefix + pkg, true
+		}
+	}
+	if moduleIdentity := imageFile.ModuleIdentity(); moduleIdentity != nil {
+		if _, ok := override[overrideModulePrefix+moduleIdentity.IdentityString()]; ok {
+			return overrideModulePrefix + moduleIdentity.IdentityString(), true
+		}
+	}
+	return "", false
+}
+
+// isOverrideGlob returns true if the override key is a glob.
+func isOverrideGlob(key string) bool {
+	return strings.ContainsAny(key, "*?[")
+}
+
+// matchOverrideGlob returns true if the file path matches the glob.
+//
+// The glob is matched per path component with path.Match, and a "**"
+// component matches zero or more path components.
+func matchOverrideGlob(glob string, filePath string) bool {
+	return matchOverrideGlobComponents(strings.Split(glob, "/"), strings.Split(filePath, "/"))
+}
+
+func matchOverrideGlobComponents(globComponents []string, pathComponents []string) bool {
+	for len(globComponents) > 0 {
+		if globComponents[0] == "**" {
+			for i := 0; i <= len(pathComponents); i++ {
+				if matchOverrideGlobComponents(globComponents[1:], pathComponents[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(pathComponents) == 0 {
+			return false
+		}
+		matched, err := path.Match(globComponents[0], pathComponents[0])
+		if err != nil || !matched {
+			return false
+		}
+		globComponents = globComponents[1:]
+		pathComponents = pathComponents[1:]
+	}
+	return len(pathComponents) == 0
+}
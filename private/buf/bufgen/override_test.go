@@ -0,0 +1,121 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestMatchOverrideGlob(t *testing.T) {
+	t.Parallel()
+	testMatchOverrideGlob(t, "acme/*/v1/*.proto", "acme/pet/v1/pet.proto", true)
+	testMatchOverrideGlob(t, "acme/*/v1/*.proto", "acme/pet/v2/pet.proto", false)
+	testMatchOverrideGlob(t, "acme/**", "acme/pet/v1/pet.proto", true)
+	testMatchOverrideGlob(t, "acme/**/*.proto", "acme/pet.proto", true)
+	testMatchOverrideGlob(t, "acme/**/v1/*.proto", "acme/pet/store/v1/store.proto", true)
+	testMatchOverrideGlob(t, "acme/**/v1/*.proto", "acme/pet/store/v2/store.proto", false)
+	testMatchOverrideGlob(t, "*.proto", "acme/pet.proto", false)
+	testMatchOverrideGlob(t, "acme/pet/v?/pet.proto", "acme/pet/v1/pet.proto", true)
+}
+
+func TestValidateOverrideKey(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, validateOverrideKey("JAVA_PACKAGE", "acme/pet/v1/pet.proto"))
+	assert.NoError(t, validateOverrideKey("JAVA_PACKAGE", "acme/**/*.proto"))
+	assert.NoError(t, validateOverrideKey("JAVA_PACKAGE", "package:acme.pet.v1"))
+	assert.NoError(t, validateOverrideKey("JAVA_PACKAGE", "module:buf.build/acme/pet"))
+	assert.Error(t, validateOverrideKey("JAVA_PACKAGE", "./acme/pet/v1/pet.proto"))
+	assert.Error(t, validateOverrideKey("JAVA_PACKAGE", "acme/[pet"))
+	assert.Error(t, validateOverrideKey("JAVA_PACKAGE", "package:"))
+	assert.Error(t, validateOverrideKey("JAVA_PACKAGE", "module:acme/pet"))
+}
+
+func TestResolveOverrides(t *testing.T) {
+	t.Parallel()
+	moduleIdentity, err := bufmoduleref.NewModuleIdentity("buf.build", "acme", "pet")
+	require.NoError(t, err)
+	image := testNewImage(
+		t,
+		testNewImageFile(t, "acme/pet/v1/pet.proto", "acme.pet.v1", moduleIdentity),
+		testNewImageFile(t, "acme/pet/v1/store.proto", "acme.pet.v1", moduleIdentity),
+		testNewImageFile(t, "acme/pet/v2/pet.proto", "acme.pet.v2", moduleIdentity),
+		testNewImageFile(t, "acme/common/v1/common.proto", "acme.common.v1", moduleIdentity),
+		testNewImageFile(t, "other/v1/other.proto", "other.v1", nil),
+	)
+	resolvedOverrides := resolveOverrides(
+		zap.NewNop(),
+		map[string]map[string]string{
+			"JAVA_PACKAGE": {
+				"acme/pet/v1/store.proto":   "file",
+				"acme/pet/**":               "short-glob",
+				"acme/pet/v1/*.proto":       "long-glob",
+				"package:acme.pet.v2":       "package",
+				"package:acme.common.v1":    "package",
+				"module:buf.build/acme/pet": "module",
+				"acme/missing.proto":        "missing",
+			},
+		},
+		image,
+	)
+	assert.Equal(
+		t,
+		map[string]map[string]string{
+			"JAVA_PACKAGE": {
+				"acme/pet/v1/pet.proto":       "long-glob",
+				"acme/pet/v1/store.proto":     "file",
+				"acme/pet/v2/pet.proto":       "short-glob",
+				"acme/common/v1/common.proto": "package",
+				"acme/missing.proto":          "missing",
+			},
+		},
+		resolvedOverrides,
+	)
+}
+
+func testMatchOverrideGlob(t *testing.T, glob string, filePath string, expected bool) {
+	assert.Equal(t, expected, matchOverrideGlob(glob, filePath), "%s %s", glob, filePath)
+}
+
+func testNewImage(t *testing.T, imageFiles ...bufimage.ImageFile) bufimage.Image {
+	image, err := bufimage.NewImage(imageFiles)
+	require.NoError(t, err)
+	return image
+}
+
+func testNewImageFile(t *testing.T, path string, pkg string, moduleIdentity bufmoduleref.ModuleIdentity) bufimage.ImageFile {
+	imageFile, err := bufimage.NewImageFile(
+		&descriptorpb.FileDescriptorProto{
+			Name:    proto.String(path),
+			Package: proto.String(pkg),
+			Syntax:  proto.String("proto3"),
+		},
+		moduleIdentity,
+		"",
+		path,
+		false,
+		false,
+		nil,
+	)
+	require.NoError(t, err)
+	return imageFile
+}
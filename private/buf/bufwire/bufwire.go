@@ -40,6 +40,11 @@ type ImageConfig interface {
 	Config() *bufconfig.Config
 }
 
+// NewImageConfig returns a new ImageConfig.
+func NewImageConfig(image bufimage.Image, config *bufconfig.Config) ImageConfig {
+	return newImageConfig(image, config)
+}
+
 // ImageConfigReader is an ImageConfig reader.
 type ImageConfigReader interface {
 	// GetImageConfigs gets the ImageConfig for the fetch value.
@@ -204,18 +209,43 @@ func NewImageWriter(
 	)
 }
 
-// ProtoEncodingWriter is a writer that writes a protobuf message in different encoding (e.g. JSON).
+// ProtoEncodingReader is a reader that reads a protobuf message in different encodings.
+type ProtoEncodingReader interface {
+	// GetMessage reads the message by the messageRef.
+	//
+	// Currently, this support bin, json and txt format.
+	GetMessage(
+		ctx context.Context,
+		container app.EnvStdinContainer,
+		image bufimage.Image,
+		typeName string,
+		messageRef buffetch.ProtoEncodingRef,
+	) (proto.Message, error)
+}
+
+// NewProtoEncodingReader returns a new ProtoEncodingReader.
+func NewProtoEncodingReader(
+	logger *zap.Logger,
+	fetchReader buffetch.ProtoEncodingReader,
+) ProtoEncodingReader {
+	return newProtoEncodingReader(
+		logger,
+		fetchReader,
+	)
+}
+
+// ProtoEncodingWriter is a writer that writes a protobuf message in different encodings.
 type ProtoEncodingWriter interface {
 	// PutMessage writes the message to the path, which can be
 	// a path in file system, or stdout represented by "-".
 	//
-	// Currently, this only support json format.
+	// Currently, this support bin, json and txt format.
 	PutMessage(
 		ctx context.Context,
 		container app.EnvStdoutContainer,
 		image bufimage.Image,
 		message proto.Message,
-		path string,
+		messageRef buffetch.ProtoEncodingRef,
 	) error
 }
 
@@ -0,0 +1,103 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufwire
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufreflect"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"go.opencensus.io/trace"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+type protoEncodingReader struct {
+	logger      *zap.Logger
+	fetchReader buffetch.ProtoEncodingReader
+}
+
+func newProtoEncodingReader(
+	logger *zap.Logger,
+	fetchReader buffetch.ProtoEncodingReader,
+) *protoEncodingReader {
+	return &protoEncodingReader{
+		logger:      logger,
+		fetchReader: fetchReader,
+	}
+}
+
+func (p *protoEncodingReader) GetMessage(
+	ctx context.Context,
+	container app.EnvStdinContainer,
+	image bufimage.Image,
+	typeName string,
+	messageRef buffetch.ProtoEncodingRef,
+) (_ proto.Message, retErr error) {
+	ctx, span := trace.StartSpan(ctx, "get_message")
+	defer span.End()
+	// Currently, this support bin, json and txt format.
+	resolver, err := protoencoding.NewResolver(
+		bufimage.ImageToFileDescriptors(
+			image,
+		)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var unmarshaler protoencoding.Unmarshaler
+	switch messageRef.MessageEncoding() {
+	case buffetch.MessageEncodingBin:
+		unmarshaler = protoencoding.NewWireUnmarshaler(resolver)
+	case buffetch.MessageEncodingJSON:
+		unmarshaler = protoencoding.NewJSONUnmarshaler(resolver)
+	case buffetch.MessageEncodingTxt:
+		unmarshaler = protoencoding.NewTxtUnmarshaler(resolver)
+	default:
+		return nil, fmt.Errorf("unknown message encoding type: %v", messageRef.MessageEncoding())
+	}
+	readCloser, err := p.fetchReader.GetMessageFile(ctx, container, messageRef)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, readCloser.Close())
+	}()
+	data, err := io.ReadAll(readCloser)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		if messageRef.Path() == "" {
+			return nil, errors.New("stdin is required as the input")
+		}
+		return nil, fmt.Errorf("length of data read from %q is zero", messageRef.Path())
+	}
+	message, err := bufreflect.NewMessage(ctx, image, typeName)
+	if err != nil {
+		return nil, err
+	}
+	if err := unmarshaler.Unmarshal(data, message); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the message: %v", err)
+	}
+	return message, nil
+}
@@ -16,6 +16,7 @@ package bufwire
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
@@ -47,24 +48,23 @@ func (i *protoEncodingWriter) PutMessage(
 	container app.EnvStdoutContainer,
 	image bufimage.Image,
 	message proto.Message,
-	path string,
+	messageRef buffetch.ProtoEncodingRef,
 ) (retErr error) {
 	ctx, span := trace.StartSpan(ctx, "put_message")
 	defer span.End()
-	// Currently, this only support json format.
-	resolver, err := protoencoding.NewResolver(
-		bufimage.ImageToFileDescriptors(
-			image,
-		)...,
-	)
+	// Stop short for performance.
+	if messageRef.IsNull() {
+		return nil
+	}
+	marshaler, err := newMarshaler(image, messageRef.MessageEncoding())
 	if err != nil {
 		return err
 	}
-	data, err := protoencoding.NewJSONMarshalerIndent(resolver).Marshal(message)
+	data, err := marshaler.Marshal(message)
 	if err != nil {
 		return err
 	}
-	writeCloser, err := i.fetchWriter.PutSingleFile(ctx, container, path)
+	writeCloser, err := i.fetchWriter.PutMessageFile(ctx, container, messageRef)
 	if err != nil {
 		return err
 	}
@@ -74,3 +74,24 @@ func (i *protoEncodingWriter) PutMessage(
 	_, err = writeCloser.Write(data)
 	return err
 }
+
+func newMarshaler(image bufimage.Image, messageEncoding buffetch.MessageEncoding) (protoencoding.Marshaler, error) {
+	switch messageEncoding {
+	case buffetch.MessageEncodingBin:
+		return protoencoding.NewWireMarshaler(), nil
+	case buffetch.MessageEncodingJSON:
+		resolver, err := protoencoding.NewResolver(bufimage.ImageToFileDescriptors(image)...)
+		if err != nil {
+			return nil, err
+		}
+		return protoencoding.NewJSONMarshalerIndent(resolver), nil
+	case buffetch.MessageEncodingTxt:
+		resolver, err := protoencoding.NewResolver(bufimage.ImageToFileDescriptors(image)...)
+		if err != nil {
+			return nil, err
+		}
+		return protoencoding.NewTxtMarshaler(resolver), nil
+	default:
+		return nil, fmt.Errorf("unknown message encoding type: %v", messageEncoding)
+	}
+}
@@ -19,12 +19,16 @@ import (
 	"time"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/alpha/changelog"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/alpha/doc"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/alpha/jsonschema"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/alpha/protoc"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/alpha/registry/token/tokencreate"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/alpha/registry/token/tokendelete"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/alpha/registry/token/tokenget"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/alpha/registry/token/tokenlist"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/decode"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/encode"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/migratev1beta1"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/registry/commit/commitget"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/registry/commit/commitlist"
@@ -57,6 +61,7 @@ import (
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/breaking"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/build"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/export"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/format"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/generate"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/lint"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/lsfiles"
@@ -100,6 +105,7 @@ func NewRootCommand(name string) *appcmd.Command {
 			export.NewCommand("export", builder),
 			lint.NewCommand("lint", builder),
 			breaking.NewCommand("breaking", builder),
+			format.NewCommand("format", builder),
 			generate.NewCommand("generate", builder),
 			lsfiles.NewCommand("ls-files", builder),
 			push.NewCommand("push", builder),
@@ -129,6 +135,7 @@ func NewRootCommand(name string) *appcmd.Command {
 				Short: "Beta commands. Unstable and likely to change.",
 				SubCommands: []*appcmd.Command{
 					decode.NewCommand("decode", builder),
+					encode.NewCommand("encode", builder),
 					migratev1beta1.NewCommand("migrate-v1beta1", builder),
 					{
 						Use:   "registry",
@@ -225,6 +232,9 @@ func NewRootCommand(name string) *appcmd.Command {
 				Short:  "Alpha commands. Unstable and recommended only for experimentation. These may be deleted.",
 				Hidden: true,
 				SubCommands: []*appcmd.Command{
+					changelog.NewCommand("changelog", builder),
+					doc.NewCommand("doc", builder),
+					jsonschema.NewCommand("jsonschema", builder),
 					protoc.NewCommand("protoc", builder),
 					{
 						Use:   "registry",
@@ -17,10 +17,12 @@ package buf
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
@@ -379,6 +381,526 @@ lint:
 	)
 }
 
+func TestFailSARIF(t *testing.T) {
+	t.Parallel()
+	stdout := bytes.NewBuffer(nil)
+	testRun(
+		t,
+		bufcli.ExitCodeFileAnnotation,
+		nil,
+		stdout,
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--error-format",
+		"sarif",
+	)
+	var sarifLog struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID               string `json:"id"`
+						ShortDescription struct {
+							Text string `json:"text"`
+						} `json:"shortDescription"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID string `json:"ruleId"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &sarifLog))
+	assert.Equal(t, "2.1.0", sarifLog.Version)
+	require.Len(t, sarifLog.Runs, 1)
+	var resultRuleIDs []string
+	for _, result := range sarifLog.Runs[0].Results {
+		resultRuleIDs = append(resultRuleIDs, result.RuleID)
+	}
+	assert.Equal(t, []string{"PACKAGE_DIRECTORY_MATCH", "FIELD_LOWER_SNAKE_CASE"}, resultRuleIDs)
+	ruleIDToPurpose := make(map[string]string)
+	for _, rule := range sarifLog.Runs[0].Tool.Driver.Rules {
+		ruleIDToPurpose[rule.ID] = rule.ShortDescription.Text
+	}
+	assert.NotEmpty(t, ruleIDToPurpose["FIELD_LOWER_SNAKE_CASE"])
+	assert.NotEmpty(t, ruleIDToPurpose["PACKAGE_DIRECTORY_MATCH"])
+}
+
+func TestFailGitHubActions(t *testing.T) {
+	t.Parallel()
+	testRunStdout(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		filepath.FromSlash(`::error file=testdata/fail/buf/buf.proto,line=3,endLine=3,col=1,endColumn=15,title=PACKAGE_DIRECTORY_MATCH::Files with package "other" must be within a directory "other" relative to root but were in directory "buf".
+::error file=testdata/fail/buf/buf.proto,line=6,endLine=6,col=9,endColumn=15,title=FIELD_LOWER_SNAKE_CASE::Field name "oneTwo" should be lower_snake_case, such as "one_two".`),
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--error-format",
+		"github-actions",
+	)
+}
+
+func TestLintBaseline(t *testing.T) {
+	t.Parallel()
+	tempDirPath := t.TempDir()
+	protoFilePath := filepath.Join(tempDirPath, "a.proto")
+	baselineFilePath := filepath.Join(tempDirPath, "buf.lint.baseline.yaml")
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(tempDirPath, "buf.yaml"),
+			[]byte(`version: v1
+lint:
+  use:
+    - FIELD_LOWER_SNAKE_CASE
+    - PACKAGE_DIRECTORY_MATCH
+`),
+			0600,
+		),
+	)
+	require.NoError(
+		t,
+		os.WriteFile(
+			protoFilePath,
+			[]byte(`syntax = "proto3";
+
+package a;
+
+message Foo {
+  int64 oneTwo = 1;
+}
+`),
+			0600,
+		),
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"lint",
+		tempDirPath,
+		"--write-baseline",
+		baselineFilePath,
+	)
+	data, err := os.ReadFile(baselineFilePath)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`version: v1
+entries:
+  - id: PACKAGE_DIRECTORY_MATCH
+    path: a.proto
+  - id: FIELD_LOWER_SNAKE_CASE
+    path: a.proto
+    element: a.Foo.oneTwo
+`,
+		string(data),
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"lint",
+		tempDirPath,
+		"--baseline",
+		baselineFilePath,
+	)
+	// shift all lines and add a new violation, only the new violation should be reported
+	require.NoError(
+		t,
+		os.WriteFile(
+			protoFilePath,
+			[]byte(`syntax = "proto3";
+
+// A new comment.
+package a;
+
+// Another new comment.
+message Foo {
+  int64 threeFour = 2;
+  int64 oneTwo = 1;
+}
+`),
+			0600,
+		),
+	)
+	testRunStdout(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		protoFilePath+`:8:9:Field name "threeFour" should be lower_snake_case, such as "three_four".`,
+		"lint",
+		tempDirPath,
+		"--baseline",
+		baselineFilePath,
+	)
+	// the baseline can also be set in the lint configuration, relative to the configuration file
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(tempDirPath, "buf.yaml"),
+			[]byte(`version: v1
+lint:
+  use:
+    - FIELD_LOWER_SNAKE_CASE
+    - PACKAGE_DIRECTORY_MATCH
+  baseline: buf.lint.baseline.yaml
+`),
+			0600,
+		),
+	)
+	testRunStdout(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		protoFilePath+`:8:9:Field name "threeFour" should be lower_snake_case, such as "three_four".`,
+		"lint",
+		tempDirPath,
+	)
+}
+
+func TestLintFix(t *testing.T) {
+	t.Parallel()
+	tempDirPath := t.TempDir()
+	protoFilePath := filepath.Join(tempDirPath, "a.proto")
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(tempDirPath, "buf.yaml"),
+			[]byte(`version: v1
+lint:
+  use:
+    - ENUM_VALUE_PREFIX
+    - ENUM_VALUE_UPPER_SNAKE_CASE
+    - ENUM_ZERO_VALUE_SUFFIX
+    - FIELD_LOWER_SNAKE_CASE
+    - IMPORT_USED
+`),
+			0600,
+		),
+	)
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(tempDirPath, "b.proto"),
+			[]byte(`syntax = "proto2";
+
+package a;
+`),
+			0600,
+		),
+	)
+	require.NoError(
+		t,
+		os.WriteFile(
+			protoFilePath,
+			[]byte(`syntax = "proto2";
+
+package a;
+
+import "b.proto";
+
+message Foo {
+  optional int64 oneTwo = 1;
+  optional Color color = 2 [default = red];
+  optional int64 threeFour = 3;
+  optional int64 three_four = 4;
+}
+
+enum Color {
+  red = 0;
+  COLOR_BLUE = 1;
+}
+`),
+			0600,
+		),
+	)
+	testRunStdoutStderr(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		protoFilePath+`:8:18:Field name "threeFour" should be lower_snake_case, such as "three_four".`,
+		protoFilePath+`:5:1:Removed unused import "b.proto".
+		`+protoFilePath+`:8:18:Renamed field "oneTwo" to "one_two".
+		`+protoFilePath+`:15:3:Renamed enum value "red" to "COLOR_RED_UNSPECIFIED".`,
+		"lint",
+		tempDirPath,
+		"--fix",
+	)
+	data, err := os.ReadFile(protoFilePath)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`syntax = "proto2";
+
+package a;
+
+message Foo {
+  optional int64 one_two = 1;
+  optional Color color = 2 [default = COLOR_RED_UNSPECIFIED];
+  optional int64 threeFour = 3;
+  optional int64 three_four = 4;
+}
+
+enum Color {
+  COLOR_RED_UNSPECIFIED = 0;
+  COLOR_BLUE = 1;
+}
+`,
+		string(data),
+	)
+}
+
+func TestLintFixPath(t *testing.T) {
+	t.Parallel()
+	tempDirPath := t.TempDir()
+	protoFilePath := filepath.Join(tempDirPath, "a.proto")
+	otherProtoFilePath := filepath.Join(tempDirPath, "b.proto")
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(tempDirPath, "buf.yaml"),
+			[]byte(`version: v1
+lint:
+  use:
+    - ENUM_VALUE_PREFIX
+    - ENUM_VALUE_UPPER_SNAKE_CASE
+`),
+			0600,
+		),
+	)
+	require.NoError(
+		t,
+		os.WriteFile(
+			protoFilePath,
+			[]byte(`syntax = "proto2";
+
+package a;
+
+enum Color {
+  red = 0;
+  COLOR_BLUE = 1;
+}
+`),
+			0600,
+		),
+	)
+	require.NoError(
+		t,
+		os.WriteFile(
+			otherProtoFilePath,
+			[]byte(`syntax = "proto2";
+
+package a;
+
+import "a.proto";
+
+message Foo {
+  optional Color color = 1 [default = red];
+}
+`),
+			0600,
+		),
+	)
+	// b.proto is not a target, but the reference within it must still be
+	// updated so that the module still compiles
+	testRunStdoutStderr(
+		t,
+		nil,
+		0,
+		``,
+		protoFilePath+`:6:3:Renamed enum value "red" to "COLOR_RED".`,
+		"lint",
+		tempDirPath,
+		"--path",
+		protoFilePath,
+		"--fix",
+	)
+	data, err := os.ReadFile(otherProtoFilePath)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`syntax = "proto2";
+
+package a;
+
+import "a.proto";
+
+message Foo {
+  optional Color color = 1 [default = COLOR_RED];
+}
+`,
+		string(data),
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"lint",
+		tempDirPath,
+	)
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+	tempDirPath := t.TempDir()
+	protoFilePath := filepath.Join(tempDirPath, "a.proto")
+	require.NoError(
+		t,
+		os.WriteFile(
+			protoFilePath,
+			[]byte(`syntax = "proto3";
+package a;
+import "c.proto";
+import "b.proto";
+// Foo is a message.
+message Foo { string one = 1; }
+`),
+			0600,
+		),
+	)
+	formatted := `syntax = "proto3";
+
+package a;
+
+import "b.proto";
+import "c.proto";
+
+// Foo is a message.
+message Foo {
+  string one = 1;
+}
+`
+	testRunStdout(
+		t,
+		nil,
+		0,
+		formatted,
+		"format",
+		protoFilePath,
+	)
+	testRunStdout(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		`
+		diff -u `+protoFilePath+`.orig `+protoFilePath+`
+		--- `+protoFilePath+`.orig
+		+++ `+protoFilePath+`
+		@@ -1,6 +1,11 @@
+		syntax = "proto3";
+		+
+		package a;
+		-import "c.proto";
+		+
+		import "b.proto";
+		+import "c.proto";
+		+
+		// Foo is a message.
+		-message Foo { string one = 1; }
+		+message Foo {
+		+  string one = 1;
+		+}
+		`,
+		"format",
+		tempDirPath,
+		"--diff",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		"",
+		"format",
+		tempDirPath,
+		"--write",
+	)
+	data, err := os.ReadFile(protoFilePath)
+	require.NoError(t, err)
+	assert.Equal(t, formatted, string(data))
+	testRunStdout(
+		t,
+		nil,
+		0,
+		"",
+		"format",
+		tempDirPath,
+		"--diff",
+	)
+}
+
+func TestFormatProtoFile(t *testing.T) {
+	t.Parallel()
+	tempDirPath := t.TempDir()
+	aProtoFilePath := filepath.Join(tempDirPath, "a.proto")
+	bProtoFilePath := filepath.Join(tempDirPath, "b.proto")
+	unformatted := `syntax = "proto3";
+package a;
+message Foo { string one = 1; }
+`
+	require.NoError(t, os.WriteFile(aProtoFilePath, []byte(unformatted), 0600))
+	require.NoError(t, os.WriteFile(bProtoFilePath, []byte(unformatted), 0600))
+	formatted := `syntax = "proto3";
+
+package a;
+
+message Foo {
+  string one = 1;
+}
+`
+	testRunStdout(
+		t,
+		nil,
+		0,
+		formatted,
+		"format",
+		aProtoFilePath,
+	)
+	testRunStdout(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		`
+		diff -u `+aProtoFilePath+`.orig `+aProtoFilePath+`
+		--- `+aProtoFilePath+`.orig
+		+++ `+aProtoFilePath+`
+		@@ -1,3 +1,7 @@
+		syntax = "proto3";
+		+
+		package a;
+		-message Foo { string one = 1; }
+		+
+		+message Foo {
+		+  string one = 1;
+		+}
+		`,
+		"format",
+		aProtoFilePath,
+		"--diff",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		"",
+		"format",
+		aProtoFilePath,
+		"--write",
+	)
+	data, err := os.ReadFile(aProtoFilePath)
+	require.NoError(t, err)
+	assert.Equal(t, formatted, string(data))
+	data, err = os.ReadFile(bProtoFilePath)
+	require.NoError(t, err)
+	assert.Equal(t, unformatted, string(data))
+}
+
 func TestFail13(t *testing.T) {
 	t.Parallel()
 	// this tests that we still use buf.mod if it exists
@@ -400,11 +922,11 @@ func TestFailCheckBreaking1(t *testing.T) {
 		nil,
 		bufcli.ExitCodeFileAnnotation,
 		filepath.FromSlash(`
-		../../../bufpkg/bufcheck/bufbreaking/testdata/breaking_field_no_delete/1.proto:5:1:Previously present field "3" with name "three" on message "Two" was deleted.
-		../../../bufpkg/bufcheck/bufbreaking/testdata/breaking_field_no_delete/1.proto:10:1:Previously present field "3" with name "three" on message "Three" was deleted.
-		../../../bufpkg/bufcheck/bufbreaking/testdata/breaking_field_no_delete/1.proto:12:5:Previously present field "3" with name "three" on message "Five" was deleted.
-		../../../bufpkg/bufcheck/bufbreaking/testdata/breaking_field_no_delete/1.proto:22:3:Previously present field "3" with name "three" on message "Seven" was deleted.
-		../../../bufpkg/bufcheck/bufbreaking/testdata/breaking_field_no_delete/2.proto:57:1:Previously present field "3" with name "three" on message "Nine" was deleted.
+		../../../bufpkg/bufcheck/bufbreaking/testdata/breaking_field_no_delete/1.proto:5:1:Previously present field "3" with name "three" on message "Two" was deleted. (source: breaking, wire: breaking, json: breaking)
+		../../../bufpkg/bufcheck/bufbreaking/testdata/breaking_field_no_delete/1.proto:10:1:Previously present field "3" with name "three" on message "Three" was deleted. (source: breaking, wire: breaking, json: breaking)
+		../../../bufpkg/bufcheck/bufbreaking/testdata/breaking_field_no_delete/1.proto:12:5:Previously present field "3" with name "three" on message "Five" was deleted. (source: breaking, wire: breaking, json: breaking)
+		../../../bufpkg/bufcheck/bufbreaking/testdata/breaking_field_no_delete/1.proto:22:3:Previously present field "3" with name "three" on message "Seven" was deleted. (source: breaking, wire: breaking, json: breaking)
+		../../../bufpkg/bufcheck/bufbreaking/testdata/breaking_field_no_delete/2.proto:57:1:Previously present field "3" with name "three" on message "Nine" was deleted. (source: breaking, wire: breaking, json: breaking)
 		`),
 		"", // stderr should be empty
 		"breaking",
@@ -415,13 +937,34 @@ func TestFailCheckBreaking1(t *testing.T) {
 	)
 }
 
+func TestFailCheckBreakingLevel(t *testing.T) {
+	t.Parallel()
+	testRunStdoutStderr(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		filepath.FromSlash(`
+		../../../bufpkg/bufcheck/bufbreaking/testdata/breaking_rpc_no_delete/1.proto:7:1:Previously present RPC "Baz" on service "Two" was deleted. (source: breaking, wire: breaking, json: breaking)
+		../../../bufpkg/bufcheck/bufbreaking/testdata/breaking_rpc_no_delete/2.proto:31:1:Previously present RPC "Baz" on service "Three" was deleted. (source: breaking, wire: breaking, json: breaking)
+		`),
+		"", // stderr should be empty
+		"breaking",
+		// can't bother right now to filepath.Join this
+		"../../../bufpkg/bufcheck/bufbreaking/testdata/breaking_rpc_no_delete",
+		"--against",
+		"../../../bufpkg/bufcheck/bufbreaking/testdata_previous/breaking_rpc_no_delete",
+		"--level",
+		"wire",
+	)
+}
+
 func TestFailCheckBreaking2(t *testing.T) {
 	t.Parallel()
 	testRunStdout(
 		t,
 		nil,
 		bufcli.ExitCodeFileAnnotation,
-		filepath.FromSlash(`testdata/protofileref/breaking/a/foo.proto:7:3:Field "2" on message "Foo" changed type from "int32" to "string".`),
+		filepath.FromSlash(`testdata/protofileref/breaking/a/foo.proto:7:3:Field "2" on message "Foo" changed type from "int32" to "string". (source: breaking, wire: breaking, json: breaking)`),
 		"breaking",
 		filepath.Join("testdata", "protofileref", "breaking", "a", "foo.proto"),
 		"--against",
@@ -436,8 +979,8 @@ func TestFailCheckBreaking3(t *testing.T) {
 		nil,
 		bufcli.ExitCodeFileAnnotation,
 		filepath.FromSlash(`
-		<input>:1:1:Previously present file "bar.proto" was deleted.
-		testdata/protofileref/breaking/a/foo.proto:7:3:Field "2" on message "Foo" changed type from "int32" to "string".
+		<input>:1:1:Previously present file "bar.proto" was deleted. (source: breaking, wire: compatible, json: compatible)
+		testdata/protofileref/breaking/a/foo.proto:7:3:Field "2" on message "Foo" changed type from "int32" to "string". (source: breaking, wire: breaking, json: breaking)
 		`),
 		"breaking",
 		filepath.Join("testdata", "protofileref", "breaking", "a", "foo.proto"),
@@ -453,8 +996,8 @@ func TestFailCheckBreaking4(t *testing.T) {
 		nil,
 		bufcli.ExitCodeFileAnnotation,
 		filepath.FromSlash(`
-		testdata/protofileref/breaking/a/bar.proto:5:1:Previously present field "2" with name "value" on message "Bar" was deleted.
-		testdata/protofileref/breaking/a/foo.proto:7:3:Field "2" on message "Foo" changed type from "int32" to "string".
+		testdata/protofileref/breaking/a/bar.proto:5:1:Previously present field "2" with name "value" on message "Bar" was deleted. (source: breaking, wire: breaking, json: breaking)
+		testdata/protofileref/breaking/a/foo.proto:7:3:Field "2" on message "Foo" changed type from "int32" to "string". (source: breaking, wire: breaking, json: breaking)
 		`),
 		"breaking",
 		fmt.Sprintf("%s#include_package_files=true", filepath.Join("testdata", "protofileref", "breaking", "a", "foo.proto")),
@@ -470,8 +1013,8 @@ func TestFailCheckBreaking5(t *testing.T) {
 		nil,
 		bufcli.ExitCodeFileAnnotation,
 		filepath.FromSlash(`
-    <input>:1:1:Previously present file "bar.proto" was deleted.
-		testdata/protofileref/breaking/a/foo.proto:7:3:Field "2" on message "Foo" changed type from "int32" to "string".
+    <input>:1:1:Previously present file "bar.proto" was deleted. (source: breaking, wire: compatible, json: compatible)
+		testdata/protofileref/breaking/a/foo.proto:7:3:Field "2" on message "Foo" changed type from "int32" to "string". (source: breaking, wire: breaking, json: breaking)
 		`),
 		"breaking",
 		filepath.Join("testdata", "protofileref", "breaking", "a", "foo.proto"),
@@ -676,6 +1219,9 @@ FIELD_WIRE_JSON_COMPATIBLE_TYPE                 WIRE_JSON
 ENUM_VALUE_NO_DELETE_UNLESS_NUMBER_RESERVED     WIRE_JSON, WIRE                 Checks that enum values are not deleted from a given enum unless the number is reserved.
 FIELD_NO_DELETE_UNLESS_NUMBER_RESERVED          WIRE_JSON, WIRE                 Checks that fields are not deleted from a given message unless the number is reserved.
 FIELD_WIRE_COMPATIBLE_TYPE                      WIRE                            Checks that fields have wire-compatible types in a given message.
+FIELD_NO_DELETE_UNLESS_DEPRECATED               DEPRECATION                     Checks that fields are not deleted from a given message unless they were previously deprecated.
+MESSAGE_NO_DELETE_UNLESS_DEPRECATED             DEPRECATION                     Checks that messages are not deleted from a given package unless they were previously deprecated.
+RPC_NO_DELETE_UNLESS_DEPRECATED                 DEPRECATION                     Checks that rpcs are not deleted from a given package unless they or their service were previously deprecated.
 		`
 	testRunStdout(
 		t,
@@ -1492,9 +2038,9 @@ func TestBreakingWithPaths(t *testing.T) {
 		t,
 		nil,
 		bufcli.ExitCodeFileAnnotation,
-		`a/v3/a.proto:6:3:Field "1" on message "Foo" changed type from "string" to "int32".
-a/v3/a.proto:7:3:Field "2" with name "Value" on message "Foo" changed option "json_name" from "value" to "Value".
-a/v3/a.proto:7:10:Field "2" on message "Foo" changed name from "value" to "Value".`,
+		`a/v3/a.proto:6:3:Field "1" on message "Foo" changed type from "string" to "int32". (source: breaking, wire: breaking, json: breaking)
+a/v3/a.proto:7:3:Field "2" with name "Value" on message "Foo" changed option "json_name" from "value" to "Value". (source: breaking, wire: compatible, json: breaking)
+a/v3/a.proto:7:10:Field "2" on message "Foo" changed name from "value" to "Value". (source: breaking, wire: compatible, json: breaking)`,
 		"",
 		"breaking",
 		filepath.Join(tempDir, "current.bin"),
@@ -1818,16 +2364,30 @@ func TestDecodeOutput(t *testing.T) {
 			"-o",
 			filepath.Join(outputTempDir, "result.txt"),
 		)
-		readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(outputTempDir)
+		data, err := os.ReadFile(filepath.Join(outputTempDir, "result.txt"))
 		require.NoError(t, err)
-		storagetesting.AssertPathToContent(
+		// the text format is not stable with regards to whitespace
+		assert.Equal(t, "one: 55", strings.Join(strings.Fields(string(data)), " "))
+	})
+	t.Run("bin format option output", func(t *testing.T) {
+		stdin, err := os.Open(filepath.Join("testdata", "decode", "descriptor.plain.bin"))
+		require.NoError(t, err)
+		defer stdin.Close()
+		stdout := bytes.NewBuffer(nil)
+		testRun(
 			t,
-			readWriteBucket,
-			"",
-			map[string]string{
-				"result.txt": `{"one":"55"}`,
-			},
+			0,
+			stdin,
+			stdout,
+			"beta",
+			"decode",
+			filepath.Join(tempDir, "image.bin"),
+			"--type",
+			"buf.Foo",
+			"-o",
+			"-#format=bin",
 		)
+		assert.Equal(t, []byte{0x08, 0x37}, stdout.Bytes())
 	})
 	t.Run("stdout with dash", func(t *testing.T) {
 		stdin, err := os.Open(filepath.Join("testdata", "decode", "descriptor.plain.bin"))
@@ -1851,6 +2411,94 @@ func TestDecodeOutput(t *testing.T) {
 	})
 }
 
+func TestEncode(t *testing.T) {
+	tempDir := t.TempDir()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"build",
+		filepath.Join("testdata", "success"),
+		"-o",
+		filepath.Join(tempDir, "image.bin"),
+	)
+	t.Run("json stdin input", func(t *testing.T) {
+		stdout := bytes.NewBuffer(nil)
+		testRun(
+			t,
+			0,
+			strings.NewReader(`{"one":"55"}`),
+			stdout,
+			"beta",
+			"encode",
+			filepath.Join(tempDir, "image.bin"),
+			"--type",
+			"buf.Foo",
+		)
+		assert.Equal(t, []byte{0x08, 0x37}, stdout.Bytes())
+	})
+	t.Run("txt stdin input", func(t *testing.T) {
+		stdout := bytes.NewBuffer(nil)
+		testRun(
+			t,
+			0,
+			strings.NewReader(`one: 55`),
+			stdout,
+			"beta",
+			"encode",
+			filepath.Join(tempDir, "image.bin"),
+			"--type",
+			"buf.Foo",
+			"--input",
+			"-#format=txt",
+		)
+		assert.Equal(t, []byte{0x08, 0x37}, stdout.Bytes())
+	})
+	t.Run("json file output", func(t *testing.T) {
+		outputTempDir := t.TempDir()
+		testRunStdout(
+			t,
+			strings.NewReader(`one: 55`),
+			0,
+			``,
+			"beta",
+			"encode",
+			filepath.Join(tempDir, "image.bin"),
+			"--type",
+			"buf.Foo",
+			"--input",
+			"-#format=txt",
+			"-o",
+			filepath.Join(outputTempDir, "result.json"),
+		)
+		readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(outputTempDir)
+		require.NoError(t, err)
+		storagetesting.AssertPathToContent(
+			t,
+			readWriteBucket,
+			"",
+			map[string]string{
+				"result.json": `{"one":"55"}`,
+			},
+		)
+	})
+	t.Run("no stdin input", func(t *testing.T) {
+		testRunStdoutStderr(
+			t,
+			nil,
+			1,
+			"",
+			"Failure: stdin is required as the input",
+			"beta",
+			"encode",
+			filepath.Join(tempDir, "image.bin"),
+			"--type",
+			"buf.Foo",
+		)
+	})
+}
+
 func TestDecodeInvalidTypeName(t *testing.T) {
 	tempDir := t.TempDir()
 	testRunStdout(
@@ -0,0 +1,217 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changelog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufchangelog"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	errorFormatFlagName     = "error-format"
+	formatFlagName          = "format"
+	includeImportsFlagName  = "include-imports"
+	pathsFlagName           = "path"
+	excludePathsFlagName    = "exclude-path"
+	configFlagName          = "config"
+	againstFlagName         = "against"
+	againstConfigFlagName   = "against-config"
+	disableSymlinksFlagName = "disable-symlinks"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <input> --against <against-input>",
+		Short: "Print the changes between the input location and the against location.",
+		Long: `Every added, removed, deprecated, and changed file, message, field, enum, enum value, service,
+and RPC is printed to stdout, grouped by package, whether or not the change is breaking. Changes
+to comments and to the deprecated option are included.
+
+` + bufcli.GetInputLong(`the source, module, or image to print the changes of`),
+		Args: cobra.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container, flags)
+			},
+			bufcli.NewErrorInterceptor(),
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	ErrorFormat     string
+	Format          string
+	IncludeImports  bool
+	Paths           []string
+	ExcludePaths    []string
+	Config          string
+	Against         string
+	AgainstConfig   string
+	DisableSymlinks bool
+	// special
+	InputHashtag string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
+	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
+	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
+	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	flagSet.StringVar(
+		&f.ErrorFormat,
+		errorFormatFlagName,
+		"text",
+		fmt.Sprintf(
+			"The format for build errors printed to stderr. Must be one of %s.",
+			stringutil.SliceToString(bufanalysis.AllFormatStrings),
+		),
+	)
+	flagSet.StringVar(
+		&f.Format,
+		formatFlagName,
+		bufchangelog.FormatMarkdown.String(),
+		fmt.Sprintf(
+			"The format for the changelog. Must be one of %s.",
+			stringutil.SliceToString(bufchangelog.AllFormatStrings),
+		),
+	)
+	flagSet.BoolVar(
+		&f.IncludeImports,
+		includeImportsFlagName,
+		false,
+		"Also print the changes of imported files, including dependencies.",
+	)
+	flagSet.StringVar(
+		&f.Config,
+		configFlagName,
+		"",
+		`The file or data to use for configuration.`,
+	)
+	flagSet.StringVar(
+		&f.Against,
+		againstFlagName,
+		"",
+		fmt.Sprintf(
+			`Required. The source, module, or image to compare against. Must be one of format %s.`,
+			buffetch.AllFormatsString,
+		),
+	)
+	flagSet.StringVar(
+		&f.AgainstConfig,
+		againstConfigFlagName,
+		"",
+		`The file or data to use to configure the against source, module, or image.`,
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+) error {
+	if flags.Against == "" {
+		return appcmd.NewInvalidArgumentErrorf("required flag %q not set", againstFlagName)
+	}
+	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
+		return err
+	}
+	format, err := bufchangelog.ParseFormat(flags.Format)
+	if err != nil {
+		return appcmd.NewInvalidArgumentError(err.Error())
+	}
+	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
+	if err != nil {
+		return err
+	}
+	files, err := getFiles(
+		ctx,
+		container,
+		flags,
+		input,
+		flags.Config,
+		false, // files specified must exist on the main input
+	)
+	if err != nil {
+		return err
+	}
+	againstFiles, err := getFiles(
+		ctx,
+		container,
+		flags,
+		flags.Against,
+		flags.AgainstConfig,
+		true, // files are allowed to not exist on the against input
+	)
+	if err != nil {
+		return err
+	}
+	changes, err := bufchangelog.GetChanges(againstFiles, files)
+	if err != nil {
+		return err
+	}
+	return bufchangelog.PrintChanges(container.Stdout(), format, changes)
+}
+
+func getFiles(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+	source string,
+	config string,
+	externalDirOrFilePathsAllowNotExist bool,
+) ([]protosource.File, error) {
+	image, err := bufcli.NewImageForSource(
+		ctx,
+		container,
+		source,
+		flags.ErrorFormat,
+		flags.DisableSymlinks,
+		config,
+		flags.Paths,
+		flags.ExcludePaths,
+		externalDirOrFilePathsAllowNotExist,
+		false, // we need source code info on both sides for comments
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !flags.IncludeImports {
+		image = bufimage.ImageWithoutImports(image)
+	}
+	return protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+}
@@ -0,0 +1,186 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doc
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufdoc"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	errorFormatFlagName     = "error-format"
+	formatFlagName          = "format"
+	includeImportsFlagName  = "include-imports"
+	outputFlagName          = "output"
+	outputFlagShortName     = "o"
+	pathsFlagName           = "path"
+	excludePathsFlagName    = "exclude-path"
+	configFlagName          = "config"
+	disableSymlinksFlagName = "disable-symlinks"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <input>",
+		Short: "Generate documentation for all Protobuf files from the specified input.",
+		Long: `One document is written to the output directory for each package, along with an index document
+that links to every package. Documents include services, RPCs, messages, enums, comments, and
+deprecations, and types are linked across packages.
+
+` + bufcli.GetInputLong(`the source, module, or image to document`),
+		Args: cobra.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container, flags)
+			},
+			bufcli.NewErrorInterceptor(),
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	ErrorFormat     string
+	Format          string
+	IncludeImports  bool
+	Output          string
+	Paths           []string
+	ExcludePaths    []string
+	Config          string
+	DisableSymlinks bool
+	// special
+	InputHashtag string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
+	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
+	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
+	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	flagSet.StringVar(
+		&f.ErrorFormat,
+		errorFormatFlagName,
+		"text",
+		fmt.Sprintf(
+			"The format for build errors printed to stderr. Must be one of %s.",
+			stringutil.SliceToString(bufanalysis.AllFormatStrings),
+		),
+	)
+	flagSet.StringVar(
+		&f.Format,
+		formatFlagName,
+		bufdoc.FormatMarkdown.String(),
+		fmt.Sprintf(
+			"The format for the generated documentation. Must be one of %s.",
+			stringutil.SliceToString(bufdoc.AllFormatStrings),
+		),
+	)
+	flagSet.BoolVar(
+		&f.IncludeImports,
+		includeImportsFlagName,
+		false,
+		"Also generate documentation for imported files, including dependencies.",
+	)
+	flagSet.StringVarP(
+		&f.Output,
+		outputFlagName,
+		outputFlagShortName,
+		"",
+		`The output directory for the generated documentation. Required.`,
+	)
+	flagSet.StringVar(
+		&f.Config,
+		configFlagName,
+		"",
+		`The file or data to use to use for configuration.`,
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+) error {
+	if flags.Output == "" {
+		return appcmd.NewInvalidArgumentErrorf("required flag %q not set", outputFlagName)
+	}
+	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
+		return err
+	}
+	format, err := bufdoc.ParseFormat(flags.Format)
+	if err != nil {
+		return appcmd.NewInvalidArgumentError(err.Error())
+	}
+	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
+	if err != nil {
+		return err
+	}
+	image, err := bufcli.NewImageForSource(
+		ctx,
+		container,
+		input,
+		flags.ErrorFormat,
+		flags.DisableSymlinks,
+		flags.Config,
+		flags.Paths,
+		flags.ExcludePaths,
+		false,
+		false, // we need source code info for comments
+	)
+	if err != nil {
+		return err
+	}
+	if !flags.IncludeImports {
+		image = bufimage.ImageWithoutImports(image)
+	}
+	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(flags.Output, 0755); err != nil {
+		return err
+	}
+	readWriteBucket, err := bufcli.NewStorageosProvider(flags.DisableSymlinks).NewReadWriteBucket(
+		flags.Output,
+		storageos.ReadWriteBucketWithSymlinksIfSupported(),
+	)
+	if err != nil {
+		return err
+	}
+	return bufdoc.Generate(ctx, readWriteBucket, format, files)
+}
@@ -0,0 +1,154 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufjsonschema"
+	"github.com/bufbuild/buf/private/bufpkg/bufreflect"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	errorFormatFlagName     = "error-format"
+	typeFlagName            = "type"
+	outputFlagName          = "output"
+	outputFlagShortName     = "o"
+	configFlagName          = "config"
+	disableSymlinksFlagName = "disable-symlinks"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <input>",
+		Short: "Generate a JSON Schema for a message from the specified input.",
+		Long: `The schema follows the draft 2020-12 specification and describes the JSON encoding of the message.
+
+` + bufcli.GetInputLong(`the source, module, or image that defines the message`),
+		Args: cobra.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container, flags)
+			},
+			bufcli.NewErrorInterceptor(),
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	ErrorFormat     string
+	Type            string
+	Output          string
+	Config          string
+	DisableSymlinks bool
+	// special
+	InputHashtag string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
+	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	flagSet.StringVar(
+		&f.ErrorFormat,
+		errorFormatFlagName,
+		"text",
+		fmt.Sprintf(
+			"The format for build errors printed to stderr. Must be one of %s.",
+			stringutil.SliceToString(bufanalysis.AllFormatStrings),
+		),
+	)
+	flagSet.StringVar(
+		&f.Type,
+		typeFlagName,
+		"",
+		`The full type name of the message to generate a JSON Schema for (like acme.weather.v1.Units). Required.`,
+	)
+	flagSet.StringVarP(
+		&f.Output,
+		outputFlagName,
+		outputFlagShortName,
+		"-",
+		`The file to write the JSON Schema to, or "-" for stdout.`,
+	)
+	flagSet.StringVar(
+		&f.Config,
+		configFlagName,
+		"",
+		`The file or data to use to use for configuration.`,
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+) error {
+	if flags.Type == "" {
+		return appcmd.NewInvalidArgumentErrorf("required flag %q not set", typeFlagName)
+	}
+	if err := bufreflect.ValidateTypeName(flags.Type); err != nil {
+		return appcmd.NewInvalidArgumentErrorf("--%s: %v", typeFlagName, err)
+	}
+	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
+		return err
+	}
+	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
+	if err != nil {
+		return err
+	}
+	image, err := bufcli.NewImageForSource(
+		ctx,
+		container,
+		input,
+		flags.ErrorFormat,
+		flags.DisableSymlinks,
+		flags.Config,
+		nil,
+		nil,
+		false,
+		false, // we need source code info for descriptions
+	)
+	if err != nil {
+		return err
+	}
+	data, err := bufjsonschema.Generate(image, flags.Type)
+	if err != nil {
+		return err
+	}
+	if flags.Output == "-" {
+		_, err := container.Stdout().Write(data)
+		return err
+	}
+	return os.WriteFile(flags.Output, data, 0644)
+}
@@ -0,0 +1,161 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protoc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// rawField is a field parsed from the wire format without a schema.
+type rawField struct {
+	Number   protowire.Number
+	WireType protowire.Type
+	// Value is set for varint, fixed32 and fixed64 fields.
+	Value uint64
+	// Bytes is set for length-delimited fields.
+	Bytes []byte
+	// Group is set for group fields.
+	Group []*rawField
+}
+
+// rawMessageToTxt prints the wire-format data as raw tag/value pairs.
+//
+// This matches the output of protoc --decode_raw, see
+// https://github.com/protocolbuffers/protobuf/blob/336ed1820a4f2649c9aa3953d5059b03b7a77100/src/google/protobuf/text_format.cc#L2591
+func rawMessageToTxt(data []byte) ([]byte, error) {
+	rawFields, _, err := consumeRawFields(data, 0)
+	if err != nil {
+		return nil, errors.New("failed to parse input")
+	}
+	buffer := bytes.NewBuffer(nil)
+	printRawFields(buffer, rawFields, "")
+	return buffer.Bytes(), nil
+}
+
+// consumeRawFields consumes fields until the end of data, or until the end group tag
+// for endGroupNumber if endGroupNumber is non-zero.
+//
+// Returns the number of bytes consumed, including the end group tag.
+func consumeRawFields(data []byte, endGroupNumber protowire.Number) ([]*rawField, int, error) {
+	var rawFields []*rawField
+	offset := 0
+	for offset < len(data) {
+		number, wireType, n := protowire.ConsumeTag(data[offset:])
+		if n < 0 {
+			return nil, 0, protowire.ParseError(n)
+		}
+		offset += n
+		rawField := &rawField{
+			Number:   number,
+			WireType: wireType,
+		}
+		switch wireType {
+		case protowire.VarintType:
+			rawField.Value, n = protowire.ConsumeVarint(data[offset:])
+		case protowire.Fixed32Type:
+			var value uint32
+			value, n = protowire.ConsumeFixed32(data[offset:])
+			rawField.Value = uint64(value)
+		case protowire.Fixed64Type:
+			rawField.Value, n = protowire.ConsumeFixed64(data[offset:])
+		case protowire.BytesType:
+			rawField.Bytes, n = protowire.ConsumeBytes(data[offset:])
+		case protowire.StartGroupType:
+			var err error
+			rawField.Group, n, err = consumeRawFields(data[offset:], number)
+			if err != nil {
+				return nil, 0, err
+			}
+		case protowire.EndGroupType:
+			if number != endGroupNumber {
+				return nil, 0, fmt.Errorf("unexpected end group tag for field %d", number)
+			}
+			return rawFields, offset, nil
+		default:
+			return nil, 0, fmt.Errorf("unknown wire type %d for field %d", wireType, number)
+		}
+		if n < 0 {
+			return nil, 0, protowire.ParseError(n)
+		}
+		offset += n
+		rawFields = append(rawFields, rawField)
+	}
+	if endGroupNumber != 0 {
+		return nil, 0, fmt.Errorf("unterminated group for field %d", endGroupNumber)
+	}
+	return rawFields, offset, nil
+}
+
+func printRawFields(buffer *bytes.Buffer, rawFields []*rawField, indent string) {
+	for _, rawField := range rawFields {
+		switch rawField.WireType {
+		case protowire.VarintType:
+			_, _ = fmt.Fprintf(buffer, "%s%d: %d\n", indent, rawField.Number, rawField.Value)
+		case protowire.Fixed32Type:
+			_, _ = fmt.Fprintf(buffer, "%s%d: 0x%08x\n", indent, rawField.Number, rawField.Value)
+		case protowire.Fixed64Type:
+			_, _ = fmt.Fprintf(buffer, "%s%d: 0x%016x\n", indent, rawField.Number, rawField.Value)
+		case protowire.BytesType:
+			// Like protoc, we print length-delimited fields as messages if they parse as such.
+			if len(rawField.Bytes) > 0 {
+				if nestedRawFields, _, err := consumeRawFields(rawField.Bytes, 0); err == nil {
+					printRawGroup(buffer, rawField.Number, nestedRawFields, indent)
+					continue
+				}
+			}
+			_, _ = fmt.Fprintf(buffer, "%s%d: \"%s\"\n", indent, rawField.Number, cEscape(rawField.Bytes))
+		case protowire.StartGroupType:
+			printRawGroup(buffer, rawField.Number, rawField.Group, indent)
+		}
+	}
+}
+
+func printRawGroup(buffer *bytes.Buffer, number protowire.Number, rawFields []*rawField, indent string) {
+	_, _ = fmt.Fprintf(buffer, "%s%d {\n", indent, number)
+	printRawFields(buffer, rawFields, indent+"  ")
+	_, _ = fmt.Fprintf(buffer, "%s}\n", indent)
+}
+
+// cEscape escapes the data in the same manner as CEscape within protoc.
+func cEscape(data []byte) string {
+	buffer := bytes.NewBuffer(nil)
+	for _, b := range data {
+		switch b {
+		case '\n':
+			buffer.WriteString(`\n`)
+		case '\r':
+			buffer.WriteString(`\r`)
+		case '\t':
+			buffer.WriteString(`\t`)
+		case '"':
+			buffer.WriteString(`\"`)
+		case '\'':
+			buffer.WriteString(`\'`)
+		case '\\':
+			buffer.WriteString(`\\`)
+		default:
+			if b < 0x20 || b >= 0x7f {
+				_, _ = fmt.Fprintf(buffer, "\\%03o", b)
+			} else {
+				buffer.WriteByte(b)
+			}
+		}
+	}
+	return buffer.String()
+}
@@ -22,6 +22,8 @@ import (
 var (
 	errNoInputFiles = errors.New("no input files specified")
 	errArgEmpty     = errors.New("empty argument specified")
+
+	errDecodeRawWithInputFiles = fmt.Errorf("no input files should be given when using --%s", decodeRawFlagName)
 )
 
 func newCannotSpecifyOptWithoutOutError(pluginName string) error {
@@ -60,44 +62,10 @@ func newDuplicatePluginPathError(pluginName string) error {
 	return fmt.Errorf("duplicate --%s for protoc-gen-%s", pluginPathValuesFlagName, pluginName)
 }
 
-func newEncodeNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf only handles the binary and JSON formats for now, however we can support this flag if there is sufficient demand.
-Please email us at support@buf.build if this is a need for your organization.`,
-		encodeFlagName,
-	)
-}
-
-func newDecodeNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf only handles the binary and JSON formats for now, however we can support this flag if there is sufficient demand.
-Please email us at support@buf.build if this is a need for your organization.`,
-		decodeFlagName,
-	)
-}
-
-func newDecodeRawNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf only handles the binary and JSON formats for now, however we can support this flag if there is sufficient demand.
-Please email us at support@buf.build if this is a need for your organization.`,
-		decodeRawFlagName,
-	)
+func newCannotSpecifyDescriptorSetInAndIncludeDirPathsError() error {
+	return fmt.Errorf("cannot specify --%s and --%s at the same time", descriptorSetInFlagName, includeDirPathsFlagName)
 }
 
-func newDescriptorSetInNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf will work with cross-repository imports Buf Schema Registry, which will be based on source files, not pre-built Images.
-We think this is a much safer option that leads to less errors and more consistent results.
-
-Please email us at support@buf.build if this is a need for your organization.`,
-		descriptorSetInFlagName,
-	)
+func newDuplicateDescriptorSetInFileError(path string) error {
+	return fmt.Errorf("file %q was specified with different contents in multiple --%s files", path, descriptorSetInFlagName)
 }
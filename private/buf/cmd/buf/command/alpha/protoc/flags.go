@@ -59,6 +59,10 @@ type flags struct {
 	Output                string
 	ErrorFormat           string
 	ByDir                 bool
+	Encode                string
+	Decode                string
+	DecodeRaw             bool
+	DescriptorSetIn       []string
 }
 
 type env struct {
@@ -74,11 +78,6 @@ type flagsBuilder struct {
 
 	PluginPathValues []string
 
-	Encode          string
-	Decode          string
-	DecodeRaw       bool
-	DescriptorSetIn []string
-
 	pluginFake        []string
 	pluginNameToValue map[string]*pluginValue
 }
@@ -167,30 +166,30 @@ func (f *flagsBuilder) Bind(flagSet *pflag.FlagSet) {
 		&f.Encode,
 		encodeFlagName,
 		"",
-		`Not supported by buf.`,
+		`Read a text-format message of the given type from stdin and write it in binary to stdout.
+The message type must be defined in the input files or their imports.`,
 	)
-	_ = flagSet.MarkHidden(encodeFlagName)
 	flagSet.StringVar(
 		&f.Decode,
 		decodeFlagName,
 		"",
-		`Not supported by buf.`,
+		`Read a binary message of the given type from stdin and write it in text format to stdout.
+The message type must be defined in the input files or their imports.`,
 	)
-	_ = flagSet.MarkHidden(decodeFlagName)
 	flagSet.BoolVar(
 		&f.DecodeRaw,
 		decodeRawFlagName,
 		false,
-		`Not supported by buf.`,
+		`Read an arbitrary protocol message from stdin and write the raw tag/value pairs in text format to stdout.
+No input files should be given when using this flag.`,
 	)
-	_ = flagSet.MarkHidden(decodeRawFlagName)
 	flagSet.StringSliceVar(
 		&f.DescriptorSetIn,
 		descriptorSetInFlagName,
 		nil,
-		`Not supported by buf.`,
+		`The FileDescriptorSet files to read the input files and their imports from instead of parsing .proto files.
+Multiple files can be separated in the same manner as --proto_path.`,
 	)
-	_ = flagSet.MarkHidden(descriptorSetInFlagName)
 }
 
 func (f *flagsBuilder) Normalize(flagSet *pflag.FlagSet, name string) string {
@@ -212,9 +211,6 @@ func (f *flagsBuilder) Build(args []string) (*env, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := f.checkUnsupported(); err != nil {
-		return nil, err
-	}
 	for pluginName, pluginInfo := range pluginNameToPluginInfo {
 		if pluginInfo.Out == "" && len(pluginInfo.Opt) > 0 {
 			return nil, newCannotSpecifyOptWithoutOutError(pluginName)
@@ -227,15 +223,27 @@ func (f *flagsBuilder) Build(args []string) (*env, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(f.DescriptorSetIn) > 0 && len(f.IncludeDirPaths) > 0 {
+		return nil, newCannotSpecifyDescriptorSetInAndIncludeDirPathsError()
+	}
 	if len(f.IncludeDirPaths) == 0 {
 		f.IncludeDirPaths = defaultIncludeDirPaths
 	} else {
-		f.IncludeDirPaths = splitIncludeDirPaths(f.IncludeDirPaths)
+		f.IncludeDirPaths = splitPathList(f.IncludeDirPaths)
+	}
+	if len(f.DescriptorSetIn) > 0 {
+		f.DescriptorSetIn = splitPathList(f.DescriptorSetIn)
 	}
 	if f.ErrorFormat == "" {
 		f.ErrorFormat = defaultErrorFormat
 	}
-	if len(filePaths) == 0 {
+	if f.DecodeRaw {
+		if len(filePaths) > 0 {
+			return nil, errDecodeRawWithInputFiles
+		}
+	} else if len(filePaths) == 0 && len(f.DescriptorSetIn) == 0 {
+		// If --descriptor_set_in is set and no input files are given, all files
+		// within the FileDescriptorSets are used, matching protoc.
 		return nil, errNoInputFiles
 	}
 	return &env{
@@ -476,22 +484,6 @@ func (f *flagsBuilder) getPluginNamesSortedByOutIndex(
 	return pluginNames, nil
 }
 
-func (f *flagsBuilder) checkUnsupported() error {
-	if f.Encode != "" {
-		return newEncodeNotSupportedError()
-	}
-	if f.Decode != "" {
-		return newDecodeNotSupportedError()
-	}
-	if f.DecodeRaw {
-		return newDecodeRawNotSupportedError()
-	}
-	if len(f.DescriptorSetIn) > 0 {
-		return newDescriptorSetInNotSupportedError()
-	}
-	return nil
-}
-
 type pluginValue struct {
 	OutIndexes []int
 	OptIndexes []int
@@ -512,15 +504,17 @@ func normalizeFunc(f func(*pflag.FlagSet, string) string) func(*pflag.FlagSet, s
 // This roughly supports the equivalent of Java's -classpath flag.
 // Note that for filenames such as "foo:bar" on unix, this breaks, but our goal is to match
 // this flag from protoc.
-func splitIncludeDirPaths(includeDirPaths []string) []string {
-	copyIncludeDirPaths := make([]string, 0, len(includeDirPaths))
-	for _, includeDirPath := range includeDirPaths {
+//
+// protoc uses the same separator for both --proto_path and --descriptor_set_in.
+func splitPathList(paths []string) []string {
+	copyPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
 		// protocolbuffers/protobuf has true for omit_empty
-		for _, splitIncludeDirPath := range strings.Split(includeDirPath, includeDirPathSeparator) {
-			if len(splitIncludeDirPath) > 0 {
-				copyIncludeDirPaths = append(copyIncludeDirPaths, splitIncludeDirPath)
+		for _, splitPath := range strings.Split(path, includeDirPathSeparator) {
+			if len(splitPath) > 0 {
+				copyPaths = append(copyPaths, splitPath)
 			}
 		}
 	}
-	return copyIncludeDirPaths
+	return copyPaths
 }
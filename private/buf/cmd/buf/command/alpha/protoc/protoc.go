@@ -17,6 +17,8 @@ package protoc
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
@@ -26,6 +28,8 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagebuild"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/private/bufpkg/bufreflect"
+	imagev1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/image/v1"
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
@@ -33,10 +37,13 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app/appproto/appprotoexec"
 	"github.com/bufbuild/buf/private/pkg/app/appproto/appprotoos"
 	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/proto"
 )
 
 // NewCommand returns a new Command.
@@ -94,6 +101,20 @@ func run(
 	if len(env.PluginNameToPluginInfo) > 0 && env.Output != "" {
 		return fmt.Errorf("cannot call --%s and plugins at the same time", outputFlagName)
 	}
+	if numSet(env.Encode != "", env.Decode != "", env.DecodeRaw) > 1 {
+		return fmt.Errorf("only one of --%s, --%s and --%s can be called at the same time", encodeFlagName, decodeFlagName, decodeRawFlagName)
+	}
+	if env.Encode != "" || env.Decode != "" || env.DecodeRaw {
+		if len(env.PluginNameToPluginInfo) > 0 {
+			return fmt.Errorf("cannot call --%s, --%s or --%s and plugins at the same time", encodeFlagName, decodeFlagName, decodeRawFlagName)
+		}
+		if env.Output != "" {
+			return fmt.Errorf("cannot call --%s, --%s or --%s and --%s at the same time", encodeFlagName, decodeFlagName, decodeRawFlagName, outputFlagName)
+		}
+		if env.PrintFreeFieldNumbers {
+			return fmt.Errorf("cannot call --%s, --%s or --%s and --%s at the same time", encodeFlagName, decodeFlagName, decodeRawFlagName, printFreeFieldNumbersFlagName)
+		}
+	}
 
 	if checkedEntry := container.Logger().Check(zapcore.DebugLevel, "env"); checkedEntry != nil {
 		checkedEntry.Write(
@@ -102,72 +123,35 @@ func run(
 		)
 	}
 
-	var buildOption bufmodulebuild.BuildOption
-	if len(env.FilePaths) > 0 {
-		buildOption = bufmodulebuild.WithPaths(env.FilePaths)
+	if env.DecodeRaw {
+		return decodeRaw(container)
 	}
+
 	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
 	runner := command.NewRunner()
-	module, err := bufmodulebuild.NewModuleIncludeBuilder(container.Logger(), storageosProvider).BuildForIncludes(
-		ctx,
-		env.IncludeDirPaths,
-		buildOption,
-	)
-	if err != nil {
-		return err
+	var image bufimage.Image
+	var err error
+	if len(env.DescriptorSetIn) > 0 {
+		image, err = readDescriptorSetInImage(ctx, env.DescriptorSetIn, env.FilePaths)
+	} else {
+		image, err = buildImage(ctx, container, storageosProvider, env)
 	}
-	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
 	if err != nil {
 		return err
 	}
-	moduleReader, err := bufcli.NewModuleReaderAndCreateCacheDirs(container, registryProvider)
-	if err != nil {
-		return err
-	}
-	moduleFileSet, err := bufmodulebuild.NewModuleFileSetBuilder(
-		zap.NewNop(),
-		moduleReader,
-	).Build(
-		ctx,
-		module,
-	)
-	if err != nil {
-		return err
-	}
-	var buildOptions []bufimagebuild.BuildOption
-	// we always need source code info if we are doing generation
-	if len(env.PluginNameToPluginInfo) == 0 && !env.IncludeSourceInfo {
-		buildOptions = append(buildOptions, bufimagebuild.WithExcludeSourceCodeInfo())
-	}
-	image, fileAnnotations, err := bufimagebuild.NewBuilder(container.Logger()).Build(
-		ctx,
-		moduleFileSet,
-		buildOptions...,
-	)
-	if err != nil {
-		return err
+
+	if env.Encode != "" {
+		return encode(ctx, container, image, env.Encode)
 	}
-	if len(fileAnnotations) > 0 {
-		if err := bufanalysis.PrintFileAnnotations(
-			container.Stderr(),
-			fileAnnotations,
-			env.ErrorFormat,
-		); err != nil {
-			return err
-		}
-		// we do this even though we're in protoc compatibility mode as we just need to do non-zero
-		// but this also makes us consistent with the rest of buf
-		return bufcli.ErrFileAnnotation
+	if env.Decode != "" {
+		return decode(ctx, container, image, env.Decode)
 	}
-
 	if env.PrintFreeFieldNumbers {
-		fileInfos, err := module.TargetFileInfos(ctx)
-		if err != nil {
-			return err
-		}
 		var filePaths []string
-		for _, fileInfo := range fileInfos {
-			filePaths = append(filePaths, fileInfo.Path())
+		for _, imageFile := range image.Files() {
+			if !imageFile.IsImport() {
+				filePaths = append(filePaths, imageFile.Path())
+			}
 		}
 		s, err := bufimageutil.FreeMessageRangeStrings(ctx, filePaths, image)
 		if err != nil {
@@ -249,3 +233,207 @@ func run(
 		!env.IncludeImports,
 	)
 }
+
+func buildImage(
+	ctx context.Context,
+	container appflag.Container,
+	storageosProvider storageos.Provider,
+	env *env,
+) (bufimage.Image, error) {
+	var buildOption bufmodulebuild.BuildOption
+	if len(env.FilePaths) > 0 {
+		buildOption = bufmodulebuild.WithPaths(env.FilePaths)
+	}
+	module, err := bufmodulebuild.NewModuleIncludeBuilder(container.Logger(), storageosProvider).BuildForIncludes(
+		ctx,
+		env.IncludeDirPaths,
+		buildOption,
+	)
+	if err != nil {
+		return nil, err
+	}
+	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
+	if err != nil {
+		return nil, err
+	}
+	moduleReader, err := bufcli.NewModuleReaderAndCreateCacheDirs(container, registryProvider)
+	if err != nil {
+		return nil, err
+	}
+	moduleFileSet, err := bufmodulebuild.NewModuleFileSetBuilder(
+		zap.NewNop(),
+		moduleReader,
+	).Build(
+		ctx,
+		module,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var buildOptions []bufimagebuild.BuildOption
+	// we always need source code info if we are doing generation
+	if len(env.PluginNameToPluginInfo) == 0 && !env.IncludeSourceInfo {
+		buildOptions = append(buildOptions, bufimagebuild.WithExcludeSourceCodeInfo())
+	}
+	image, fileAnnotations, err := bufimagebuild.NewBuilder(container.Logger()).Build(
+		ctx,
+		moduleFileSet,
+		buildOptions...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(fileAnnotations) > 0 {
+		if err := bufanalysis.PrintFileAnnotations(
+			container.Stderr(),
+			fileAnnotations,
+			env.ErrorFormat,
+		); err != nil {
+			return nil, err
+		}
+		// we do this even though we're in protoc compatibility mode as we just need to do non-zero
+		// but this also makes us consistent with the rest of buf
+		return nil, bufcli.ErrFileAnnotation
+	}
+	return image, nil
+}
+
+// readDescriptorSetInImage reads the FileDescriptorSets at the given paths and
+// returns an Image with the given filePaths as non-imports.
+//
+// If filePaths is empty, all files within the FileDescriptorSets are non-imports.
+func readDescriptorSetInImage(
+	ctx context.Context,
+	descriptorSetInPaths []string,
+	filePaths []string,
+) (bufimage.Image, error) {
+	_, span := trace.StartSpan(ctx, "read_descriptor_set_in")
+	defer span.End()
+	var protoImageFiles []*imagev1.ImageFile
+	pathToProtoImageFile := make(map[string]*imagev1.ImageFile)
+	for _, descriptorSetInPath := range descriptorSetInPaths {
+		data, err := os.ReadFile(descriptorSetInPath)
+		if err != nil {
+			return nil, err
+		}
+		// A FileDescriptorSet is wire-compatible with an Image.
+		protoImage := &imagev1.Image{}
+		if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(data, protoImage); err != nil {
+			return nil, fmt.Errorf("could not unmarshal --%s file %q: %v", descriptorSetInFlagName, descriptorSetInPath, err)
+		}
+		for _, protoImageFile := range protoImage.File {
+			// protoc allows the same file to be specified more than once as long as it is identical.
+			if existingProtoImageFile, ok := pathToProtoImageFile[protoImageFile.GetName()]; ok {
+				if !proto.Equal(existingProtoImageFile, protoImageFile) {
+					return nil, newDuplicateDescriptorSetInFileError(protoImageFile.GetName())
+				}
+				continue
+			}
+			pathToProtoImageFile[protoImageFile.GetName()] = protoImageFile
+			protoImageFiles = append(protoImageFiles, protoImageFile)
+		}
+	}
+	image, err := bufimage.NewImageForProto(
+		&imagev1.Image{
+			File: protoImageFiles,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(filePaths) == 0 {
+		for _, protoImageFile := range protoImageFiles {
+			filePaths = append(filePaths, protoImageFile.GetName())
+		}
+	} else {
+		normalizedFilePaths := make([]string, len(filePaths))
+		for i, filePath := range filePaths {
+			normalizedFilePaths[i] = normalpath.Normalize(filePath)
+		}
+		filePaths = normalizedFilePaths
+	}
+	// this also re-orders the ImageFiles in DAG order, which we need as the
+	// FileDescriptorSets given to us are not guaranteed to be ordered
+	return bufimage.ImageWithOnlyPaths(image, filePaths, nil)
+}
+
+func encode(
+	ctx context.Context,
+	container app.StdioContainer,
+	image bufimage.Image,
+	typeName string,
+) error {
+	message, err := bufreflect.NewMessage(ctx, image, typeName)
+	if err != nil {
+		return err
+	}
+	resolver, err := protoencoding.NewResolver(bufimage.ImageToFileDescriptors(image)...)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(container.Stdin())
+	if err != nil {
+		return err
+	}
+	if err := protoencoding.NewTxtUnmarshaler(resolver).Unmarshal(data, message); err != nil {
+		return fmt.Errorf("failed to parse input: %v", err)
+	}
+	data, err = protoencoding.NewWireMarshaler().Marshal(message)
+	if err != nil {
+		return fmt.Errorf("output: %v", err)
+	}
+	_, err = container.Stdout().Write(data)
+	return err
+}
+
+func decode(
+	ctx context.Context,
+	container app.StdioContainer,
+	image bufimage.Image,
+	typeName string,
+) error {
+	message, err := bufreflect.NewMessage(ctx, image, typeName)
+	if err != nil {
+		return err
+	}
+	resolver, err := protoencoding.NewResolver(bufimage.ImageToFileDescriptors(image)...)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(container.Stdin())
+	if err != nil {
+		return err
+	}
+	if err := protoencoding.NewWireUnmarshaler(resolver).Unmarshal(data, message); err != nil {
+		return fmt.Errorf("failed to parse input: %v", err)
+	}
+	data, err = protoencoding.NewTxtMarshaler(resolver).Marshal(message)
+	if err != nil {
+		return fmt.Errorf("output: %v", err)
+	}
+	_, err = container.Stdout().Write(data)
+	return err
+}
+
+func decodeRaw(container app.StdioContainer) error {
+	data, err := io.ReadAll(container.Stdin())
+	if err != nil {
+		return err
+	}
+	data, err = rawMessageToTxt(data)
+	if err != nil {
+		return err
+	}
+	_, err = container.Stdout().Write(data)
+	return err
+}
+
+func numSet(values ...bool) int {
+	var count int
+	for _, value := range values {
+		if value {
+			count++
+		}
+	}
+	return count
+}
@@ -21,6 +21,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/bufbuild/buf/private/bufpkg/buftesting"
@@ -84,6 +85,119 @@ func TestOverlap(t *testing.T) {
 	)
 }
 
+func TestEncodeDecode(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "encoding")
+	filePath := filepath.Join(dirPath, "test.proto")
+	stdout := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandSuccess(
+		t,
+		testNewCommand,
+		nil,
+		strings.NewReader(`name: "foo" count: 5 nested { id: 7 }`),
+		stdout,
+		"-I",
+		dirPath,
+		"--encode=test.Test",
+		filePath,
+	)
+	require.Equal(t, testEncodingData, stdout.Bytes())
+	decoded := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandSuccess(
+		t,
+		testNewCommand,
+		nil,
+		bytes.NewReader(testEncodingData),
+		decoded,
+		"-I",
+		dirPath,
+		"--decode=test.Test",
+		filePath,
+	)
+	// the text format is not stable, so we round trip back to binary to compare
+	stdout = bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandSuccess(
+		t,
+		testNewCommand,
+		nil,
+		decoded,
+		stdout,
+		"-I",
+		dirPath,
+		"--encode=test.Test",
+		filePath,
+	)
+	require.Equal(t, testEncodingData, stdout.Bytes())
+}
+
+func TestDecodeDescriptorSetIn(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "encoding")
+	descriptorSetFilePath := filepath.Join(t.TempDir(), "image.bin")
+	appcmdtesting.RunCommandSuccess(
+		t,
+		testNewCommand,
+		nil,
+		nil,
+		nil,
+		"-I",
+		dirPath,
+		"-o",
+		descriptorSetFilePath,
+		filepath.Join(dirPath, "test.proto"),
+	)
+	decoded := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandSuccess(
+		t,
+		testNewCommand,
+		nil,
+		bytes.NewReader(testEncodingData),
+		decoded,
+		"--descriptor_set_in",
+		descriptorSetFilePath,
+		"--decode=test.Test",
+		"test.proto",
+	)
+	stdout := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandSuccess(
+		t,
+		testNewCommand,
+		nil,
+		decoded,
+		stdout,
+		"--descriptor_set_in",
+		descriptorSetFilePath,
+		"--encode=test.Test",
+	)
+	require.Equal(t, testEncodingData, stdout.Bytes())
+}
+
+func TestDecodeRaw(t *testing.T) {
+	t.Parallel()
+	appcmdtesting.RunCommandSuccessStdout(
+		t,
+		testNewCommand,
+		`1: "foo"
+2: 5
+3 {
+  1: 0x00000007
+}`,
+		nil,
+		bytes.NewReader(testEncodingData),
+		"--decode_raw",
+	)
+	appcmdtesting.RunCommandExitCodeStderr(
+		t,
+		testNewCommand,
+		1,
+		errDecodeRawWithInputFiles.Error(),
+		nil,
+		bytes.NewReader(testEncodingData),
+		"--decode_raw",
+		filepath.Join("testdata", "encoding", "test.proto"),
+	)
+}
+
 func TestComparePrintFreeFieldNumbersGoogleapis(t *testing.T) {
 	t.Parallel()
 	googleapisDirPath := buftesting.GetGoogleapisDirPath(t, buftestingDirPath)
@@ -497,3 +611,13 @@ func testGetBufProtocFileDescriptorSetBytes(t *testing.T, dirPath string) []byte
 	)
 	return stdout.Bytes()
 }
+
+// testEncodingData is test.Test{name: "foo", count: 5, nested: {id: 7}} from testdata/encoding.
+var testEncodingData = []byte{0x0a, 0x03, 'f', 'o', 'o', 0x10, 0x05, 0x1a, 0x05, 0x0d, 0x07, 0x00, 0x00, 0x00}
+
+func testNewCommand(name string) *appcmd.Command {
+	return NewCommand(
+		name,
+		appflag.NewBuilder(name),
+	)
+}
@@ -17,14 +17,12 @@ package decode
 import (
 	"context"
 	"fmt"
-	"io"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
-	"github.com/bufbuild/buf/private/bufpkg/bufreflect"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
-	"github.com/bufbuild/buf/private/pkg/protoencoding"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -91,9 +89,11 @@ Alternatively, this can be a fully qualified path to the type without providing
 		outputFlagName,
 		outputFlagShortName,
 		"-",
-		// TODO: If we ever support other formats (e.g. prototext), we will need
-		// to build a buffetch.ProtoEncodingRefParser.
-		`The location to write the decoded result to. Output is currently only in JSON form.`,
+		fmt.Sprintf(
+			`The location to write the decoded result to. Must be one of format %s.
+The format is derived from the file extension, or can be set with "#format=". Defaults to json.`,
+			buffetch.ProtoEncodingFormatsString,
+		),
 	)
 }
 
@@ -105,18 +105,23 @@ func run(
 	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
 		return err
 	}
-	messageBytes, err := io.ReadAll(container.Stdin())
+	input, err := bufcli.GetInputValue(container, "", "")
 	if err != nil {
 		return err
 	}
-	if len(messageBytes) == 0 {
-		return fmt.Errorf("stdin is required as the input")
-	}
-	input, err := bufcli.GetInputValue(container, "", "")
+	protoSource, protoType, err := bufcli.ParseSourceAndType(ctx, input, flags.Type)
 	if err != nil {
 		return err
 	}
-	protoSource, protoType, err := bufcli.ParseSourceAndType(ctx, input, flags.Type)
+	outputMessageRef, err := buffetch.NewProtoEncodingRefParser(
+		container.Logger(),
+		buffetch.ProtoEncodingRefParserWithDefaultMessageEncoding(buffetch.MessageEncodingJSON),
+	).GetProtoEncodingRef(ctx, flags.Output)
+	if err != nil {
+		return fmt.Errorf("--%s: %v", outputFlagName, err)
+	}
+	// The input is always the binary message on stdin.
+	inputMessageRef, err := buffetch.NewProtoEncodingRefParser(container.Logger()).GetProtoEncodingRef(ctx, "-")
 	if err != nil {
 		return err
 	}
@@ -135,13 +140,19 @@ func run(
 	if err != nil {
 		return err
 	}
-	message, err := bufreflect.NewMessage(ctx, image, protoType)
+	message, err := bufcli.NewWireProtoEncodingReader(
+		container.Logger(),
+		bufcli.NewStorageosProvider(false),
+	).GetMessage(
+		ctx,
+		container,
+		image,
+		protoType,
+		inputMessageRef,
+	)
 	if err != nil {
 		return err
 	}
-	if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(messageBytes, message); err != nil {
-		return err
-	}
 	return bufcli.NewWireProtoEncodingWriter(
 		container.Logger(),
 	).PutMessage(
@@ -149,6 +160,6 @@ func run(
 		container,
 		image,
 		message,
-		flags.Output,
+		outputMessageRef,
 	)
 }
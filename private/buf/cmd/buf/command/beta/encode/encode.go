@@ -0,0 +1,178 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	errorFormatFlagName = "error-format"
+	typeFlagName        = "type"
+	inputFlagName       = "input"
+	inputFlagShortName  = "i"
+	outputFlagName      = "output"
+	outputFlagShortName = "o"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <source>",
+		Short: "Use a source reference to encode a JSON or text message supplied through stdin into its binary serialized form.",
+		Long: `The first argument is the source that defines the message (like buf.build/acme/weather).
+Alternatively, you can omit the source and specify a fully qualified path for the type using the --type option (like buf.build/acme/weather#acme.weather.v1.Units).`,
+		Args: cobra.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container, flags)
+			},
+			bufcli.NewErrorInterceptor(),
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	ErrorFormat string
+	Type        string
+	Input       string
+	Output      string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	flagSet.StringVar(
+		&f.ErrorFormat,
+		errorFormatFlagName,
+		"text",
+		fmt.Sprintf(
+			"The format for build errors printed to stderr. Must be one of %s.",
+			stringutil.SliceToString(bufanalysis.AllFormatStrings),
+		),
+	)
+	flagSet.StringVar(
+		&f.Type,
+		typeFlagName,
+		"",
+		`The full type name of the message (like acme.weather.v1.Units).
+Alternatively, this can be a fully qualified path to the type without providing the source (like buf.build/acme/weather#acme.weather.v1.Units).`,
+	)
+	flagSet.StringVarP(
+		&f.Input,
+		inputFlagName,
+		inputFlagShortName,
+		"-",
+		fmt.Sprintf(
+			`The location to read the message to encode from. Must be one of format %s.
+The format is derived from the file extension, or can be set with "#format=". Defaults to json.`,
+			buffetch.ProtoEncodingFormatsString,
+		),
+	)
+	flagSet.StringVarP(
+		&f.Output,
+		outputFlagName,
+		outputFlagShortName,
+		"-",
+		fmt.Sprintf(
+			`The location to write the encoded result to. Must be one of format %s.
+The format is derived from the file extension, or can be set with "#format=". Defaults to bin.`,
+			buffetch.ProtoEncodingFormatsString,
+		),
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+) error {
+	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
+		return err
+	}
+	input, err := bufcli.GetInputValue(container, "", "")
+	if err != nil {
+		return err
+	}
+	protoSource, protoType, err := bufcli.ParseSourceAndType(ctx, input, flags.Type)
+	if err != nil {
+		return err
+	}
+	inputMessageRef, err := buffetch.NewProtoEncodingRefParser(
+		container.Logger(),
+		buffetch.ProtoEncodingRefParserWithDefaultMessageEncoding(buffetch.MessageEncodingJSON),
+	).GetProtoEncodingRef(ctx, flags.Input)
+	if err != nil {
+		return fmt.Errorf("--%s: %v", inputFlagName, err)
+	}
+	outputMessageRef, err := buffetch.NewProtoEncodingRefParser(container.Logger()).GetProtoEncodingRef(ctx, flags.Output)
+	if err != nil {
+		return fmt.Errorf("--%s: %v", outputFlagName, err)
+	}
+	image, err := bufcli.NewImageForSource(
+		ctx,
+		container,
+		protoSource,
+		flags.ErrorFormat,
+		false,
+		"",
+		nil,
+		nil,
+		false,
+		false,
+	)
+	if err != nil {
+		return err
+	}
+	message, err := bufcli.NewWireProtoEncodingReader(
+		container.Logger(),
+		bufcli.NewStorageosProvider(false),
+	).GetMessage(
+		ctx,
+		container,
+		image,
+		protoType,
+		inputMessageRef,
+	)
+	if err != nil {
+		return err
+	}
+	return bufcli.NewWireProtoEncodingWriter(
+		container.Logger(),
+	).PutMessage(
+		ctx,
+		container,
+		image,
+		message,
+		outputMessageRef,
+	)
+}
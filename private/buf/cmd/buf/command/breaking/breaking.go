@@ -23,11 +23,15 @@ import (
 	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/buf/bufwire"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/bufpkg/bufsemver"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
 	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/protosource"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -43,6 +47,8 @@ const (
 	againstConfigFlagName     = "against-config"
 	excludePathsFlagName      = "exclude-path"
 	disableSymlinksFlagName   = "disable-symlinks"
+	levelFlagName             = "level"
+	suggestVersionFlagName    = "suggest-version"
 )
 
 // NewCommand returns a new Command.
@@ -76,6 +82,8 @@ type flags struct {
 	AgainstConfig     string
 	ExcludePaths      []string
 	DisableSymlinks   bool
+	Level             string
+	SuggestVersion    bool
 	// special
 	InputHashtag string
 }
@@ -136,6 +144,29 @@ Overrides --%s.`,
 		"",
 		`The file or data to use to configure the against source, module, or image.`,
 	)
+	flagSet.StringVar(
+		&f.Level,
+		levelFlagName,
+		"",
+		fmt.Sprintf(
+			`The compatibility level to check for. Must be one of %s.
+Each failure reports whether it breaks source, wire, and JSON compatibility, and
+when set, only the failures of the configured rules that break the given level are printed.`,
+			stringutil.SliceToString(bufbreaking.AllLevelStrings),
+		),
+	)
+	flagSet.BoolVar(
+		&f.SuggestVersion,
+		suggestVersionFlagName,
+		false,
+		fmt.Sprintf(
+			`Print the suggested semantic version bump instead of failing on breaking changes.
+The bump is major if there are breaking changes, minor if messages, enums, services, fields,
+enum values, or RPCs were added or removed without breaking changes, and patch otherwise.
+Each breaking change, addition, and removal is printed as the reason for the bump. Printed as JSON if --%s is json.`,
+			errorFormatFlagName,
+		),
+	)
 }
 
 func run(
@@ -153,6 +184,14 @@ func run(
 	if err != nil {
 		return err
 	}
+	var handlerOptions []bufbreaking.HandlerOption
+	if flags.Level != "" {
+		level, err := bufbreaking.ParseLevel(flags.Level)
+		if err != nil {
+			return appcmd.NewInvalidArgumentErrorf("--%s: %v", levelFlagName, err)
+		}
+		handlerOptions = append(handlerOptions, bufbreaking.HandlerWithLevel(level))
+	}
 	ref, err := buffetch.NewRefParser(container.Logger(), buffetch.RefParserWithProtoFileRefAllowed()).GetRef(ctx, input)
 	if err != nil {
 		return err
@@ -241,7 +280,15 @@ func run(
 		return fmt.Errorf("input contained %d images, whereas against contained %d images", len(imageConfigs), len(againstImageConfigs))
 	}
 	var allFileAnnotations []bufanalysis.FileAnnotation
+	var allAdditionFileAnnotations []bufanalysis.FileAnnotation
+	var allRemovalFileAnnotations []bufanalysis.FileAnnotation
+	var allRules []bufcheck.Rule
 	for i, imageConfig := range imageConfigs {
+		rules, err := bufbreaking.RulesForConfig(imageConfig.Config().Breaking)
+		if err != nil {
+			return err
+		}
+		allRules = append(allRules, rules...)
 		fileAnnotations, err := breakingForImage(
 			ctx,
 			container,
@@ -249,17 +296,38 @@ func run(
 			againstImageConfigs[i],
 			flags.ExcludeImports,
 			flags.ErrorFormat,
+			handlerOptions,
 		)
 		if err != nil {
 			return err
 		}
 		allFileAnnotations = append(allFileAnnotations, fileAnnotations...)
+		if flags.SuggestVersion {
+			additionFileAnnotations, removalFileAnnotations, err := additionsAndRemovalsForImage(
+				ctx,
+				imageConfig,
+				againstImageConfigs[i],
+			)
+			if err != nil {
+				return err
+			}
+			allAdditionFileAnnotations = append(allAdditionFileAnnotations, additionFileAnnotations...)
+			allRemovalFileAnnotations = append(allRemovalFileAnnotations, removalFileAnnotations...)
+		}
+	}
+	if flags.SuggestVersion {
+		return bufsemver.PrintSuggestion(
+			container.Stdout(),
+			bufsemver.NewSuggestion(allFileAnnotations, allAdditionFileAnnotations, allRemovalFileAnnotations),
+			flags.ErrorFormat == bufanalysis.FormatJSON.String(),
+		)
 	}
 	if len(allFileAnnotations) > 0 {
 		if err := bufanalysis.PrintFileAnnotations(
 			container.Stdout(),
 			bufanalysis.DeduplicateAndSortFileAnnotations(allFileAnnotations),
 			flags.ErrorFormat,
+			bufanalysis.PrintFileAnnotationsWithRules(bufcheck.AnalysisRulesForRules(allRules)...),
 		); err != nil {
 			return err
 		}
@@ -275,6 +343,7 @@ func breakingForImage(
 	againstImageConfig bufwire.ImageConfig,
 	excludeImports bool,
 	errorFormat string,
+	handlerOptions []bufbreaking.HandlerOption,
 ) ([]bufanalysis.FileAnnotation, error) {
 	image := imageConfig.Image()
 	if excludeImports {
@@ -284,7 +353,7 @@ func breakingForImage(
 	if excludeImports {
 		againstImage = bufimage.ImageWithoutImports(againstImage)
 	}
-	return bufbreaking.NewHandler(container.Logger()).Check(
+	return bufbreaking.NewHandler(container.Logger(), handlerOptions...).Check(
 		ctx,
 		imageConfig.Config().Breaking,
 		againstImage,
@@ -292,6 +361,29 @@ func breakingForImage(
 	)
 }
 
+func additionsAndRemovalsForImage(
+	ctx context.Context,
+	imageConfig bufwire.ImageConfig,
+	againstImageConfig bufwire.ImageConfig,
+) ([]bufanalysis.FileAnnotation, []bufanalysis.FileAnnotation, error) {
+	// changes to imports do not change the version of the input
+	files, err := protosource.NewFilesUnstable(
+		ctx,
+		bufimageutil.NewInputFiles(bufimage.ImageWithoutImports(imageConfig.Image()).Files())...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	againstFiles, err := protosource.NewFilesUnstable(
+		ctx,
+		bufimageutil.NewInputFiles(bufimage.ImageWithoutImports(againstImageConfig.Image()).Files())...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bufsemver.GetAdditionsAndRemovals(againstFiles, files)
+}
+
 func getExternalPathsForImages(imageConfigs []bufwire.ImageConfig, excludeImports bool) ([]string, error) {
 	externalPaths := make(map[string]struct{})
 	for _, imageConfig := range imageConfigs {
@@ -0,0 +1,264 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/buf/bufformat"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/diff"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.uber.org/multierr"
+)
+
+const (
+	writeFlagName           = "write"
+	writeFlagShortName      = "w"
+	diffFlagName            = "diff"
+	diffFlagShortName       = "d"
+	pathsFlagName           = "path"
+	excludePathsFlagName    = "exclude-path"
+	configFlagName          = "config"
+	disableSymlinksFlagName = "disable-symlinks"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <source>",
+		Short: "Format all Protobuf files from the specified source.",
+		Long: `By default, the formatted files are printed to stdout.
+Use --write to rewrite the files in place, and --diff to print a diff of the changes instead.
+With --diff, buf exits with a non-zero exit code if any file is not formatted, which is useful in CI.
+
+All comments are preserved, and imports are sorted.
+
+` + bufcli.GetSourceLong(`the source to format`),
+		Args: cobra.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container, flags)
+			},
+			bufcli.NewErrorInterceptor(),
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	Write           bool
+	Diff            bool
+	Paths           []string
+	ExcludePaths    []string
+	Config          string
+	DisableSymlinks bool
+	// special
+	InputHashtag string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
+	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
+	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
+	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	flagSet.BoolVarP(
+		&f.Write,
+		writeFlagName,
+		writeFlagShortName,
+		false,
+		"Rewrite the files that are not formatted in place instead of printing the formatted files. The source must be a local directory or .proto file.",
+	)
+	flagSet.BoolVarP(
+		&f.Diff,
+		diffFlagName,
+		diffFlagShortName,
+		false,
+		"Print a diff for the files that are not formatted instead of printing the formatted files, and exit with a non-zero exit code if any file is not formatted.",
+	)
+	flagSet.StringVar(
+		&f.Config,
+		configFlagName,
+		"",
+		`The file or data to use for configuration.`,
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+) error {
+	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
+	if err != nil {
+		return err
+	}
+	sourceRef, err := buffetch.NewRefParser(container.Logger(), buffetch.RefParserWithProtoFileRefAllowed()).GetSourceRef(ctx, input)
+	if err != nil {
+		return err
+	}
+	if flags.Write && !buffetch.IsLocalRef(sourceRef) {
+		return appcmd.NewInvalidArgumentErrorf("--%s can only be used with a local directory or .proto file", writeFlagName)
+	}
+	protoFileRef, isProtoFileRef := sourceRef.(buffetch.ProtoFileRef)
+	if isProtoFileRef && protoFileRef.IncludePackageFiles() {
+		return appcmd.NewInvalidArgumentError("include_package_files is not supported when formatting")
+	}
+	storageosProvider := bufcli.NewStorageosProvider(flags.DisableSymlinks)
+	runner := command.NewRunner()
+	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
+	if err != nil {
+		return err
+	}
+	moduleReader, err := bufcli.NewModuleReaderAndCreateCacheDirs(container, registryProvider)
+	if err != nil {
+		return err
+	}
+	moduleConfigReader, err := bufcli.NewWireModuleConfigReaderForModuleReader(
+		container,
+		storageosProvider,
+		runner,
+		registryProvider,
+		moduleReader,
+	)
+	if err != nil {
+		return err
+	}
+	moduleConfigs, err := moduleConfigReader.GetModuleConfigs(
+		ctx,
+		container,
+		sourceRef,
+		flags.Config,
+		flags.Paths,
+		flags.ExcludePaths,
+		false,
+	)
+	if err != nil {
+		return err
+	}
+	var numFiles int
+	var numUnformattedFiles int
+	for _, moduleConfig := range moduleConfigs {
+		module := moduleConfig.Module()
+		targetFileInfos, err := module.TargetFileInfos(ctx)
+		if err != nil {
+			return err
+		}
+		for _, targetFileInfo := range targetFileInfos {
+			if isProtoFileRef {
+				// The module for a proto file reference contains all the files
+				// in the enclosing module, so only format the referenced file.
+				if _, err := protoFileRef.PathForExternalPath(targetFileInfo.ExternalPath()); err != nil {
+					continue
+				}
+			}
+			numFiles++
+			formatted, err := formatFile(ctx, container, runner, module, targetFileInfo.Path(), targetFileInfo.ExternalPath(), flags)
+			if err != nil {
+				return err
+			}
+			if !formatted {
+				numUnformattedFiles++
+			}
+		}
+	}
+	if numFiles == 0 {
+		return errors.New("no .proto target files found")
+	}
+	if flags.Diff && numUnformattedFiles > 0 {
+		return bufcli.ErrFileAnnotation
+	}
+	return nil
+}
+
+// formatFile formats the file and returns true if the file was already formatted.
+func formatFile(
+	ctx context.Context,
+	container appflag.Container,
+	runner command.Runner,
+	module bufmodule.Module,
+	path string,
+	externalPath string,
+	flags *flags,
+) (_ bool, retErr error) {
+	moduleFile, err := module.GetModuleFile(ctx, path)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, moduleFile.Close())
+	}()
+	var buffer bytes.Buffer
+	if _, err := buffer.ReadFrom(moduleFile); err != nil {
+		return false, err
+	}
+	data := buffer.Bytes()
+	formattedData, err := bufformat.Format(externalPath, data)
+	if err != nil {
+		return false, err
+	}
+	formatted := bytes.Equal(data, formattedData)
+	if !flags.Write && !flags.Diff {
+		_, err := container.Stdout().Write(formattedData)
+		return formatted, err
+	}
+	if formatted {
+		return true, nil
+	}
+	if flags.Diff {
+		diffData, err := diff.Diff(
+			ctx,
+			runner,
+			data,
+			formattedData,
+			externalPath,
+			externalPath,
+			diff.DiffWithSuppressTimestamps(),
+		)
+		if err != nil {
+			return false, err
+		}
+		if _, err := container.Stdout().Write(diffData); err != nil {
+			return false, err
+		}
+	}
+	if flags.Write {
+		fileInfo, err := os.Stat(externalPath)
+		if err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(externalPath, formattedData, fileInfo.Mode().Perm()); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
@@ -16,13 +16,16 @@ package generate
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/buf/bufgen"
+	"github.com/bufbuild/buf/private/buf/bufwire"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
 	"github.com/bufbuild/buf/private/pkg/command"
@@ -43,6 +46,10 @@ const (
 	includeWKTFlagName          = "include-wkt"
 	excludePathsFlagName        = "exclude-path"
 	disableSymlinksFlagName     = "disable-symlinks"
+	noCacheFlagName             = "no-cache"
+	checkFlagName               = "check"
+	diffFlagName                = "diff"
+	printManagedFlagName        = "print-managed"
 )
 
 // NewCommand returns a new Command.
@@ -68,6 +75,8 @@ plugins:
     # remote: buf.build/protocolbuffers/plugins/go:v1.27.0-1
   - name: go
     # The the relative output directory.
+    # If this ends in .zip, .jar, .tar, or .tar.gz, the generated files are written to
+    # an archive at this path instead. A .jar also includes a META-INF/MANIFEST.MF.
     # Required.
     out: gen/go
     # Any options to provide to the plugin.
@@ -103,12 +112,70 @@ plugins:
     # If omitted, "directory" is used. Most users should not need to set this option.
     # Optional.
     strategy: directory
+    # Whether to delete the files generated to the out directory by the previous run
+    # that are no longer generated, for example because a .proto file was deleted.
+    # The generated files are recorded in a .buf-gen-manifest file in the out directory,
+    # and files that are not recorded there are never deleted. Files are not deleted when
+    # only some of the input is generated, for example with --path or a .proto file input.
+    # Cannot be set for archive outs.
+    # Optional.
+    clean: false
+    # Only generate the files within these paths with this plugin. The paths are
+    # relative to the root of the module, and files outside of them are still
+    # available to the plugin as imports.
+    # Optional.
+    paths:
+      - acme/weather/v1
+    # Only generate these fully-qualified types, and the types they depend on, with this
+    # plugin. Note that comments are not available to the plugin when types are set.
+    # Optional.
+    types:
+      - acme.weather.v1.WeatherService
+    # The maximum duration the plugin can run for, after which it is stopped.
+    # Optional, and exclusive with "remote".
+    timeout: 1m
+    # The environment variables passed to the plugin. If set, only these environment
+    # variables are passed. If omitted, all environment variables are passed.
+    # Optional, and exclusive with "remote".
+    env:
+      - HOME
+      - PATH
+    # The maximum size in bytes of the response written by the plugin.
+    # Optional, and exclusive with "remote".
+    max_output_bytes: 104857600
+    # The version constraint that the installed plugin must satisfy, checked with the
+    # output of the plugin's --version flag before any plugins are run. This is a
+    # comma-separated list of comparisons using =, !=, >, >=, <, or <=.
+    # Optional, and exclusive with "remote".
+    version: ">= 1.28, < 2"
   - name: java
     out: gen/java
     # Use the plugin hosted at buf.build/protocolbuffers/plugins/python at version v3.17.0-1.
     # If version is omitted, uses the latest version of the plugin.
   - remote: buf.build/protocolbuffers/plugins/python:v3.17.0-1
     out: gen/python
+# The inputs to generate from. If an input is given on the command line, these are ignored.
+# If omitted, the input is read from the command line, and defaults to ".".
+# The generated files for all of the inputs are combined before being written.
+# Optional.
+inputs:
+    # The source, module, or image to generate from, in the same format as the input
+    # on the command line. Local paths are relative to your current directory.
+    # Required.
+  - ref: proto
+    # The paths within the input to generate, equivalent to --path.
+    # Optional.
+    paths:
+      - acme/weather
+    # The paths within the input to exclude, equivalent to --exclude-path.
+    # Optional.
+    exclude_paths:
+      - acme/weather/internal
+    # Only generate these fully-qualified types, and the types they depend on.
+    # Optional.
+    types:
+      - acme.weather.v1.WeatherService
+  - ref: buf.build/acme/units
 
 As an example, here's a typical "buf.gen.yaml" go and grpc, assuming
 "protoc-gen-go" and "protoc-gen-go-grpc" are on your "$PATH":
@@ -164,11 +231,26 @@ Note that all paths must be contained within the same module. For example, if yo
 module in "proto", you cannot specify "--path proto", however "--path proto/foo" is allowed
 as "proto/foo" is contained within "proto".
 
+If the inputs are read from the template, --path and --exclude-path cannot be set. Set the
+paths for each input in the template instead.
+
 Plugins are invoked in the order they are specified in the template, but each plugin
 has a per-directory parallel invocation, with results from each invocation combined
 before writing the result.
 
 Insertion points are processed in the order the plugins are specified in the template.
+
+Plugin responses are cached in the buf cache directory, keyed by the plugin requests, the
+plugin binary or pinned remote plugin version, and the plugin options. If a plugin is invoked
+again with the same inputs, the cached response is used. Use --no-cache to always invoke plugins.
+
+Use --check to verify that the generated files on disk are up to date without writing them.
+The paths of any out of date files are printed, and buf exits with a non-zero exit code.
+Use --diff to print a unified diff of the changes that would be made instead, which also
+exits with a non-zero exit code if there are any changes.
+
+Use --print-managed to print the file options of each file after managed mode is applied,
+without running any plugins. This is useful to audit the managed mode configuration.
 `,
 		Args: cobra.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
@@ -192,6 +274,10 @@ type flags struct {
 	IncludeWKT      bool
 	ExcludePaths    []string
 	DisableSymlinks bool
+	NoCache         bool
+	Check           bool
+	Diff            bool
+	PrintManaged    bool
 	// special
 	InputHashtag string
 }
@@ -220,6 +306,36 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 			includeImportsFlagName,
 		),
 	)
+	flagSet.BoolVar(
+		&f.NoCache,
+		noCacheFlagName,
+		false,
+		"Always invoke plugins instead of reusing cached responses from previous invocations with the same inputs.",
+	)
+	flagSet.BoolVar(
+		&f.Check,
+		checkFlagName,
+		false,
+		fmt.Sprintf(
+			`Check that the generated files on disk are up to date instead of writing them.
+The paths of the files that are out of date are printed, and the exit code is non-zero if there are any.
+If --%s is also set, a diff is printed instead of the paths.`,
+			diffFlagName,
+		),
+	)
+	flagSet.BoolVar(
+		&f.Diff,
+		diffFlagName,
+		false,
+		`Print a diff between the generated files on disk and the generated files instead of writing them.
+The exit code is non-zero if there are any differences.`,
+	)
+	flagSet.BoolVar(
+		&f.PrintManaged,
+		printManagedFlagName,
+		false,
+		`Print the file options of each file after managed mode is applied instead of generating.`,
+	)
 	flagSet.StringVar(
 		&f.Template,
 		templateFlagName,
@@ -263,14 +379,13 @@ func run(
 		// in the context of including imports.
 		return appcmd.NewInvalidArgumentErrorf("Cannot set --%s without --%s", includeWKTFlagName, includeImportsFlagName)
 	}
-	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
-		return err
+	if flags.PrintManaged && (flags.Check || flags.Diff) {
+		return appcmd.NewInvalidArgumentErrorf("Cannot set --%s with --%s or --%s", printManagedFlagName, checkFlagName, diffFlagName)
 	}
-	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
-	if err != nil {
+	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
 		return err
 	}
-	ref, err := buffetch.NewRefParser(container.Logger(), buffetch.RefParserWithProtoFileRefAllowed()).GetRef(ctx, input)
+	input, err := bufcli.GetInputValue(container, flags.InputHashtag, "")
 	if err != nil {
 		return err
 	}
@@ -292,6 +407,26 @@ func run(
 	if err != nil {
 		return err
 	}
+	inputConfigs := genConfig.InputConfigs
+	if input != "" || len(inputConfigs) == 0 {
+		// An input on the command line takes precedence over the inputs in the template.
+		if input == "" {
+			input = "."
+		}
+		inputConfigs = []*bufgen.InputConfig{
+			{
+				Ref:          input,
+				Paths:        flags.Paths,
+				ExcludePaths: flags.ExcludePaths,
+			},
+		}
+	} else if len(flags.Paths) > 0 || len(flags.ExcludePaths) > 0 {
+		return appcmd.NewInvalidArgumentErrorf(
+			"Cannot set --%s or --%s when the inputs are read from the template. Set paths for the inputs in the template instead.",
+			pathsFlagName,
+			excludePathsFlagName,
+		)
+	}
 	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
 	if err != nil {
 		return err
@@ -305,33 +440,30 @@ func run(
 	if err != nil {
 		return err
 	}
-	imageConfigs, fileAnnotations, err := imageConfigReader.GetImageConfigs(
-		ctx,
-		container,
-		ref,
-		flags.Config,
-		flags.Paths,        // we filter on files
-		flags.ExcludePaths, // we exclude these paths
-		false,              // input files must exist
-		false,              // we must include source info for generation
-	)
-	if err != nil {
-		return err
-	}
-	if len(fileAnnotations) > 0 {
-		if err := bufanalysis.PrintFileAnnotations(container.Stderr(), fileAnnotations, flags.ErrorFormat); err != nil {
+	images := make([]bufimage.Image, 0, len(inputConfigs))
+	var partialInput bool
+	for _, inputConfig := range inputConfigs {
+		image, inputIsPartial, err := getInputImage(
+			ctx,
+			container,
+			imageConfigReader,
+			inputConfig,
+			flags.Config,
+			flags.ErrorFormat,
+		)
+		if err != nil {
 			return err
 		}
-		return bufcli.ErrFileAnnotation
-	}
-	images := make([]bufimage.Image, 0, len(imageConfigs))
-	for _, imageConfig := range imageConfigs {
-		images = append(images, imageConfig.Image())
+		images = append(images, image)
+		partialInput = partialInput || inputIsPartial
 	}
 	image, err := bufimage.MergeImages(images...)
 	if err != nil {
 		return err
 	}
+	if flags.PrintManaged {
+		return bufgen.WriteManagedFileOptions(ctx, logger, container.Stdout(), genConfig, image)
+	}
 	generateOptions := []bufgen.GenerateOption{
 		bufgen.GenerateWithBaseOutDirPath(flags.BaseOutDirPath),
 	}
@@ -347,7 +479,34 @@ func run(
 			bufgen.GenerateWithIncludeWellKnownTypes(),
 		)
 	}
-	return bufgen.NewGenerator(
+	if !flags.NoCache {
+		cache, err := bufcli.NewGenerateCacheAndCreateCacheDir(container)
+		if err != nil {
+			return err
+		}
+		generateOptions = append(
+			generateOptions,
+			bufgen.GenerateWithCache(cache),
+		)
+	}
+	if partialInput {
+		generateOptions = append(
+			generateOptions,
+			bufgen.GenerateWithPartialInput(),
+		)
+	}
+	if flags.Diff {
+		generateOptions = append(
+			generateOptions,
+			bufgen.GenerateWithCheck(nil, container.Stdout()),
+		)
+	} else if flags.Check {
+		generateOptions = append(
+			generateOptions,
+			bufgen.GenerateWithCheck(container.Stdout(), nil),
+		)
+	}
+	if err := bufgen.NewGenerator(
 		logger,
 		storageosProvider,
 		runner,
@@ -358,5 +517,68 @@ func run(
 		genConfig,
 		image,
 		generateOptions...,
+	); err != nil {
+		if errors.Is(err, bufgen.ErrOutOfDate) {
+			return bufcli.ErrFileAnnotation
+		}
+		return err
+	}
+	return nil
+}
+
+// getInputImage gets the image for the input, filtered to the
+// paths and types of the InputConfig.
+//
+// Also returns whether the image only contains a subset of the files of the input.
+func getInputImage(
+	ctx context.Context,
+	container appflag.Container,
+	imageConfigReader bufwire.ImageConfigReader,
+	inputConfig *bufgen.InputConfig,
+	configOverride string,
+	errorFormat string,
+) (bufimage.Image, bool, error) {
+	ref, err := buffetch.NewRefParser(container.Logger(), buffetch.RefParserWithProtoFileRefAllowed()).GetRef(ctx, inputConfig.Ref)
+	if err != nil {
+		return nil, false, err
+	}
+	_, isProtoFileRef := ref.(buffetch.ProtoFileRef)
+	partial := isProtoFileRef ||
+		len(inputConfig.Paths) > 0 ||
+		len(inputConfig.ExcludePaths) > 0 ||
+		len(inputConfig.Types) > 0
+	imageConfigs, fileAnnotations, err := imageConfigReader.GetImageConfigs(
+		ctx,
+		container,
+		ref,
+		configOverride,
+		inputConfig.Paths,        // we filter on files
+		inputConfig.ExcludePaths, // we exclude these paths
+		false,                    // input files must exist
+		false,                    // we must include source info for generation
 	)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(fileAnnotations) > 0 {
+		if err := bufanalysis.PrintFileAnnotations(container.Stderr(), fileAnnotations, errorFormat); err != nil {
+			return nil, false, err
+		}
+		return nil, false, bufcli.ErrFileAnnotation
+	}
+	images := make([]bufimage.Image, 0, len(imageConfigs))
+	for _, imageConfig := range imageConfigs {
+		images = append(images, imageConfig.Image())
+	}
+	image, err := bufimage.MergeImages(images...)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(inputConfig.Types) > 0 {
+		image, err = bufimageutil.ImageFilteredByTypes(image, inputConfig.Types...)
+		if err != nil {
+			return nil, false, fmt.Errorf("input %s: %w", inputConfig.Ref, err)
+		}
+	}
+	return image, partial, nil
 }
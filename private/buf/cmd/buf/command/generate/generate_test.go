@@ -155,6 +155,42 @@ func TestProtoFileRefIncludePackageFiles(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestGenerateCleanWithPath(t *testing.T) {
+	tempDirPath := t.TempDir()
+	template := `
+version: v1
+plugins:
+  - name: java
+    out: java
+    clean: true
+`
+	testRunSuccess(
+		t,
+		filepath.Join("testdata", "paths"),
+		"--output",
+		tempDirPath,
+		"--template",
+		template,
+	)
+	// Only a/v1 is generated, so the files generated for the rest of the input are kept.
+	testRunSuccess(
+		t,
+		filepath.Join("testdata", "paths"),
+		"--output",
+		tempDirPath,
+		"--template",
+		template,
+		"--path",
+		filepath.Join("testdata", "paths", "a", "v1"),
+	)
+	_, err := os.Stat(filepath.Join(tempDirPath, "java", "a", "v1", "A.java"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(tempDirPath, "java", "a", "v2", "A.java"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(tempDirPath, "java", "b", "v1", "B.java"))
+	require.NoError(t, err)
+}
+
 func TestGenerateDuplicatePlugins(t *testing.T) {
 	tempDirPath := t.TempDir()
 	testRunSuccess(
@@ -496,6 +532,24 @@ func testRunSuccess(t *testing.T, args ...string) {
 	)
 }
 
+func testRunExitCode(t *testing.T, expectedExitCode int, stdout io.Writer, args ...string) {
+	appcmdtesting.RunCommandExitCode(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		expectedExitCode,
+		internaltesting.NewEnvFunc(t),
+		nil,
+		stdout,
+		bytes.NewBuffer(nil),
+		args...,
+	)
+}
+
 func testRunStdoutStderr(t *testing.T, stdin io.Reader, expectedExitCode int, expectedStdout string, expectedStderr string, args ...string) {
 	appcmdtesting.RunCommandExitCodeStdoutStderr(
 		t,
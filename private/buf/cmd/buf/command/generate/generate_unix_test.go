@@ -18,10 +18,13 @@
 package generate
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/stretchr/testify/require"
 )
 
@@ -190,3 +193,54 @@ func TestWorkspaceGenerateWithExcludeAndTargetPaths(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "no such file or directory")
 }
+
+func TestGenerateDiff(t *testing.T) {
+	tempDirPath := t.TempDir()
+	// the plugin ignores the request and responds with a single file a.txt containing "x\n"
+	pluginPath := filepath.Join(tempDirPath, "protoc-gen-text")
+	require.NoError(
+		t,
+		os.WriteFile(
+			pluginPath,
+			[]byte(`#!/bin/sh
+cat > /dev/null
+printf '\172\013\012\005a.txt\172\002x\012'
+`),
+			0700,
+		),
+	)
+	outDirPath := filepath.Join(tempDirPath, "gen")
+	template := fmt.Sprintf(`{"version":"v1","plugins":[{"name":"text","path":%q,"out":%q}]}`, pluginPath, outDirPath)
+	testRunSuccess(
+		t,
+		"--template",
+		template,
+		filepath.Join("testdata", "simple"),
+	)
+	testRunStdoutStderr(
+		t,
+		nil,
+		0,
+		"",
+		"",
+		"--template",
+		template,
+		"--diff",
+		filepath.Join("testdata", "simple"),
+	)
+	require.NoError(t, os.WriteFile(filepath.Join(outDirPath, "a.txt"), []byte("y\n"), 0600))
+	stdout := bytes.NewBuffer(nil)
+	testRunExitCode(
+		t,
+		bufcli.ExitCodeFileAnnotation,
+		stdout,
+		"--template",
+		template,
+		"--diff",
+		filepath.Join("testdata", "simple"),
+	)
+	require.Contains(t, stdout.String(), "-y\n+x\n")
+	data, err := os.ReadFile(filepath.Join(outDirPath, "a.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "y\n", string(data))
+}
@@ -15,14 +15,22 @@
 package lint
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/buf/bufwire"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
@@ -38,6 +46,9 @@ const (
 	pathsFlagName           = "path"
 	excludePathsFlagName    = "exclude-path"
 	disableSymlinksFlagName = "disable-symlinks"
+	baselineFlagName        = "baseline"
+	writeBaselineFlagName   = "write-baseline"
+	fixFlagName             = "fix"
 )
 
 // NewCommand returns a new Command.
@@ -67,6 +78,9 @@ type flags struct {
 	Paths           []string
 	ExcludePaths    []string
 	DisableSymlinks bool
+	Baseline        string
+	WriteBaseline   string
+	Fix             bool
 	// special
 	InputHashtag string
 }
@@ -95,6 +109,29 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 		"",
 		`The file or data to use for configuration.`,
 	)
+	flagSet.StringVar(
+		&f.Baseline,
+		baselineFlagName,
+		"",
+		`The baseline file to use to suppress existing check violations.
+Overrides the baseline set in the lint configuration.`,
+	)
+	flagSet.StringVar(
+		&f.WriteBaseline,
+		writeBaselineFlagName,
+		"",
+		`Write all current check violations to the given baseline file instead of printing them.
+The baseline file can then be set with the baseline key in the lint configuration or with --baseline,
+after which only new check violations are reported.`,
+	)
+	flagSet.BoolVar(
+		&f.Fix,
+		fixFlagName,
+		false,
+		`Fix the check violations that can be fixed mechanically, and then report the remaining check violations.
+Fixed files are rewritten in place, and the applied fixes are printed to stderr.
+The input must be a local directory.`,
+	)
 }
 
 func run(
@@ -105,6 +142,12 @@ func run(
 	if err := bufcli.ValidateErrorFormatFlagLint(flags.ErrorFormat, errorFormatFlagName); err != nil {
 		return err
 	}
+	if flags.Baseline != "" && flags.WriteBaseline != "" {
+		return appcmd.NewInvalidArgumentErrorf("cannot set both --%s and --%s", baselineFlagName, writeBaselineFlagName)
+	}
+	if flags.Fix && flags.WriteBaseline != "" {
+		return appcmd.NewInvalidArgumentErrorf("cannot set both --%s and --%s", fixFlagName, writeBaselineFlagName)
+	}
 	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
 	if err != nil {
 		return err
@@ -113,6 +156,9 @@ func run(
 	if err != nil {
 		return err
 	}
+	if flags.Fix && !buffetch.IsDirRef(ref) {
+		return appcmd.NewInvalidArgumentErrorf("--%s can only be used with a local directory input", fixFlagName)
+	}
 	storageosProvider := bufcli.NewStorageosProvider(flags.DisableSymlinks)
 	runner := command.NewRunner()
 	registryProvider, err := bufcli.NewRegistryProvider(ctx, container)
@@ -128,35 +174,124 @@ func run(
 	if err != nil {
 		return err
 	}
-	imageConfigs, fileAnnotations, err := imageConfigReader.GetImageConfigs(
-		ctx,
-		container,
-		ref,
-		flags.Config,
-		flags.Paths,        // we filter checks for files
-		flags.ExcludePaths, // we exclude these paths
-		false,              // input files must exist
-		false,              // we must include source info for linting
-	)
+	getImageConfigsForPaths := func(paths []string, excludePaths []string) ([]bufwire.ImageConfig, error) {
+		imageConfigs, fileAnnotations, err := imageConfigReader.GetImageConfigs(
+			ctx,
+			container,
+			ref,
+			flags.Config,
+			paths,        // we filter checks for files
+			excludePaths, // we exclude these paths
+			false,        // input files must exist
+			false,        // we must include source info for linting
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(fileAnnotations) > 0 {
+			formatString := flags.ErrorFormat
+			if formatString == "config-ignore-yaml" {
+				formatString = "text"
+			}
+			if err := bufanalysis.PrintFileAnnotations(container.Stdout(), fileAnnotations, formatString); err != nil {
+				return nil, err
+			}
+			return nil, bufcli.ErrFileAnnotation
+		}
+		return imageConfigs, nil
+	}
+	getImageConfigs := func() ([]bufwire.ImageConfig, error) {
+		return getImageConfigsForPaths(flags.Paths, flags.ExcludePaths)
+	}
+	imageConfigs, err := getImageConfigs()
 	if err != nil {
 		return err
 	}
-	if len(fileAnnotations) > 0 {
-		formatString := flags.ErrorFormat
-		if formatString == "config-ignore-yaml" {
-			formatString = "text"
+	handler := buflint.NewHandler(
+		container.Logger(),
+		buflint.HandlerWithPluginExecutor(
+			buflintplugin.NewExecutor(
+				container.Logger(),
+				container,
+				buflintplugin.NewBinaryHandlerProvider(
+					container.Logger(),
+					storageosProvider,
+					runner,
+				),
+			),
+		),
+	)
+	if flags.WriteBaseline != "" {
+		return writeBaseline(ctx, handler, imageConfigs, flags.WriteBaseline)
+	}
+	pathToBaselineEntries := make(map[string][]*buflintconfig.BaselineEntry)
+	getCheckOptions := func(imageConfig bufwire.ImageConfig) ([]buflint.CheckOption, error) {
+		// the flag is relative to the current working directory
+		baselinePath := flags.Baseline
+		if baselinePath == "" {
+			var err error
+			baselinePath, err = getConfigBaselinePath(ref, flags.Config, imageConfig)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if baselinePath == "" {
+			return nil, nil
+		}
+		baselineEntries, ok := pathToBaselineEntries[baselinePath]
+		if !ok {
+			var err error
+			baselineEntries, err = readBaseline(baselinePath)
+			if err != nil {
+				return nil, err
+			}
+			pathToBaselineEntries[baselinePath] = baselineEntries
 		}
-		if err := bufanalysis.PrintFileAnnotations(container.Stdout(), fileAnnotations, formatString); err != nil {
+		return []buflint.CheckOption{buflint.CheckWithBaselineEntries(baselineEntries...)}, nil
+	}
+	if flags.Fix {
+		fixImageConfigs := imageConfigs
+		if len(flags.Paths) > 0 || len(flags.ExcludePaths) > 0 {
+			// renames must also update references within the files of the modules
+			// that are not targeted, so we need images of the entire modules
+			allImageConfigs, err := getImageConfigsForPaths(nil, nil)
+			if err != nil {
+				return err
+			}
+			fixImageConfigs, err = getImageConfigsWithOnlyTargetsOf(allImageConfigs, imageConfigs)
+			if err != nil {
+				return err
+			}
+		}
+		fixed, err := fix(ctx, container, handler, fixImageConfigs, getCheckOptions)
+		if err != nil {
 			return err
 		}
-		return bufcli.ErrFileAnnotation
+		if fixed {
+			// the files changed, so we need to rebuild to report the remaining violations
+			imageConfigs, err = getImageConfigs()
+			if err != nil {
+				return err
+			}
+		}
 	}
 	var allFileAnnotations []bufanalysis.FileAnnotation
+	var allRules []bufcheck.Rule
 	for _, imageConfig := range imageConfigs {
-		fileAnnotations, err := buflint.NewHandler(container.Logger()).Check(
+		checkOptions, err := getCheckOptions(imageConfig)
+		if err != nil {
+			return err
+		}
+		rules, err := buflint.RulesForConfig(imageConfig.Config().Lint)
+		if err != nil {
+			return err
+		}
+		allRules = append(allRules, rules...)
+		fileAnnotations, err := handler.Check(
 			ctx,
 			imageConfig.Config().Lint,
-			bufimage.ImageWithoutImports(imageConfig.Image()),
+			imageConfig.Image(),
+			checkOptions...,
 		)
 		if err != nil {
 			return err
@@ -168,6 +303,7 @@ func run(
 			container.Stdout(),
 			bufanalysis.DeduplicateAndSortFileAnnotations(allFileAnnotations),
 			flags.ErrorFormat,
+			bufanalysis.PrintFileAnnotationsWithRules(bufcheck.AnalysisRulesForRules(allRules)...),
 		); err != nil {
 			return err
 		}
@@ -175,3 +311,227 @@ func run(
 	}
 	return nil
 }
+
+// fix fixes the files of the images in place and prints the applied fixes to stderr.
+//
+// Returns true if any file was changed.
+func fix(
+	ctx context.Context,
+	container appflag.Container,
+	handler buflint.Handler,
+	imageConfigs []bufwire.ImageConfig,
+	getCheckOptions func(bufwire.ImageConfig) ([]buflint.CheckOption, error),
+) (bool, error) {
+	var allFileAnnotations []bufanalysis.FileAnnotation
+	for _, imageConfig := range imageConfigs {
+		checkOptions, err := getCheckOptions(imageConfig)
+		if err != nil {
+			return false, err
+		}
+		image := imageConfig.Image()
+		pathToData, err := getModulePathToData(image)
+		if err != nil {
+			return false, err
+		}
+		fileFixes, err := handler.Fix(
+			ctx,
+			imageConfig.Config().Lint,
+			image,
+			pathToData,
+			checkOptions...,
+		)
+		if err != nil {
+			return false, err
+		}
+		for _, fileFix := range fileFixes {
+			if err := os.WriteFile(fileFix.ExternalPath, fileFix.Data, 0600); err != nil {
+				return false, err
+			}
+			allFileAnnotations = append(allFileAnnotations, fileFix.FileAnnotations...)
+		}
+	}
+	if len(allFileAnnotations) == 0 {
+		return false, nil
+	}
+	if err := bufanalysis.PrintFileAnnotations(
+		container.Stderr(),
+		bufanalysis.DeduplicateAndSortFileAnnotations(allFileAnnotations),
+		bufanalysis.FormatText.String(),
+	); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// getImageConfigsWithOnlyTargetsOf returns the ImageConfigs with all files that are not
+// targets within targetImageConfigs marked as imports.
+func getImageConfigsWithOnlyTargetsOf(
+	imageConfigs []bufwire.ImageConfig,
+	targetImageConfigs []bufwire.ImageConfig,
+) ([]bufwire.ImageConfig, error) {
+	targetPaths := make(map[string]struct{})
+	for _, targetImageConfig := range targetImageConfigs {
+		for _, imageFile := range targetImageConfig.Image().Files() {
+			if !imageFile.IsImport() {
+				targetPaths[imageFile.Path()] = struct{}{}
+			}
+		}
+	}
+	newImageConfigs := make([]bufwire.ImageConfig, 0, len(imageConfigs))
+	for _, imageConfig := range imageConfigs {
+		imageFiles := imageConfig.Image().Files()
+		newImageFiles := make([]bufimage.ImageFile, len(imageFiles))
+		hasTargets := false
+		for i, imageFile := range imageFiles {
+			_, isTarget := targetPaths[imageFile.Path()]
+			isImport := imageFile.IsImport() || !isTarget
+			hasTargets = hasTargets || !isImport
+			newImageFile, err := bufimage.NewImageFile(
+				imageFile.FileDescriptor(),
+				imageFile.ModuleIdentity(),
+				imageFile.Commit(),
+				imageFile.ExternalPath(),
+				isImport,
+				imageFile.IsSyntaxUnspecified(),
+				imageFile.UnusedDependencyIndexes(),
+			)
+			if err != nil {
+				return nil, err
+			}
+			newImageFiles[i] = newImageFile
+		}
+		if !hasTargets {
+			continue
+		}
+		newImage, err := bufimage.NewImage(newImageFiles)
+		if err != nil {
+			return nil, err
+		}
+		newImageConfigs = append(newImageConfigs, bufwire.NewImageConfig(newImage, imageConfig.Config()))
+	}
+	return newImageConfigs, nil
+}
+
+// getModulePathToData returns the content of all files of the local module the Image was
+// built from, keyed by path.
+//
+// This includes the files of the module that are imports because the paths were
+// restricted or the files are ignored, so that renames also update references within them,
+// but not the files of dependencies.
+func getModulePathToData(image bufimage.Image) (map[string][]byte, error) {
+	moduleRootPath, err := getModuleRootPath(image)
+	if err != nil {
+		return nil, err
+	}
+	pathToData := make(map[string][]byte)
+	for _, imageFile := range image.Files() {
+		externalPath := filepath.Join(moduleRootPath, filepath.FromSlash(imageFile.Path()))
+		if imageFile.IsImport() && filepath.Clean(imageFile.ExternalPath()) != externalPath {
+			// a file of a dependency
+			continue
+		}
+		data, err := os.ReadFile(externalPath)
+		if err != nil {
+			if imageFile.IsImport() && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		pathToData[imageFile.Path()] = data
+	}
+	return pathToData, nil
+}
+
+// getConfigBaselinePath returns the path of the baseline set in the lint configuration
+// of the ImageConfig, resolved relative to the directory of the configuration file.
+//
+// Returns empty if no baseline is configured.
+func getConfigBaselinePath(ref buffetch.Ref, configOverride string, imageConfig bufwire.ImageConfig) (string, error) {
+	baselinePath := imageConfig.Config().Lint.Baseline
+	if baselinePath == "" || filepath.IsAbs(baselinePath) {
+		return baselinePath, nil
+	}
+	switch filepath.Ext(configOverride) {
+	case ".json", ".yaml":
+		// the configuration was read from the file at this path
+		return filepath.Join(filepath.Dir(configOverride), baselinePath), nil
+	}
+	if _, ok := ref.(buffetch.ImageRef); ok {
+		// the configuration for images is read from the current working directory
+		return baselinePath, nil
+	}
+	if !buffetch.IsLocalRef(ref) {
+		return "", fmt.Errorf(
+			"the baseline %q in the lint configuration can only be used with a local directory or .proto file input, use --%s instead",
+			baselinePath,
+			baselineFlagName,
+		)
+	}
+	moduleRootPath, err := getModuleRootPath(imageConfig.Image())
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(moduleRootPath, baselinePath), nil
+}
+
+// getModuleRootPath returns the path of the root of the local module the Image was built from,
+// which is the directory of its configuration file.
+func getModuleRootPath(image bufimage.Image) (string, error) {
+	for _, imageFile := range image.Files() {
+		if imageFile.IsImport() {
+			continue
+		}
+		externalPath := filepath.ToSlash(filepath.Clean(imageFile.ExternalPath()))
+		if externalPath == imageFile.Path() {
+			return ".", nil
+		}
+		if moduleRootPath := strings.TrimSuffix(externalPath, "/"+imageFile.Path()); moduleRootPath != externalPath {
+			return filepath.FromSlash(moduleRootPath), nil
+		}
+		return "", fmt.Errorf("could not determine the module root of %q", imageFile.ExternalPath())
+	}
+	return "", errors.New("could not determine the module root as the image has no target files")
+}
+
+func readBaseline(baselinePath string) ([]*buflintconfig.BaselineEntry, error) {
+	data, err := os.ReadFile(baselinePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read baseline file: %w", err)
+	}
+	return buflintconfig.ReadBaseline(data)
+}
+
+func writeBaseline(
+	ctx context.Context,
+	handler buflint.Handler,
+	imageConfigs []bufwire.ImageConfig,
+	baselinePath string,
+) error {
+	var allBaselineEntries []*buflintconfig.BaselineEntry
+	seen := make(map[buflintconfig.BaselineEntry]struct{})
+	for _, imageConfig := range imageConfigs {
+		baselineEntries, err := handler.Baseline(
+			ctx,
+			imageConfig.Config().Lint,
+			imageConfig.Image(),
+		)
+		if err != nil {
+			return err
+		}
+		for _, baselineEntry := range baselineEntries {
+			if _, ok := seen[*baselineEntry]; ok {
+				continue
+			}
+			seen[*baselineEntry] = struct{}{}
+			allBaselineEntries = append(allBaselineEntries, baselineEntry)
+		}
+	}
+	// each Baseline call returns sorted entries, but we need to re-sort
+	// across all images to keep the file deterministic
+	buflintconfig.SortBaselineEntries(allBaselineEntries)
+	buffer := bytes.NewBuffer(nil)
+	if err := buflintconfig.WriteBaseline(buffer, allBaselineEntries); err != nil {
+		return err
+	}
+	return os.WriteFile(baselinePath, buffer.Bytes(), 0644)
+}
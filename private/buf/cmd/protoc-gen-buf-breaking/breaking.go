@@ -26,6 +26,7 @@ import (
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
@@ -147,8 +148,17 @@ func handle(
 		return err
 	}
 	if len(fileAnnotations) > 0 {
+		rules, err := bufbreaking.RulesForConfig(config.Breaking)
+		if err != nil {
+			return err
+		}
 		buffer := bytes.NewBuffer(nil)
-		if err := bufanalysis.PrintFileAnnotations(buffer, fileAnnotations, externalConfig.ErrorFormat); err != nil {
+		if err := bufanalysis.PrintFileAnnotations(
+			buffer,
+			fileAnnotations,
+			externalConfig.ErrorFormat,
+			bufanalysis.PrintFileAnnotationsWithRules(bufcheck.AnalysisRulesForRules(rules)...),
+		); err != nil {
 			return err
 		}
 		responseWriter.AddError(strings.TrimSpace(buffer.String()))
@@ -21,6 +21,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
@@ -113,8 +115,17 @@ func handle(
 		return err
 	}
 	if len(fileAnnotations) > 0 {
+		rules, err := buflint.RulesForConfig(config.Lint)
+		if err != nil {
+			return err
+		}
 		buffer := bytes.NewBuffer(nil)
-		if err := buflintconfig.PrintFileAnnotations(buffer, fileAnnotations, externalConfig.ErrorFormat); err != nil {
+		if err := buflintconfig.PrintFileAnnotations(
+			buffer,
+			fileAnnotations,
+			externalConfig.ErrorFormat,
+			bufanalysis.PrintFileAnnotationsWithRules(bufcheck.AnalysisRulesForRules(rules)...),
+		); err != nil {
 			return err
 		}
 		responseWriter.AddError(strings.TrimSpace(buffer.String()))
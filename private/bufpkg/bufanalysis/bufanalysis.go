@@ -31,6 +31,19 @@ const (
 	FormatJSON
 	// FormatMSVS is the MSVS format for FileAnnotations.
 	FormatMSVS
+	// FormatSARIF is the SARIF 2.1.0 format for FileAnnotations.
+	//
+	// Unlike the other formats, this prints a single SARIF log for all FileAnnotations.
+	FormatSARIF
+	// FormatJUnit is the JUnit XML format for FileAnnotations.
+	//
+	// Each file is a testsuite and each FileAnnotation is a failing testcase.
+	// Like FormatSARIF, this prints a single document for all FileAnnotations.
+	FormatJUnit
+	// FormatGitHubActions is the GitHub Actions workflow command format for FileAnnotations.
+	//
+	// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-an-error-message
+	FormatGitHubActions
 )
 
 var (
@@ -41,6 +54,9 @@ var (
 		"text",
 		"json",
 		"msvs",
+		"sarif",
+		"junit",
+		"github-actions",
 	}
 	// AllFormatStringsWithAliases is all format strings with aliases.
 	//
@@ -50,19 +66,28 @@ var (
 		"gcc",
 		"json",
 		"msvs",
+		"sarif",
+		"junit",
+		"github-actions",
 	}
 
 	stringToFormat = map[string]Format{
 		"text": FormatText,
 		// alias for text
-		"gcc":  FormatText,
-		"json": FormatJSON,
-		"msvs": FormatMSVS,
+		"gcc":            FormatText,
+		"json":           FormatJSON,
+		"msvs":           FormatMSVS,
+		"sarif":          FormatSARIF,
+		"junit":          FormatJUnit,
+		"github-actions": FormatGitHubActions,
 	}
 	formatToString = map[Format]string{
-		FormatText: "text",
-		FormatJSON: "json",
-		FormatMSVS: "msvs",
+		FormatText:          "text",
+		FormatJSON:          "json",
+		FormatMSVS:          "msvs",
+		FormatSARIF:         "sarif",
+		FormatJUnit:         "junit",
+		FormatGitHubActions: "github-actions",
 	}
 )
 
@@ -93,6 +118,18 @@ func ParseFormat(s string) (Format, error) {
 	return 0, fmt.Errorf("unknown format: %q", s)
 }
 
+// Rule is a minimal Rule interface.
+//
+// This is used to attach rule metadata to formats that support it, such as SARIF.
+type Rule interface {
+	// ID is the ID of the Rule, which matches the Type of FileAnnotations it produces.
+	ID() string
+	// Categories are the categories of the Rule.
+	Categories() []string
+	// Purpose is the purpose of the Rule.
+	Purpose() string
+}
+
 // FileInfo is a minimal FileInfo interface.
 type FileInfo interface {
 	Path() string
@@ -195,11 +232,28 @@ func DeduplicateAndSortFileAnnotations(fileAnnotations []FileAnnotation) []FileA
 }
 
 // PrintFileAnnotations prints the file annotations separated by newlines.
-func PrintFileAnnotations(writer io.Writer, fileAnnotations []FileAnnotation, formatString string) error {
+//
+// If the format is FormatSARIF or FormatJUnit, a single document is printed instead.
+func PrintFileAnnotations(
+	writer io.Writer,
+	fileAnnotations []FileAnnotation,
+	formatString string,
+	options ...PrintFileAnnotationsOption,
+) error {
 	format, err := ParseFormat(formatString)
 	if err != nil {
 		return err
 	}
+	printFileAnnotationsOptions := newPrintFileAnnotationsOptions()
+	for _, option := range options {
+		option(printFileAnnotationsOptions)
+	}
+	switch format {
+	case FormatSARIF:
+		return printFileAnnotationsSARIF(writer, fileAnnotations, printFileAnnotationsOptions.rules)
+	case FormatJUnit:
+		return printFileAnnotationsJUnit(writer, fileAnnotations)
+	}
 	for _, fileAnnotation := range fileAnnotations {
 		s, err := FormatFileAnnotation(fileAnnotation, format)
 		if err != nil {
@@ -212,7 +266,22 @@ func PrintFileAnnotations(writer io.Writer, fileAnnotations []FileAnnotation, fo
 	return nil
 }
 
+// PrintFileAnnotationsOption is an option for PrintFileAnnotations.
+type PrintFileAnnotationsOption func(*printFileAnnotationsOptions)
+
+// PrintFileAnnotationsWithRules returns a new PrintFileAnnotationsOption that
+// attaches the metadata of the given Rules to formats that support it.
+//
+// This is currently only used by FormatSARIF.
+func PrintFileAnnotationsWithRules(rules ...Rule) PrintFileAnnotationsOption {
+	return func(printFileAnnotationsOptions *printFileAnnotationsOptions) {
+		printFileAnnotationsOptions.rules = append(printFileAnnotationsOptions.rules, rules...)
+	}
+}
+
 // FormatFileAnnotation formats the FileAnnotation.
+//
+// If the format is FormatSARIF or FormatJUnit, this returns a document containing only this FileAnnotation.
 func FormatFileAnnotation(fileAnnotation FileAnnotation, format Format) (string, error) {
 	switch format {
 	case FormatText:
@@ -225,6 +294,20 @@ func FormatFileAnnotation(fileAnnotation FileAnnotation, format Format) (string,
 		return string(data), nil
 	case FormatMSVS:
 		return fileAnnotation.MSVSString(), nil
+	case FormatSARIF:
+		data, err := marshalSARIF([]FileAnnotation{fileAnnotation}, nil)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case FormatJUnit:
+		data, err := marshalJUnit([]FileAnnotation{fileAnnotation})
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case FormatGitHubActions:
+		return gitHubActionsString(fileAnnotation), nil
 	default:
 		return "", fmt.Errorf("unknown FileAnnotation Format: %v", format)
 	}
@@ -247,6 +330,14 @@ func hash(fileAnnotation FileAnnotation) string {
 	return string(hash.Sum(nil))
 }
 
+type printFileAnnotationsOptions struct {
+	rules []Rule
+}
+
+func newPrintFileAnnotationsOptions() *printFileAnnotationsOptions {
+	return &printFileAnnotationsOptions{}
+}
+
 type sortFileAnnotations []FileAnnotation
 
 func (a sortFileAnnotations) Len() int               { return len(a) }
@@ -15,6 +15,7 @@
 package bufanalysistesting
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
@@ -63,4 +64,190 @@ func TestBasic(t *testing.T) {
 	s, err = bufanalysis.FormatFileAnnotation(fileAnnotation, bufanalysis.FormatMSVS)
 	require.NoError(t, err)
 	assert.Equal(t, `path/to/file.proto(2,1) : error FOO : Hello.`, s)
+	s, err = bufanalysis.FormatFileAnnotation(fileAnnotation, bufanalysis.FormatGitHubActions)
+	require.NoError(t, err)
+	assert.Equal(t, `::error file=path/to/file.proto,line=2,endLine=2,col=1,endColumn=1,title=FOO::Hello.`, s)
+
+	fileAnnotation = newFileAnnotation(
+		t,
+		"",
+		0,
+		0,
+		0,
+		0,
+		"FOO",
+		"Hello,\nworld: 100%.",
+	)
+	s, err = bufanalysis.FormatFileAnnotation(fileAnnotation, bufanalysis.FormatGitHubActions)
+	require.NoError(t, err)
+	assert.Equal(t, `::error title=FOO::Hello,%0Aworld: 100%25.`, s)
+}
+
+func TestPrintFileAnnotationsJUnit(t *testing.T) {
+	t.Parallel()
+	fileAnnotations := []bufanalysis.FileAnnotation{
+		newFileAnnotation(
+			t,
+			"path/to/a.proto",
+			2,
+			1,
+			2,
+			5,
+			"FOO",
+			"Hello <a>.",
+		),
+		newFileAnnotation(
+			t,
+			"path/to/b.proto",
+			3,
+			0,
+			3,
+			0,
+			"BAR",
+			"Goodbye.",
+		),
+		newFileAnnotation(
+			t,
+			"path/to/a.proto",
+			4,
+			2,
+			4,
+			2,
+			"BAR",
+			"Goodbye.",
+		),
+	}
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, bufanalysis.PrintFileAnnotations(buffer, fileAnnotations, "junit"))
+	assert.Equal(
+		t,
+		`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="3">
+  <testsuite name="path/to/a.proto" tests="2" failures="2" errors="0">
+    <testcase classname="path/to/a.proto" name="path/to/a.proto:2:1:FOO">
+      <failure message="Hello &lt;a&gt;." type="FOO">path/to/a.proto:2:1:Hello &lt;a&gt;.</failure>
+    </testcase>
+    <testcase classname="path/to/a.proto" name="path/to/a.proto:4:2:BAR">
+      <failure message="Goodbye." type="BAR">path/to/a.proto:4:2:Goodbye.</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="path/to/b.proto" tests="1" failures="1" errors="0">
+    <testcase classname="path/to/b.proto" name="path/to/b.proto:3:1:BAR">
+      <failure message="Goodbye." type="BAR">path/to/b.proto:3:1:Goodbye.</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+		buffer.String(),
+	)
+}
+
+func TestPrintFileAnnotationsSARIF(t *testing.T) {
+	t.Parallel()
+	fileAnnotations := []bufanalysis.FileAnnotation{
+		newFileAnnotation(
+			t,
+			"path/to/file.proto",
+			2,
+			1,
+			2,
+			5,
+			"FOO",
+			"Hello.",
+		),
+		newFileAnnotation(
+			t,
+			"",
+			0,
+			0,
+			0,
+			0,
+			"BAR",
+			"Goodbye.",
+		),
+	}
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(
+		t,
+		bufanalysis.PrintFileAnnotations(
+			buffer,
+			fileAnnotations,
+			"sarif",
+			bufanalysis.PrintFileAnnotationsWithRules(
+				newTestRule("FOO", "Checks foo.", "BASIC"),
+				newTestRule("FOO", "Checks foo.", "BASIC"),
+			),
+		),
+	)
+	assert.JSONEq(
+		t,
+		`{
+			"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+			"version": "2.1.0",
+			"runs": [
+				{
+					"tool": {
+						"driver": {
+							"name": "buf",
+							"informationUri": "https://buf.build",
+							"rules": [
+								{
+									"id": "FOO",
+									"shortDescription": {"text": "Checks foo."},
+									"properties": {"tags": ["BASIC"]}
+								}
+							]
+						}
+					},
+					"results": [
+						{
+							"ruleId": "FOO",
+							"level": "error",
+							"message": {"text": "Hello."},
+							"locations": [
+								{
+									"physicalLocation": {
+										"artifactLocation": {"uri": "path/to/file.proto"},
+										"region": {"startLine": 2, "startColumn": 1, "endLine": 2, "endColumn": 5}
+									}
+								}
+							]
+						},
+						{
+							"ruleId": "BAR",
+							"level": "error",
+							"message": {"text": "Goodbye."}
+						}
+					]
+				}
+			]
+		}`,
+		buffer.String(),
+	)
+}
+
+type testRule struct {
+	id         string
+	purpose    string
+	categories []string
+}
+
+func newTestRule(id string, purpose string, categories ...string) *testRule {
+	return &testRule{
+		id:         id,
+		purpose:    purpose,
+		categories: categories,
+	}
+}
+
+func (r *testRule) ID() string {
+	return r.id
+}
+
+func (r *testRule) Categories() []string {
+	return r.categories
+}
+
+func (r *testRule) Purpose() string {
+	return r.purpose
 }
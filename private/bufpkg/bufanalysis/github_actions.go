@@ -0,0 +1,77 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufanalysis
+
+import (
+	"strconv"
+	"strings"
+)
+
+var (
+	gitHubActionsDataReplacer = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+	)
+	gitHubActionsPropertyReplacer = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+		":", "%3A",
+		",", "%2C",
+	)
+)
+
+// gitHubActionsString returns the string representation of the FileAnnotation
+// as a GitHub Actions error workflow command.
+//
+// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-an-error-message
+func gitHubActionsString(fileAnnotation FileAnnotation) string {
+	message := fileAnnotation.Message()
+	if message == "" {
+		message = fileAnnotation.Type()
+		// should never happen but just in case
+		if message == "" {
+			message = "FAILURE"
+		}
+	}
+	var properties []string
+	if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+		properties = append(properties, "file="+gitHubActionsPropertyReplacer.Replace(fileInfo.ExternalPath()))
+		properties = appendGitHubActionsIntProperty(properties, "line", fileAnnotation.StartLine())
+		properties = appendGitHubActionsIntProperty(properties, "endLine", fileAnnotation.EndLine())
+		properties = appendGitHubActionsIntProperty(properties, "col", fileAnnotation.StartColumn())
+		properties = appendGitHubActionsIntProperty(properties, "endColumn", fileAnnotation.EndColumn())
+	}
+	if typeString := fileAnnotation.Type(); typeString != "" {
+		properties = append(properties, "title="+gitHubActionsPropertyReplacer.Replace(typeString))
+	}
+	var builder strings.Builder
+	_, _ = builder.WriteString("::error")
+	if len(properties) > 0 {
+		_, _ = builder.WriteRune(' ')
+		_, _ = builder.WriteString(strings.Join(properties, ","))
+	}
+	_, _ = builder.WriteString("::")
+	_, _ = builder.WriteString(gitHubActionsDataReplacer.Replace(message))
+	return builder.String()
+}
+
+func appendGitHubActionsIntProperty(properties []string, key string, value int) []string {
+	if value == 0 {
+		return properties
+	}
+	return append(properties, key+"="+strconv.Itoa(value))
+}
@@ -0,0 +1,120 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufanalysis
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// printFileAnnotationsJUnit prints a single JUnit XML document containing all FileAnnotations.
+//
+// Each file is a testsuite and each FileAnnotation is a failing testcase within it.
+// Testsuites are printed in the order their files first appear in fileAnnotations.
+func printFileAnnotationsJUnit(writer io.Writer, fileAnnotations []FileAnnotation) error {
+	data, err := marshalJUnit(fileAnnotations)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
+
+func marshalJUnit(fileAnnotations []FileAnnotation) ([]byte, error) {
+	testSuites := &junitTestSuites{
+		Tests:    len(fileAnnotations),
+		Failures: len(fileAnnotations),
+	}
+	pathToTestSuite := make(map[string]*junitTestSuite)
+	for _, fileAnnotation := range fileAnnotations {
+		path := "<input>"
+		if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+			path = fileInfo.ExternalPath()
+		}
+		testSuite, ok := pathToTestSuite[path]
+		if !ok {
+			testSuite = &junitTestSuite{
+				Name: path,
+			}
+			pathToTestSuite[path] = testSuite
+			testSuites.TestSuites = append(testSuites.TestSuites, testSuite)
+		}
+		testSuite.Tests++
+		testSuite.Failures++
+		testSuite.TestCases = append(testSuite.TestCases, newJUnitTestCase(path, fileAnnotation))
+	}
+	data, err := xml.MarshalIndent(testSuites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+func newJUnitTestCase(path string, fileAnnotation FileAnnotation) *junitTestCase {
+	line := fileAnnotation.StartLine()
+	column := fileAnnotation.StartColumn()
+	if line == 0 {
+		line = 1
+	}
+	if column == 0 {
+		column = 1
+	}
+	typeString := fileAnnotation.Type()
+	if typeString == "" {
+		// should never happen but just in case
+		typeString = "FAILURE"
+	}
+	message := fileAnnotation.Message()
+	if message == "" {
+		message = typeString
+	}
+	return &junitTestCase{
+		ClassName: path,
+		Name:      path + ":" + strconv.Itoa(line) + ":" + strconv.Itoa(column) + ":" + typeString,
+		Failure: &junitFailure{
+			Message:  message,
+			Type:     typeString,
+			Contents: fileAnnotation.String(),
+		},
+	}
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name          `xml:"testsuites"`
+	Tests      int               `xml:"tests,attr"`
+	Failures   int               `xml:"failures,attr"`
+	TestSuites []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Errors    int              `xml:"errors,attr"`
+	TestCases []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
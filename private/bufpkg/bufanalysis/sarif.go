@@ -0,0 +1,209 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufanalysis
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+)
+
+const (
+	sarifSchema         = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion        = "2.1.0"
+	sarifToolName       = "buf"
+	sarifToolInfoURI    = "https://buf.build"
+	sarifResultLevel    = "error"
+	sarifDefaultMessage = "FAILURE"
+)
+
+// printFileAnnotationsSARIF prints a single SARIF log containing all FileAnnotations.
+//
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+func printFileAnnotationsSARIF(writer io.Writer, fileAnnotations []FileAnnotation, rules []Rule) error {
+	data, err := marshalSARIF(fileAnnotations, rules)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
+
+func marshalSARIF(fileAnnotations []FileAnnotation, rules []Rule) ([]byte, error) {
+	results := make([]*sarifResult, 0, len(fileAnnotations))
+	for _, fileAnnotation := range fileAnnotations {
+		results = append(results, newSARIFResult(fileAnnotation))
+	}
+	return json.Marshal(
+		&sarifLog{
+			Schema:  sarifSchema,
+			Version: sarifVersion,
+			Runs: []*sarifRun{
+				{
+					Tool: &sarifTool{
+						Driver: &sarifDriver{
+							Name:           sarifToolName,
+							InformationURI: sarifToolInfoURI,
+							Rules:          newSARIFRules(rules),
+						},
+					},
+					Results: results,
+				},
+			},
+		},
+	)
+}
+
+func newSARIFRules(rules []Rule) []*sarifRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	sarifRules := make([]*sarifRule, 0, len(rules))
+	seen := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		id := rule.ID()
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		sarifRule := &sarifRule{
+			ID: id,
+		}
+		if purpose := rule.Purpose(); purpose != "" {
+			sarifRule.ShortDescription = &sarifMessage{
+				Text: purpose,
+			}
+		}
+		if categories := rule.Categories(); len(categories) > 0 {
+			sarifRule.Properties = &sarifRuleProperties{
+				Tags: categories,
+			}
+		}
+		sarifRules = append(sarifRules, sarifRule)
+	}
+	return sarifRules
+}
+
+func newSARIFResult(fileAnnotation FileAnnotation) *sarifResult {
+	message := fileAnnotation.Message()
+	if message == "" {
+		message = fileAnnotation.Type()
+		// should never happen but just in case
+		if message == "" {
+			message = sarifDefaultMessage
+		}
+	}
+	result := &sarifResult{
+		RuleID: fileAnnotation.Type(),
+		Level:  sarifResultLevel,
+		Message: &sarifMessage{
+			Text: message,
+		},
+	}
+	fileInfo := fileAnnotation.FileInfo()
+	if fileInfo == nil {
+		return result
+	}
+	physicalLocation := &sarifPhysicalLocation{
+		ArtifactLocation: &sarifArtifactLocation{
+			URI: sarifURIForPath(fileInfo.ExternalPath()),
+		},
+	}
+	// SARIF requires lines and columns to be 1-based if present, so we
+	// omit the region entirely if we do not know the starting line.
+	if fileAnnotation.StartLine() > 0 {
+		physicalLocation.Region = &sarifRegion{
+			StartLine:   fileAnnotation.StartLine(),
+			StartColumn: fileAnnotation.StartColumn(),
+			EndLine:     fileAnnotation.EndLine(),
+			EndColumn:   fileAnnotation.EndColumn(),
+		}
+	}
+	result.Locations = []*sarifLocation{
+		{
+			PhysicalLocation: physicalLocation,
+		},
+	}
+	return result
+}
+
+func sarifURIForPath(path string) string {
+	if filepath.IsAbs(path) {
+		return "file://" + filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(path)
+}
+
+type sarifLog struct {
+	Schema  string      `json:"$schema,omitempty"`
+	Version string      `json:"version,omitempty"`
+	Runs    []*sarifRun `json:"runs,omitempty"`
+}
+
+type sarifRun struct {
+	Tool    *sarifTool     `json:"tool,omitempty"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver *sarifDriver `json:"driver,omitempty"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name,omitempty"`
+	InformationURI string       `json:"informationUri,omitempty"`
+	Rules          []*sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string               `json:"id,omitempty"`
+	ShortDescription *sarifMessage        `json:"shortDescription,omitempty"`
+	Properties       *sarifRuleProperties `json:"properties,omitempty"`
+}
+
+type sarifRuleProperties struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string           `json:"ruleId,omitempty"`
+	Level     string           `json:"level,omitempty"`
+	Message   *sarifMessage    `json:"message,omitempty"`
+	Locations []*sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation *sarifArtifactLocation `json:"artifactLocation,omitempty"`
+	Region           *sarifRegion           `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
@@ -0,0 +1,179 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufchangelog computes the changes between two sets of Protobuf files,
+// whether or not they are breaking.
+package bufchangelog
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/private/pkg/protosource"
+)
+
+const (
+	// FormatMarkdown is the Markdown format.
+	FormatMarkdown Format = iota + 1
+	// FormatJSON is the JSON format.
+	FormatJSON
+)
+
+const (
+	// KindAdded is the Kind for added elements.
+	KindAdded Kind = iota + 1
+	// KindRemoved is the Kind for removed elements.
+	KindRemoved
+	// KindDeprecated is the Kind for elements that became deprecated.
+	KindDeprecated
+	// KindChanged is the Kind for all other changes to elements.
+	KindChanged
+)
+
+const (
+	// ElementFile is the Element for files.
+	ElementFile = "file"
+	// ElementMessage is the Element for messages.
+	ElementMessage = "message"
+	// ElementField is the Element for fields.
+	ElementField = "field"
+	// ElementEnum is the Element for enums.
+	ElementEnum = "enum"
+	// ElementEnumValue is the Element for enum values.
+	ElementEnumValue = "enum value"
+	// ElementService is the Element for services.
+	ElementService = "service"
+	// ElementRPC is the Element for RPCs.
+	ElementRPC = "rpc"
+)
+
+var (
+	// AllFormatStrings is all format strings.
+	//
+	// Sorted in the order we want to display them.
+	AllFormatStrings = []string{
+		"markdown",
+		"json",
+	}
+
+	stringToFormat = map[string]Format{
+		"markdown": FormatMarkdown,
+		"json":     FormatJSON,
+	}
+	formatToString = map[Format]string{
+		FormatMarkdown: "markdown",
+		FormatJSON:     "json",
+	}
+	kindToString = map[Kind]string{
+		KindAdded:      "added",
+		KindRemoved:    "removed",
+		KindDeprecated: "deprecated",
+		KindChanged:    "changed",
+	}
+)
+
+// Format is a changelog format.
+type Format int
+
+// String implements fmt.Stringer.
+func (f Format) String() string {
+	s, ok := formatToString[f]
+	if !ok {
+		return strconv.Itoa(int(f))
+	}
+	return s
+}
+
+// ParseFormat parses the Format.
+//
+// The empty strings defaults to FormatMarkdown.
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return FormatMarkdown, nil
+	}
+	f, ok := stringToFormat[s]
+	if ok {
+		return f, nil
+	}
+	return 0, fmt.Errorf("unknown format: %q", s)
+}
+
+// Kind is the kind of a Change.
+type Kind int
+
+// String implements fmt.Stringer.
+func (k Kind) String() string {
+	s, ok := kindToString[k]
+	if !ok {
+		return strconv.Itoa(int(k))
+	}
+	return s
+}
+
+// Change is a change to a single element.
+type Change struct {
+	// Package is the package of the element.
+	Package string
+	// Kind is the kind of the change.
+	Kind Kind
+	// Element is the type of the element, such as ElementMessage or ElementEnumValue.
+	Element string
+	// Name is the fully-qualified name of the element, or the path for files.
+	Name string
+	// Details describes what changed for KindChanged, and is empty otherwise.
+	Details string
+	// Path is the path of the file that contains the element.
+	//
+	// This is the path in the previous files for KindRemoved, and the path
+	// in the files otherwise.
+	Path string
+	// Line is the line of the element within Path, or 0 if not known.
+	Line int
+	// Descriptor is the element, or the File for file changes.
+	//
+	// This is the element in the previous files for KindRemoved, and the element
+	// in the files otherwise.
+	Descriptor protosource.Descriptor
+	// Location is the location of the change within Path, or nil if not known.
+	Location protosource.Location
+}
+
+// GetChanges returns the Changes from the previous files to the files.
+//
+// Added, removed, deprecated, and changed files, messages, fields, enums,
+// enum values, services, and RPCs are all returned, including changes to
+// comments. Members of added or removed elements are not returned separately.
+//
+// The files should have source code info for comment changes and lines to be
+// returned. Changes are sorted by package, kind, name, and then details.
+func GetChanges(previousFiles []protosource.File, files []protosource.File) ([]*Change, error) {
+	return getChanges(previousFiles, files)
+}
+
+// PrintChanges prints the Changes in the Format to the writer.
+//
+// The Markdown format has one section per package, with one subsection per Kind.
+func PrintChanges(writer io.Writer, format Format, changes []*Change) error {
+	switch format {
+	case FormatMarkdown:
+		return printChangesMarkdown(writer, changes)
+	case FormatJSON:
+		return printChangesJSON(writer, changes)
+	default:
+		return fmt.Errorf("unknown format: %v", format)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufchangelog
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagebuild"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPrintChangesMarkdown(t *testing.T) {
+	t.Parallel()
+	changes, err := GetChanges(testGetFiles(t, "previous"), testGetFiles(t, "current"))
+	require.NoError(t, err)
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, PrintChanges(buffer, FormatMarkdown, changes))
+	assert.Equal(
+		t,
+		`# Changelog
+
+## `+"`a`"+`
+
+### Added
+
+- Message `+"`a.Four`"+`
+- Field `+"`a.One.four`"+`
+- Enum value `+"`a.Two.TWO_TWO`"+`
+
+### Removed
+
+- Message `+"`a.One.Nested`"+`
+- Field `+"`a.One.three`"+`
+- RPC `+"`a.Three.Delete`"+`
+
+### Deprecated
+
+- Field `+"`a.One.renamed`"+`
+- Service `+"`a.Three`"+`
+
+### Changed
+
+- Message `+"`a.One`"+`: comment changed
+- Field `+"`a.One.one`"+`: type changed from `+"`int32`"+` to `+"`int64`"+`
+- Field `+"`a.One.renamed`"+`: JSON name changed from `+"`two`"+` to `+"`renamed`"+`
+- Field `+"`a.One.renamed`"+`: name changed from `+"`two`"+` to `+"`renamed`"+`
+- RPC `+"`a.Three.Get`"+`: server streaming changed from false to true
+- Enum value `+"`a.Two.TWO_ONE`"+`: number changed from 1 to 2
+`,
+		buffer.String(),
+	)
+}
+
+func TestPrintChangesJSON(t *testing.T) {
+	t.Parallel()
+	changes, err := GetChanges(testGetFiles(t, "previous"), testGetFiles(t, "current"))
+	require.NoError(t, err)
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, PrintChanges(buffer, FormatJSON, changes))
+	assert.Contains(
+		t,
+		buffer.String(),
+		`{"package":"a","kind":"changed","element":"field","name":"a.One.one","details":"type changed from `+"`int32`"+` to `+"`int64`"+`","path":"a.proto","line":7}`,
+	)
+}
+
+func TestGetChangesNoChanges(t *testing.T) {
+	t.Parallel()
+	changes, err := GetChanges(testGetFiles(t, "current"), testGetFiles(t, "current"))
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, PrintChanges(buffer, FormatMarkdown, changes))
+	assert.Equal(t, "# Changelog\n\nNo changes.\n", buffer.String())
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, FormatMarkdown, format)
+	format, err = ParseFormat("JSON")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+	_, err = ParseFormat("html")
+	assert.Error(t, err)
+}
+
+func testGetFiles(t *testing.T, relDirPath string) []protosource.File {
+	ctx := context.Background()
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(filepath.Join("testdata", relDirPath))
+	require.NoError(t, err)
+	module, err := bufmodule.NewModuleForBucket(ctx, readWriteBucket)
+	require.NoError(t, err)
+	moduleFileSet, err := bufmodulebuild.NewModuleFileSetBuilder(
+		zap.NewNop(),
+		bufmodule.NewNopModuleReader(),
+	).Build(
+		ctx,
+		module,
+	)
+	require.NoError(t, err)
+	image, annotations, err := bufimagebuild.NewBuilder(zap.NewNop()).Build(ctx, moduleFileSet)
+	require.NoError(t, err)
+	require.Empty(t, annotations)
+	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	require.NoError(t, err)
+	return files
+}
@@ -0,0 +1,351 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufchangelog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/private/pkg/protosource"
+)
+
+// element is an element that can be deprecated and has comments.
+type element interface {
+	protosource.LocationDescriptor
+
+	Deprecated() bool
+}
+
+type changeBuilder struct {
+	changes []*Change
+}
+
+func newChangeBuilder() *changeBuilder {
+	return &changeBuilder{}
+}
+
+func (b *changeBuilder) add(kind Kind, elementType string, name string, descriptor protosource.Descriptor, location protosource.Location, details string) {
+	line := 0
+	if location != nil {
+		line = location.StartLine()
+	}
+	b.changes = append(
+		b.changes,
+		&Change{
+			Package:    descriptor.File().Package(),
+			Kind:       kind,
+			Element:    elementType,
+			Name:       name,
+			Details:    details,
+			Path:       descriptor.File().Path(),
+			Line:       line,
+			Descriptor: descriptor,
+			Location:   location,
+		},
+	)
+}
+
+func (b *changeBuilder) addAdded(elementType string, name string, descriptor protosource.LocationDescriptor) {
+	b.add(KindAdded, elementType, name, descriptor, descriptor.Location(), "")
+}
+
+func (b *changeBuilder) addRemoved(elementType string, name string, previousDescriptor protosource.LocationDescriptor) {
+	b.add(KindRemoved, elementType, name, previousDescriptor, previousDescriptor.Location(), "")
+}
+
+func (b *changeBuilder) addChangedf(elementType string, name string, descriptor protosource.LocationDescriptor, format string, args ...interface{}) {
+	b.add(KindChanged, elementType, name, descriptor, descriptor.Location(), fmt.Sprintf(format, args...))
+}
+
+// addElementChanges adds the deprecation and comment changes for an element.
+func (b *changeBuilder) addElementChanges(elementType string, name string, previousElement element, element element) {
+	if !previousElement.Deprecated() && element.Deprecated() {
+		b.add(KindDeprecated, elementType, name, element, element.Location(), "")
+	}
+	if previousElement.Deprecated() && !element.Deprecated() {
+		b.addChangedf(elementType, name, element, "no longer deprecated")
+	}
+	previousLocation := previousElement.Location()
+	location := element.Location()
+	// comments can only be compared if both sides have source code info
+	if previousLocation != nil && location != nil &&
+		strings.TrimSpace(previousLocation.LeadingComments()) != strings.TrimSpace(location.LeadingComments()) {
+		b.addChangedf(elementType, name, element, "comment changed")
+	}
+}
+
+func getChanges(previousFiles []protosource.File, files []protosource.File) ([]*Change, error) {
+	changeBuilder := newChangeBuilder()
+	if err := addFileChanges(changeBuilder, previousFiles, files); err != nil {
+		return nil, err
+	}
+	if err := addMessageChanges(changeBuilder, previousFiles, files); err != nil {
+		return nil, err
+	}
+	if err := addEnumChanges(changeBuilder, previousFiles, files); err != nil {
+		return nil, err
+	}
+	if err := addServiceChanges(changeBuilder, previousFiles, files); err != nil {
+		return nil, err
+	}
+	changes := changeBuilder.changes
+	sort.Slice(
+		changes,
+		func(i int, j int) bool {
+			one := changes[i]
+			two := changes[j]
+			if one.Package != two.Package {
+				return one.Package < two.Package
+			}
+			if one.Kind != two.Kind {
+				return one.Kind < two.Kind
+			}
+			if one.Name != two.Name {
+				return one.Name < two.Name
+			}
+			return one.Details < two.Details
+		},
+	)
+	return changes, nil
+}
+
+func addFileChanges(changeBuilder *changeBuilder, previousFiles []protosource.File, files []protosource.File) error {
+	return protosource.ForEachFilePair(
+		func(previousFile protosource.File, file protosource.File) error {
+			switch {
+			case file == nil:
+				changeBuilder.add(KindRemoved, ElementFile, previousFile.Path(), previousFile, nil, "")
+				return nil
+			case previousFile == nil:
+				changeBuilder.add(KindAdded, ElementFile, file.Path(), file, nil, "")
+				return nil
+			}
+			filePath := file.Path()
+			if previousFile.Package() != file.Package() {
+				changeBuilder.add(KindChanged, ElementFile, filePath, file, file.PackageLocation(), fmt.Sprintf("package changed from %s to %s", quote(previousFile.Package()), quote(file.Package())))
+			}
+			if !previousFile.Deprecated() && file.Deprecated() {
+				changeBuilder.add(KindDeprecated, ElementFile, filePath, file, nil, "")
+			}
+			if previousFile.Deprecated() && !file.Deprecated() {
+				changeBuilder.add(KindChanged, ElementFile, filePath, file, nil, "no longer deprecated")
+			}
+			return nil
+		},
+		previousFiles,
+		files,
+	)
+}
+
+func addMessageChanges(changeBuilder *changeBuilder, previousFiles []protosource.File, files []protosource.File) error {
+	// nested messages are only skipped if their parent message was removed or added
+	previousFullNameToMessage, err := protosource.FullNameToMessage(previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToMessage, err := protosource.FullNameToMessage(files...)
+	if err != nil {
+		return err
+	}
+	return protosource.ForEachMessagePair(
+		func(previousMessage protosource.Message, message protosource.Message) error {
+			switch {
+			case message == nil:
+				if parent := previousMessage.Parent(); parent == nil || fullNameToMessage[parent.FullName()] != nil {
+					changeBuilder.addRemoved(ElementMessage, previousMessage.FullName(), previousMessage)
+				}
+				return nil
+			case previousMessage == nil:
+				if parent := message.Parent(); parent == nil || previousFullNameToMessage[parent.FullName()] != nil {
+					changeBuilder.addAdded(ElementMessage, message.FullName(), message)
+				}
+				return nil
+			}
+			changeBuilder.addElementChanges(ElementMessage, message.FullName(), previousMessage, message)
+			return addFieldChanges(changeBuilder, previousMessage, message)
+		},
+		previousFiles,
+		files,
+	)
+}
+
+func addFieldChanges(changeBuilder *changeBuilder, previousMessage protosource.Message, message protosource.Message) error {
+	return protosource.ForEachFieldPair(
+		func(previousField protosource.Field, field protosource.Field) error {
+			switch {
+			case field == nil:
+				changeBuilder.addRemoved(ElementField, fieldName(previousField), previousField)
+				return nil
+			case previousField == nil:
+				changeBuilder.addAdded(ElementField, fieldName(field), field)
+				return nil
+			}
+			name := fieldName(field)
+			if previousField.Name() != field.Name() {
+				changeBuilder.addChangedf(ElementField, name, field, "name changed from %s to %s", quote(previousField.Name()), quote(field.Name()))
+			}
+			if previousFieldType, fieldType := fieldTypeString(previousField), fieldTypeString(field); previousFieldType != fieldType {
+				changeBuilder.addChangedf(ElementField, name, field, "type changed from %s to %s", quote(previousFieldType), quote(fieldType))
+			}
+			if previousField.Label() != field.Label() {
+				changeBuilder.addChangedf(ElementField, name, field, "label changed from %s to %s", quote(previousField.Label().String()), quote(field.Label().String()))
+			}
+			if previousField.JSONName() != field.JSONName() {
+				changeBuilder.addChangedf(ElementField, name, field, "JSON name changed from %s to %s", quote(previousField.JSONName()), quote(field.JSONName()))
+			}
+			if previousOneofName, oneofName := fieldOneofName(previousField), fieldOneofName(field); previousOneofName != oneofName {
+				changeBuilder.addChangedf(ElementField, name, field, "oneof changed from %s to %s", quote(previousOneofName), quote(oneofName))
+			}
+			changeBuilder.addElementChanges(ElementField, name, previousField, field)
+			return nil
+		},
+		previousMessage,
+		message,
+	)
+}
+
+func addEnumChanges(changeBuilder *changeBuilder, previousFiles []protosource.File, files []protosource.File) error {
+	// enums are only skipped if their parent message was removed or added
+	previousFullNameToMessage, err := protosource.FullNameToMessage(previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToMessage, err := protosource.FullNameToMessage(files...)
+	if err != nil {
+		return err
+	}
+	return protosource.ForEachEnumPair(
+		func(previousEnum protosource.Enum, enum protosource.Enum) error {
+			switch {
+			case enum == nil:
+				if parent := previousEnum.Parent(); parent == nil || fullNameToMessage[parent.FullName()] != nil {
+					changeBuilder.addRemoved(ElementEnum, previousEnum.FullName(), previousEnum)
+				}
+				return nil
+			case previousEnum == nil:
+				if parent := enum.Parent(); parent == nil || previousFullNameToMessage[parent.FullName()] != nil {
+					changeBuilder.addAdded(ElementEnum, enum.FullName(), enum)
+				}
+				return nil
+			}
+			changeBuilder.addElementChanges(ElementEnum, enum.FullName(), previousEnum, enum)
+			return addEnumValueChanges(changeBuilder, previousEnum, enum)
+		},
+		previousFiles,
+		files,
+	)
+}
+
+func addEnumValueChanges(changeBuilder *changeBuilder, previousEnum protosource.Enum, enum protosource.Enum) error {
+	return protosource.ForEachEnumValuePair(
+		func(previousEnumValue protosource.EnumValue, enumValue protosource.EnumValue) error {
+			switch {
+			case enumValue == nil:
+				changeBuilder.addRemoved(ElementEnumValue, previousEnumValue.FullName(), previousEnumValue)
+				return nil
+			case previousEnumValue == nil:
+				changeBuilder.addAdded(ElementEnumValue, enumValue.FullName(), enumValue)
+				return nil
+			}
+			if previousEnumValue.Number() != enumValue.Number() {
+				changeBuilder.addChangedf(ElementEnumValue, enumValue.FullName(), enumValue, "number changed from %d to %d", previousEnumValue.Number(), enumValue.Number())
+			}
+			changeBuilder.addElementChanges(ElementEnumValue, enumValue.FullName(), previousEnumValue, enumValue)
+			return nil
+		},
+		previousEnum,
+		enum,
+	)
+}
+
+func addServiceChanges(changeBuilder *changeBuilder, previousFiles []protosource.File, files []protosource.File) error {
+	return protosource.ForEachServicePair(
+		func(previousService protosource.Service, service protosource.Service) error {
+			switch {
+			case service == nil:
+				changeBuilder.addRemoved(ElementService, previousService.FullName(), previousService)
+				return nil
+			case previousService == nil:
+				changeBuilder.addAdded(ElementService, service.FullName(), service)
+				return nil
+			}
+			changeBuilder.addElementChanges(ElementService, service.FullName(), previousService, service)
+			return addRPCChanges(changeBuilder, previousService, service)
+		},
+		previousFiles,
+		files,
+	)
+}
+
+func addRPCChanges(changeBuilder *changeBuilder, previousService protosource.Service, service protosource.Service) error {
+	return protosource.ForEachMethodPair(
+		func(previousMethod protosource.Method, method protosource.Method) error {
+			switch {
+			case method == nil:
+				changeBuilder.addRemoved(ElementRPC, previousMethod.FullName(), previousMethod)
+				return nil
+			case previousMethod == nil:
+				changeBuilder.addAdded(ElementRPC, method.FullName(), method)
+				return nil
+			}
+			fullName := method.FullName()
+			if previousMethod.InputTypeName() != method.InputTypeName() {
+				changeBuilder.addChangedf(ElementRPC, fullName, method, "request type changed from %s to %s", quote(previousMethod.InputTypeName()), quote(method.InputTypeName()))
+			}
+			if previousMethod.OutputTypeName() != method.OutputTypeName() {
+				changeBuilder.addChangedf(ElementRPC, fullName, method, "response type changed from %s to %s", quote(previousMethod.OutputTypeName()), quote(method.OutputTypeName()))
+			}
+			if previousMethod.ClientStreaming() != method.ClientStreaming() {
+				changeBuilder.addChangedf(ElementRPC, fullName, method, "client streaming changed from %t to %t", previousMethod.ClientStreaming(), method.ClientStreaming())
+			}
+			if previousMethod.ServerStreaming() != method.ServerStreaming() {
+				changeBuilder.addChangedf(ElementRPC, fullName, method, "server streaming changed from %t to %t", previousMethod.ServerStreaming(), method.ServerStreaming())
+			}
+			changeBuilder.addElementChanges(ElementRPC, fullName, previousMethod, method)
+			return nil
+		},
+		previousService,
+		service,
+	)
+}
+
+// fieldName returns the name of the field qualified by the message, as
+// fields do not have a full name that is unique across renames.
+func fieldName(field protosource.Field) string {
+	return field.Message().FullName() + "." + field.Name()
+}
+
+func fieldTypeString(field protosource.Field) string {
+	if typeName := strings.TrimPrefix(field.TypeName(), "."); typeName != "" {
+		return typeName
+	}
+	return field.Type().String()
+}
+
+func fieldOneofName(field protosource.Field) string {
+	if oneof := field.Oneof(); oneof != nil {
+		return oneof.Name()
+	}
+	return ""
+}
+
+// quote quotes s with backticks.
+func quote(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return "`" + s + "`"
+}
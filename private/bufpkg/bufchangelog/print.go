@@ -0,0 +1,109 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufchangelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+var (
+	elementToTitle = map[string]string{
+		ElementFile:      "File",
+		ElementMessage:   "Message",
+		ElementField:     "Field",
+		ElementEnum:      "Enum",
+		ElementEnumValue: "Enum value",
+		ElementService:   "Service",
+		ElementRPC:       "RPC",
+	}
+	kindToTitle = map[Kind]string{
+		KindAdded:      "Added",
+		KindRemoved:    "Removed",
+		KindDeprecated: "Deprecated",
+		KindChanged:    "Changed",
+	}
+)
+
+func printChangesMarkdown(writer io.Writer, changes []*Change) error {
+	buffer := bytes.NewBuffer(nil)
+	_, _ = buffer.WriteString("# Changelog\n")
+	if len(changes) == 0 {
+		_, _ = buffer.WriteString("\nNo changes.\n")
+	}
+	for i, change := range changes {
+		// changes are sorted by package and then kind
+		if i == 0 || changes[i-1].Package != change.Package {
+			packageTitle := "(no package)"
+			if change.Package != "" {
+				packageTitle = "`" + change.Package + "`"
+			}
+			_, _ = buffer.WriteString("\n## " + packageTitle + "\n")
+		}
+		if i == 0 || changes[i-1].Package != change.Package || changes[i-1].Kind != change.Kind {
+			_, _ = buffer.WriteString("\n### " + kindToTitle[change.Kind] + "\n\n")
+		}
+		_, _ = buffer.WriteString("- " + elementToTitle[change.Element] + " `" + change.Name + "`")
+		if change.Details != "" {
+			_, _ = buffer.WriteString(": " + change.Details)
+		}
+		_, _ = buffer.WriteString("\n")
+	}
+	_, err := writer.Write(buffer.Bytes())
+	return err
+}
+
+func printChangesJSON(writer io.Writer, changes []*Change) error {
+	externalChanges := make([]externalChange, len(changes))
+	for i, change := range changes {
+		externalChanges[i] = newExternalChange(change)
+	}
+	data, err := json.Marshal(externalChangelog{Changes: externalChanges})
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	_, err = writer.Write([]byte("\n"))
+	return err
+}
+
+type externalChangelog struct {
+	Changes []externalChange `json:"changes" yaml:"changes"`
+}
+
+type externalChange struct {
+	Package string `json:"package,omitempty" yaml:"package,omitempty"`
+	Kind    string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Element string `json:"element,omitempty" yaml:"element,omitempty"`
+	Name    string `json:"name,omitempty" yaml:"name,omitempty"`
+	Details string `json:"details,omitempty" yaml:"details,omitempty"`
+	Path    string `json:"path,omitempty" yaml:"path,omitempty"`
+	Line    int    `json:"line,omitempty" yaml:"line,omitempty"`
+}
+
+func newExternalChange(change *Change) externalChange {
+	return externalChange{
+		Package: change.Package,
+		Kind:    change.Kind.String(),
+		Element: change.Element,
+		Name:    change.Name,
+		Details: change.Details,
+		Path:    change.Path,
+		Line:    change.Line,
+	}
+}
@@ -19,18 +19,85 @@ package bufbreaking
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/bufbreakingconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingcompat"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingv1"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingv1beta1"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"go.uber.org/zap"
 )
 
+const (
+	// LevelSource checks for changes that break generated source code.
+	LevelSource Level = iota + 1
+	// LevelWire checks for changes that break the binary wire format.
+	LevelWire
+	// LevelJSON checks for changes that break the JSON format.
+	LevelJSON
+)
+
+var (
+	// AllLevelStrings is all level strings.
+	//
+	// Sorted in the order we want to display them.
+	AllLevelStrings = []string{
+		"source",
+		"wire",
+		"json",
+	}
+
+	stringToLevel = map[string]Level{
+		"source": LevelSource,
+		"wire":   LevelWire,
+		"json":   LevelJSON,
+	}
+	levelToString = map[Level]string{
+		LevelSource: "source",
+		LevelWire:   "wire",
+		LevelJSON:   "json",
+	}
+	levelToCompatibility = map[Level]bufbreakingcheck.Compatibility{
+		LevelSource: bufbreakingcheck.CompatibilitySource,
+		LevelWire:   bufbreakingcheck.CompatibilityWire,
+		LevelJSON:   bufbreakingcheck.CompatibilityJSON,
+	}
+)
+
+// Level is a compatibility level that breaking changes are checked for.
+type Level int
+
+// String implements fmt.Stringer.
+func (l Level) String() string {
+	s, ok := levelToString[l]
+	if !ok {
+		return strconv.Itoa(int(l))
+	}
+	return s
+}
+
+// ParseLevel parses the Level.
+func ParseLevel(s string) (Level, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, fmt.Errorf("level required, must be one of %s", stringutil.SliceToString(AllLevelStrings))
+	}
+	level, ok := stringToLevel[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown level: %q, must be one of %s", s, stringutil.SliceToString(AllLevelStrings))
+	}
+	return level, nil
+}
+
 // Handler handles the main breaking functionality.
 type Handler interface {
 	// Check runs the breaking checks.
@@ -39,6 +106,8 @@ type Handler interface {
 	// does not need to have source code info.
 	//
 	// Images should be filtered with regards to imports before passing to this function.
+	//
+	// Each FileAnnotation reports whether the change breaks source, wire, and JSON compatibility.
 	Check(
 		ctx context.Context,
 		config *bufbreakingconfig.Config,
@@ -48,8 +117,21 @@ type Handler interface {
 }
 
 // NewHandler returns a new Handler.
-func NewHandler(logger *zap.Logger) Handler {
-	return newHandler(logger)
+func NewHandler(logger *zap.Logger, options ...HandlerOption) Handler {
+	return newHandler(logger, options...)
+}
+
+// HandlerOption is an option for a new Handler.
+type HandlerOption func(*handler)
+
+// HandlerWithLevel returns a new HandlerOption that only returns changes that
+// break the given compatibility level.
+//
+// The default is to return all changes.
+func HandlerWithLevel(level Level) HandlerOption {
+	return func(handler *handler) {
+		handler.level = level
+	}
 }
 
 // RulesForConfig returns the rules for a given config.
@@ -110,12 +192,30 @@ func GetAllRulesAndCategoriesV1() []string {
 }
 
 func internalConfigForConfig(config *bufbreakingconfig.Config) (*internal.Config, error) {
+	return internalConfigForConfigAndLevel(config, 0)
+}
+
+// internalConfigForConfigAndLevel returns the internal.Config for the config whose
+// rules only report changes that break the level.
+//
+// If the level is 0, all changes are reported.
+func internalConfigForConfigAndLevel(config *bufbreakingconfig.Config, level Level) (*internal.Config, error) {
+	compatibility := bufbreakingcheck.CompatibilitySource | bufbreakingcheck.CompatibilityWire | bufbreakingcheck.CompatibilityJSON
+	if level != 0 {
+		var ok bool
+		compatibility, ok = levelToCompatibility[level]
+		if !ok {
+			return nil, fmt.Errorf("unknown level: %v", level)
+		}
+	}
 	var versionSpec *internal.VersionSpec
 	switch config.Version {
 	case bufconfig.V1Beta1Version:
 		versionSpec = bufbreakingv1beta1.VersionSpec
 	case bufconfig.V1Version:
 		versionSpec = bufbreakingv1.VersionSpec
+	default:
+		return nil, fmt.Errorf("unknown version: %q", config.Version)
 	}
 	return internal.ConfigBuilder{
 		Use:                           config.Use,
@@ -124,7 +224,7 @@ func internalConfigForConfig(config *bufbreakingconfig.Config) (*internal.Config
 		IgnoreIDOrCategoryToRootPaths: config.IgnoreIDOrCategoryToRootPaths,
 		IgnoreUnstablePackages:        config.IgnoreUnstablePackages,
 	}.NewConfig(
-		versionSpec,
+		bufbreakingcompat.NewVersionSpec(versionSpec, compatibility),
 	)
 }
 
@@ -450,6 +450,23 @@ func TestRunBreakingMessageNoDelete(t *testing.T) {
 	)
 }
 
+func TestRunBreakingNoDeleteUnlessDeprecated(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_no_delete_unless_deprecated",
+		// message Five and service Removed were deleted along with 2.proto, while messages
+		// Seven and Eight were moved between files
+		bufanalysistesting.NewFileAnnotationNoLocationOrPath(t, "MESSAGE_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotationNoLocationOrPath(t, "RPC_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "1.proto", "MESSAGE_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 5, 1, 7, 2, "FIELD_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 9, 1, 10, 2, "MESSAGE_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 12, 1, 14, 2, "RPC_NO_DELETE_UNLESS_DEPRECATED"),
+		// services Deleted and DeletedDeprecated were deleted from 3.proto
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "3.proto", "RPC_NO_DELETE_UNLESS_DEPRECATED"),
+	)
+}
+
 func TestRunBreakingMessageSameValues(t *testing.T) {
 	testBreaking(
 		t,
@@ -662,10 +679,101 @@ func TestRunBreakingIgnoreUnstablePackagesDeleteFile(t *testing.T) {
 	)
 }
 
+func TestRunBreakingLevelSource(t *testing.T) {
+	testBreakingLevel(
+		t,
+		"breaking_level",
+		bufbreaking.LevelSource,
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 6, 3, 6, 8, "FIELD_SAME_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 7, 3, 7, 9, "FIELD_SAME_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 8, 3, 8, 8, "FIELD_SAME_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 9, 3, 9, 21, "FIELD_SAME_JSON_NAME"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 9, 9, 9, 16, "FIELD_SAME_NAME"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 12, 1, 15, 2, "FIELD_NO_DELETE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 17, 1, 19, 2, "FIELD_NO_DELETE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 21, 1, 21, 16, "FIELD_NO_DELETE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 23, 1, 26, 2, "ENUM_VALUE_NO_DELETE"),
+	)
+}
+
+func TestRunBreakingLevelWire(t *testing.T) {
+	testBreakingLevel(
+		t,
+		"breaking_level",
+		bufbreaking.LevelWire,
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 7, 3, 7, 9, "FIELD_SAME_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 21, 1, 21, 16, "FIELD_NO_DELETE"),
+	)
+}
+
+func TestRunBreakingLevelJSON(t *testing.T) {
+	testBreakingLevel(
+		t,
+		"breaking_level",
+		bufbreaking.LevelJSON,
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 6, 3, 6, 8, "FIELD_SAME_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 8, 3, 8, 8, "FIELD_SAME_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 9, 3, 9, 21, "FIELD_SAME_JSON_NAME"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 9, 9, 9, 16, "FIELD_SAME_NAME"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 17, 1, 19, 2, "FIELD_NO_DELETE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 21, 1, 21, 16, "FIELD_NO_DELETE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 23, 1, 26, 2, "ENUM_VALUE_NO_DELETE"),
+	)
+}
+
+func TestRunBreakingLevelUse(t *testing.T) {
+	// Only the configured rules are checked.
+	testBreakingLevel(
+		t,
+		"breaking_level_use",
+		bufbreaking.LevelJSON,
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 6, 3, 6, 8, "FIELD_SAME_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 8, 3, 8, 8, "FIELD_SAME_TYPE"),
+	)
+}
+
+func TestParseLevel(t *testing.T) {
+	t.Parallel()
+	for _, levelString := range bufbreaking.AllLevelStrings {
+		level, err := bufbreaking.ParseLevel(levelString)
+		require.NoError(t, err)
+		assert.Equal(t, levelString, level.String())
+	}
+	_, err := bufbreaking.ParseLevel("")
+	assert.Error(t, err)
+	_, err = bufbreaking.ParseLevel("binary")
+	assert.Error(t, err)
+}
+
 func testBreaking(
 	t *testing.T,
 	relDirPath string,
 	expectedFileAnnotations ...bufanalysis.FileAnnotation,
+) {
+	testBreakingWithHandlerOptions(t, relDirPath, nil, expectedFileAnnotations...)
+}
+
+func testBreakingLevel(
+	t *testing.T,
+	relDirPath string,
+	level bufbreaking.Level,
+	expectedFileAnnotations ...bufanalysis.FileAnnotation,
+) {
+	testBreakingWithHandlerOptions(
+		t,
+		relDirPath,
+		[]bufbreaking.HandlerOption{
+			bufbreaking.HandlerWithLevel(level),
+		},
+		expectedFileAnnotations...,
+	)
+}
+
+func testBreakingWithHandlerOptions(
+	t *testing.T,
+	relDirPath string,
+	handlerOptions []bufbreaking.HandlerOption,
+	expectedFileAnnotations ...bufanalysis.FileAnnotation,
 ) {
 	t.Parallel()
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -735,7 +843,7 @@ func testBreaking(
 	require.Empty(t, fileAnnotations)
 	image = bufimage.ImageWithoutImports(image)
 
-	handler := bufbreaking.NewHandler(logger)
+	handler := bufbreaking.NewHandler(logger, handlerOptions...)
 	fileAnnotations, err = handler.Check(
 		ctx,
 		config.Breaking,
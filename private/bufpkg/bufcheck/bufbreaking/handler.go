@@ -29,17 +29,23 @@ import (
 type handler struct {
 	logger *zap.Logger
 	runner *internal.Runner
+	level  Level
 }
 
 func newHandler(
 	logger *zap.Logger,
+	options ...HandlerOption,
 ) *handler {
-	return &handler{
+	handler := &handler{
 		logger: logger,
 		// comment ignores are not allowed for breaking changes
 		// so do not set the ignore prefix per the RunnerWithIgnorePrefix comments
 		runner: internal.NewRunner(logger),
 	}
+	for _, option := range options {
+		option(handler)
+	}
+	return handler
 }
 
 func (h *handler) Check(
@@ -56,7 +62,7 @@ func (h *handler) Check(
 	if err != nil {
 		return nil, err
 	}
-	internalConfig, err := internalConfigForConfig(config)
+	internalConfig, err := internalConfigForConfigAndLevel(config, h.level)
 	if err != nil {
 		return nil, err
 	}
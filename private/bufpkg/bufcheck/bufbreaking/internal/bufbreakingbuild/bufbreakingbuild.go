@@ -68,6 +68,12 @@ var (
 		"fields are not deleted from a given message",
 		bufbreakingcheck.CheckFieldNoDelete,
 	)
+	// FieldNoDeleteUnlessDeprecatedRuleBuilder is a rule builder.
+	FieldNoDeleteUnlessDeprecatedRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_NO_DELETE_UNLESS_DEPRECATED",
+		"fields are not deleted from a given message unless they were previously deprecated",
+		bufbreakingcheck.CheckFieldNoDeleteUnlessDeprecated,
+	)
 	// FieldNoDeleteUnlessNameReservedRuleBuilder is a rule builder.
 	FieldNoDeleteUnlessNameReservedRuleBuilder = internal.NewNopRuleBuilder(
 		"FIELD_NO_DELETE_UNLESS_NAME_RESERVED",
@@ -266,6 +272,12 @@ var (
 		"messages are not deleted from a given file",
 		bufbreakingcheck.CheckMessageNoDelete,
 	)
+	// MessageNoDeleteUnlessDeprecatedRuleBuilder is a rule builder.
+	MessageNoDeleteUnlessDeprecatedRuleBuilder = internal.NewNopRuleBuilder(
+		"MESSAGE_NO_DELETE_UNLESS_DEPRECATED",
+		"messages are not deleted from a given package unless they were previously deprecated",
+		bufbreakingcheck.CheckMessageNoDeleteUnlessDeprecated,
+	)
 	// MessageNoRemoveStandardDescriptorAccessorRuleBuilder is a rule builder.
 	MessageNoRemoveStandardDescriptorAccessorRuleBuilder = internal.NewNopRuleBuilder(
 		"MESSAGE_NO_REMOVE_STANDARD_DESCRIPTOR_ACCESSOR",
@@ -332,6 +344,12 @@ var (
 		"rpcs are not deleted from a given service",
 		bufbreakingcheck.CheckRPCNoDelete,
 	)
+	// RPCNoDeleteUnlessDeprecatedRuleBuilder is a rule builder.
+	RPCNoDeleteUnlessDeprecatedRuleBuilder = internal.NewNopRuleBuilder(
+		"RPC_NO_DELETE_UNLESS_DEPRECATED",
+		"rpcs are not deleted from a given package unless they or their service were previously deprecated",
+		bufbreakingcheck.CheckRPCNoDeleteUnlessDeprecated,
+	)
 	// RPCSameClientStreamingRuleBuilder is a rule builder.
 	RPCSameClientStreamingRuleBuilder = internal.NewNopRuleBuilder(
 		"RPC_SAME_CLIENT_STREAMING",
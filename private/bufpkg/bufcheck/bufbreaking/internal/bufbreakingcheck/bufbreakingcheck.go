@@ -181,6 +181,28 @@ func checkFieldNoDeleteUnlessNameReserved(add addFunc, corpus *corpus, previousM
 	return checkFieldNoDeleteWithRules(add, previousMessage, message, false, true)
 }
 
+// CheckFieldNoDeleteUnlessDeprecated is a check function.
+var CheckFieldNoDeleteUnlessDeprecated = newMessagePairCheckFunc(checkFieldNoDeleteUnlessDeprecated)
+
+func checkFieldNoDeleteUnlessDeprecated(add addFunc, corpus *corpus, previousMessage protosource.Message, message protosource.Message) error {
+	previousNumberToField, err := protosource.NumberToMessageField(previousMessage)
+	if err != nil {
+		return err
+	}
+	numberToField, err := protosource.NumberToMessageField(message)
+	if err != nil {
+		return err
+	}
+	for previousNumber, previousField := range previousNumberToField {
+		if _, ok := numberToField[previousNumber]; !ok && !previousField.Deprecated() {
+			// otherwise prints as hex
+			previousNumberString := strconv.FormatInt(int64(previousNumber), 10)
+			add(message, nil, message.Location(), `Previously present field %q with name %q on message %q was deleted without first being deprecated.`, previousNumberString, previousField.Name(), message.Name())
+		}
+	}
+	return nil
+}
+
 func checkFieldNoDeleteWithRules(add addFunc, previousMessage protosource.Message, message protosource.Message, allowIfNumberReserved bool, allowIfNameReserved bool) error {
 	previousNumberToField, err := protosource.NumberToMessageField(previousMessage)
 	if err != nil {
@@ -432,38 +454,44 @@ func checkFieldWireJSONCompatibleType(add addFunc, corpus *corpus, previousField
 }
 
 func checkEnumWireCompatibleForField(add addFunc, corpus *corpus, previousField protosource.Field, field protosource.Field) error {
+	compatible, err := isEnumWireCompatibleForField(corpus, previousField, field)
+	if err != nil {
+		return err
+	}
+	if !compatible {
+		addEnumGroupMessageFieldChangedTypeName(add, previousField, field)
+	}
+	return nil
+}
+
+func isEnumWireCompatibleForField(corpus *corpus, previousField protosource.Field, field protosource.Field) (bool, error) {
 	previousEnum, err := getEnumByFullName(
 		corpus.previousFiles,
 		strings.TrimPrefix(previousField.TypeName(), "."),
 	)
 	if err != nil {
-		return err
+		return false, err
 	}
 	enum, err := getEnumByFullName(
 		corpus.files,
 		strings.TrimPrefix(field.TypeName(), "."),
 	)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if previousEnum.Name() != enum.Name() {
 		// If the short names are not equal, we say that this is a different enum.
-		addEnumGroupMessageFieldChangedTypeName(add, previousField, field)
-		return nil
+		return false, nil
 	}
 	isSubset, err := protosource.EnumIsSubset(enum, previousEnum)
 	if err != nil {
-		return err
-	}
-	if !isSubset {
-		// If the previous enum is not a subset of the new enum, we say that
-		// this is a different enum.
-		// We allow subsets so that enum values can be added within the
-		// same change.
-		addEnumGroupMessageFieldChangedTypeName(add, previousField, field)
-		return nil
+		return false, err
 	}
-	return nil
+	// If the previous enum is not a subset of the new enum, we say that
+	// this is a different enum.
+	// We allow subsets so that enum values can be added within the
+	// same change.
+	return isSubset, nil
 }
 
 func addFieldChangedType(add addFunc, previousField protosource.Field, field protosource.Field, extraMessages ...string) {
@@ -703,6 +731,51 @@ func checkMessageNoDelete(add addFunc, corpus *corpus, previousFile protosource.
 	return nil
 }
 
+// CheckMessageNoDeleteUnlessDeprecated is a check function.
+var CheckMessageNoDeleteUnlessDeprecated = newFilesCheckFunc(checkMessageNoDeleteUnlessDeprecated)
+
+func checkMessageNoDeleteUnlessDeprecated(add addFunc, corpus *corpus) error {
+	previousPackageToNestedNameToMessage, err := protosource.PackageToNestedNameToMessage(corpus.previousFiles...)
+	if err != nil {
+		return err
+	}
+	packageToNestedNameToMessage, err := protosource.PackageToNestedNameToMessage(corpus.files...)
+	if err != nil {
+		return err
+	}
+	// caching across loops
+	var filePathToFile map[string]protosource.File
+	for previousPackage, previousNestedNameToMessage := range previousPackageToNestedNameToMessage {
+		// messages are keyed on their package so that messages moved between files are not
+		// deleted, and messages in deleted files or packages are still checked
+		nestedNameToMessage := packageToNestedNameToMessage[previousPackage]
+		for previousNestedName, previousMessage := range previousNestedNameToMessage {
+			if _, ok := nestedNameToMessage[previousNestedName]; ok || isDeletedMessageDeprecated(previousMessage, nestedNameToMessage) {
+				continue
+			}
+			// if cache not populated, populate it
+			if filePathToFile == nil {
+				filePathToFile, err = protosource.FilePathToFile(corpus.files...)
+				if err != nil {
+					return err
+				}
+			}
+			// Check if the file still exists.
+			file, ok := filePathToFile[previousMessage.File().Path()]
+			if ok {
+				// File exists, try to get a location to attach the error to.
+				descriptor, location := getDescriptorAndLocationForDeletedMessage(file, nestedNameToMessage, previousNestedName)
+				add(descriptor, nil, location, `Previously present message %q was deleted from package %q without first being deprecated.`, previousNestedName, previousPackage)
+			} else {
+				// File does not exist, we don't know where the message was deleted from.
+				// Add the previous message to check for ignores.
+				add(nil, []protosource.Descriptor{previousMessage}, nil, `Previously present message %q was deleted from package %q without first being deprecated.`, previousNestedName, previousPackage)
+			}
+		}
+	}
+	return nil
+}
+
 // CheckMessageNoRemoveStandardDescriptorAccessor is a check function.
 var CheckMessageNoRemoveStandardDescriptorAccessor = newMessagePairCheckFunc(checkMessageNoRemoveStandardDescriptorAccessor)
 
@@ -1009,6 +1082,73 @@ func checkRPCNoDelete(add addFunc, corpus *corpus, previousService protosource.S
 	return nil
 }
 
+// CheckRPCNoDeleteUnlessDeprecated is a check function.
+var CheckRPCNoDeleteUnlessDeprecated = newFilesCheckFunc(checkRPCNoDeleteUnlessDeprecated)
+
+func checkRPCNoDeleteUnlessDeprecated(add addFunc, corpus *corpus) error {
+	previousPackageToNameToService, err := protosource.PackageToNameToService(corpus.previousFiles...)
+	if err != nil {
+		return err
+	}
+	packageToNameToService, err := protosource.PackageToNameToService(corpus.files...)
+	if err != nil {
+		return err
+	}
+	// caching across loops
+	var filePathToFile map[string]protosource.File
+	for previousPackage, previousNameToService := range previousPackageToNameToService {
+		// services are keyed on their package so that services moved between files are
+		// compared, and the RPCs of services in deleted files or packages are still checked
+		nameToService := packageToNameToService[previousPackage]
+		for previousName, previousService := range previousNameToService {
+			if previousService.Deprecated() {
+				// all RPCs on a deprecated service are deprecated
+				continue
+			}
+			previousNameToMethod, err := protosource.NameToMethod(previousService)
+			if err != nil {
+				return err
+			}
+			service, ok := nameToService[previousName]
+			if ok {
+				nameToMethod, err := protosource.NameToMethod(service)
+				if err != nil {
+					return err
+				}
+				for previousMethodName, previousMethod := range previousNameToMethod {
+					if _, ok := nameToMethod[previousMethodName]; !ok && !previousMethod.Deprecated() {
+						add(service, nil, service.Location(), `Previously present RPC %q on service %q was deleted without first being deprecated.`, previousMethodName, service.Name())
+					}
+				}
+				continue
+			}
+			for previousMethodName, previousMethod := range previousNameToMethod {
+				if previousMethod.Deprecated() {
+					continue
+				}
+				// if cache not populated, populate it
+				if filePathToFile == nil {
+					filePathToFile, err = protosource.FilePathToFile(corpus.files...)
+					if err != nil {
+						return err
+					}
+				}
+				// Check if the file still exists.
+				file, ok := filePathToFile[previousService.File().Path()]
+				if ok {
+					// File exists.
+					add(file, nil, nil, `Previously present RPC %q on service %q was deleted with the service without first being deprecated.`, previousMethodName, previousName)
+				} else {
+					// File does not exist, we don't know where the service was deleted from.
+					// Add the previous RPC to check for ignores.
+					add(nil, []protosource.Descriptor{previousMethod}, nil, `Previously present RPC %q on service %q was deleted with the service without first being deprecated.`, previousMethodName, previousName)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // CheckRPCSameClientStreaming is a check function.
 var CheckRPCSameClientStreaming = newMethodPairCheckFunc(checkRPCSameClientStreaming)
 
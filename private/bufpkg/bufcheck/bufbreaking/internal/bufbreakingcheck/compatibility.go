@@ -0,0 +1,348 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreakingcheck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+)
+
+const (
+	// CompatibilitySource is set if a change breaks generated source code.
+	CompatibilitySource Compatibility = 1 << iota
+	// CompatibilityWire is set if a change breaks the binary wire format.
+	CompatibilityWire
+	// CompatibilityJSON is set if a change breaks the JSON format.
+	CompatibilityJSON
+)
+
+var (
+	// https://developers.google.com/protocol-buffers/docs/proto3#json
+	//
+	// All integer types are accepted as either JSON numbers or strings, but 64-bit
+	// integers are printed as strings, so we keep 32-bit and 64-bit integers apart.
+	fieldDescriptorProtoTypeToJSONCompatibilityGroup = map[protosource.FieldDescriptorProtoType]int{
+		protosource.FieldDescriptorProtoTypeInt32:    1,
+		protosource.FieldDescriptorProtoTypeUint32:   1,
+		protosource.FieldDescriptorProtoTypeSint32:   1,
+		protosource.FieldDescriptorProtoTypeFixed32:  1,
+		protosource.FieldDescriptorProtoTypeSfixed32: 1,
+		protosource.FieldDescriptorProtoTypeInt64:    2,
+		protosource.FieldDescriptorProtoTypeUint64:   2,
+		protosource.FieldDescriptorProtoTypeSint64:   2,
+		protosource.FieldDescriptorProtoTypeFixed64:  2,
+		protosource.FieldDescriptorProtoTypeSfixed64: 2,
+		protosource.FieldDescriptorProtoTypeBool:     3,
+		protosource.FieldDescriptorProtoTypeString:   4,
+		protosource.FieldDescriptorProtoTypeBytes:    5,
+		protosource.FieldDescriptorProtoTypeDouble:   6,
+		protosource.FieldDescriptorProtoTypeFloat:    7,
+		protosource.FieldDescriptorProtoTypeGroup:    8,
+		protosource.FieldDescriptorProtoTypeMessage:  9,
+		protosource.FieldDescriptorProtoTypeEnum:     10,
+	}
+)
+
+// Compatibility is the set of kinds of compatibility that a change breaks.
+type Compatibility int
+
+// String implements fmt.Stringer.
+//
+// All three kinds of compatibility are always printed.
+func (c Compatibility) String() string {
+	return fmt.Sprintf(
+		"source: %s, wire: %s, json: %s",
+		compatibilityBreakingString(c&CompatibilitySource != 0),
+		compatibilityBreakingString(c&CompatibilityWire != 0),
+		compatibilityBreakingString(c&CompatibilityJSON != 0),
+	)
+}
+
+// NewCompatibilityCheckFunc returns a new check function for a check function
+// whose failures all break the given Compatibility.
+//
+// Failures are only reported if the Compatibility breaks the level, and
+// have the Compatibility appended to their message.
+func NewCompatibilityCheckFunc(checkFunc internal.CheckFunc, compatibility Compatibility, level Compatibility) internal.CheckFunc {
+	return func(id string, ignoreFunc internal.IgnoreFunc, previousFiles []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+		if compatibility&level == 0 {
+			return nil, nil
+		}
+		fileAnnotations, err := checkFunc(id, ignoreFunc, previousFiles, files)
+		if err != nil {
+			return nil, err
+		}
+		for i, fileAnnotation := range fileAnnotations {
+			fileAnnotations[i] = bufanalysis.NewFileAnnotation(
+				fileAnnotation.FileInfo(),
+				fileAnnotation.StartLine(),
+				fileAnnotation.StartColumn(),
+				fileAnnotation.EndLine(),
+				fileAnnotation.EndColumn(),
+				fileAnnotation.Type(),
+				appendCompatibility(fileAnnotation.Message(), compatibility),
+			)
+		}
+		return fileAnnotations, nil
+	}
+}
+
+// CheckEnumValueNoDeleteCompatibility is a compatibility check function.
+var CheckEnumValueNoDeleteCompatibility = newEnumPairCompatibilityCheckFunc(checkEnumValueNoDeleteCompatibility)
+
+func checkEnumValueNoDeleteCompatibility(add compatibilityAddFunc, corpus *corpus, previousEnum protosource.Enum, enum protosource.Enum) error {
+	previousNumberToNameToEnumValue, err := protosource.NumberToNameToEnumValue(previousEnum)
+	if err != nil {
+		return err
+	}
+	numberToNameToEnumValue, err := protosource.NumberToNameToEnumValue(enum)
+	if err != nil {
+		return err
+	}
+	for previousNumber, previousNameToEnumValue := range previousNumberToNameToEnumValue {
+		if _, ok := numberToNameToEnumValue[previousNumber]; !ok {
+			compatibility := CompatibilitySource
+			if !isDeletedEnumValueAllowedWithRules(previousNumber, previousNameToEnumValue, enum, true, false) {
+				compatibility |= CompatibilityWire
+			}
+			if !isDeletedEnumValueAllowedWithRules(previousNumber, previousNameToEnumValue, enum, false, true) {
+				compatibility |= CompatibilityJSON
+			}
+			add(compatibility)(enum, nil, enum.Location(), `Previously present enum value "%d" on enum %q was deleted.`, previousNumber, enum.Name())
+		}
+	}
+	return nil
+}
+
+// CheckFieldNoDeleteCompatibility is a compatibility check function.
+var CheckFieldNoDeleteCompatibility = newMessagePairCompatibilityCheckFunc(checkFieldNoDeleteCompatibility)
+
+func checkFieldNoDeleteCompatibility(add compatibilityAddFunc, corpus *corpus, previousMessage protosource.Message, message protosource.Message) error {
+	previousNumberToField, err := protosource.NumberToMessageField(previousMessage)
+	if err != nil {
+		return err
+	}
+	numberToField, err := protosource.NumberToMessageField(message)
+	if err != nil {
+		return err
+	}
+	for previousNumber, previousField := range previousNumberToField {
+		if _, ok := numberToField[previousNumber]; !ok {
+			// otherwise prints as hex
+			previousNumberString := strconv.FormatInt(int64(previousNumber), 10)
+			add(getDeletedFieldCompatibility(previousField, message))(message, nil, message.Location(), `Previously present field %q with name %q on message %q was deleted.`, previousNumberString, previousField.Name(), message.Name())
+		}
+	}
+	return nil
+}
+
+// CheckFieldNoDeleteUnlessDeprecatedCompatibility is a compatibility check function.
+var CheckFieldNoDeleteUnlessDeprecatedCompatibility = newMessagePairCompatibilityCheckFunc(checkFieldNoDeleteUnlessDeprecatedCompatibility)
+
+func checkFieldNoDeleteUnlessDeprecatedCompatibility(add compatibilityAddFunc, corpus *corpus, previousMessage protosource.Message, message protosource.Message) error {
+	previousNumberToField, err := protosource.NumberToMessageField(previousMessage)
+	if err != nil {
+		return err
+	}
+	numberToField, err := protosource.NumberToMessageField(message)
+	if err != nil {
+		return err
+	}
+	for previousNumber, previousField := range previousNumberToField {
+		if _, ok := numberToField[previousNumber]; !ok && !previousField.Deprecated() {
+			// otherwise prints as hex
+			previousNumberString := strconv.FormatInt(int64(previousNumber), 10)
+			add(getDeletedFieldCompatibility(previousField, message))(message, nil, message.Location(), `Previously present field %q with name %q on message %q was deleted without first being deprecated.`, previousNumberString, previousField.Name(), message.Name())
+		}
+	}
+	return nil
+}
+
+// CheckFieldSameTypeCompatibility is a compatibility check function.
+var CheckFieldSameTypeCompatibility = newFieldTypeCompatibilityCheckFunc(checkFieldSameType)
+
+// CheckFieldWireCompatibleTypeCompatibility is a compatibility check function.
+var CheckFieldWireCompatibleTypeCompatibility = newFieldTypeCompatibilityCheckFunc(checkFieldWireCompatibleType)
+
+// CheckFieldWireJSONCompatibleTypeCompatibility is a compatibility check function.
+var CheckFieldWireJSONCompatibleTypeCompatibility = newFieldTypeCompatibilityCheckFunc(checkFieldWireJSONCompatibleType)
+
+// getDeletedFieldCompatibility returns the Compatibility broken by deleting the field.
+//
+// A deleted field is only wire breaking if its number can be reused, and only
+// JSON breaking if its name can be reused.
+func getDeletedFieldCompatibility(previousField protosource.Field, message protosource.Message) Compatibility {
+	compatibility := CompatibilitySource
+	if !isDeletedFieldAllowedWithRules(previousField, message, true, false) {
+		compatibility |= CompatibilityWire
+	}
+	if !isDeletedFieldAllowedWithRules(previousField, message, false, true) {
+		compatibility |= CompatibilityJSON
+	}
+	return compatibility
+}
+
+// getFieldTypeCompatibility returns the Compatibility broken by changing the type
+// of the field, or 0 if the type did not change.
+func getFieldTypeCompatibility(corpus *corpus, previousField protosource.Field, field protosource.Field) (Compatibility, error) {
+	if previousField.Type() != field.Type() {
+		compatibility := CompatibilitySource
+		wireCompatible, err := isFieldTypeWireCompatible(previousField.Type(), field.Type())
+		if err != nil {
+			return 0, err
+		}
+		if !wireCompatible {
+			compatibility |= CompatibilityWire
+		}
+		jsonCompatible, err := isFieldTypeJSONCompatible(previousField.Type(), field.Type())
+		if err != nil {
+			return 0, err
+		}
+		if !jsonCompatible {
+			compatibility |= CompatibilityJSON
+		}
+		return compatibility, nil
+	}
+	if previousField.TypeName() == field.TypeName() {
+		return 0, nil
+	}
+	switch field.Type() {
+	case protosource.FieldDescriptorProtoTypeEnum:
+		compatible, err := isEnumWireCompatibleForField(corpus, previousField, field)
+		if err != nil {
+			return 0, err
+		}
+		if compatible {
+			// Enums are encoded by number on the wire and by name in JSON,
+			// and both are the same if the values of the previous enum are
+			// a subset of the new enum.
+			return CompatibilitySource, nil
+		}
+		return CompatibilitySource | CompatibilityWire | CompatibilityJSON, nil
+	case protosource.FieldDescriptorProtoTypeGroup,
+		protosource.FieldDescriptorProtoTypeMessage:
+		return CompatibilitySource | CompatibilityWire | CompatibilityJSON, nil
+	}
+	return 0, nil
+}
+
+// compatibilityAddFunc returns the addFunc for FileAnnotations that break the Compatibility.
+type compatibilityAddFunc func(Compatibility) addFunc
+
+func newCompatibilityAddFunc(add addFunc, level Compatibility) compatibilityAddFunc {
+	return func(compatibility Compatibility) addFunc {
+		return func(descriptor protosource.Descriptor, extraIgnoreDescriptors []protosource.Descriptor, location protosource.Location, format string, args ...interface{}) {
+			if compatibility&level == 0 {
+				return
+			}
+			add(descriptor, extraIgnoreDescriptors, location, "%s", appendCompatibility(fmt.Sprintf(format, args...), compatibility))
+		}
+	}
+}
+
+func newEnumPairCompatibilityCheckFunc(
+	f func(compatibilityAddFunc, *corpus, protosource.Enum, protosource.Enum) error,
+) func(Compatibility) internal.CheckFunc {
+	return func(level Compatibility) internal.CheckFunc {
+		return newEnumPairCheckFunc(
+			func(add addFunc, corpus *corpus, previousEnum protosource.Enum, enum protosource.Enum) error {
+				return f(newCompatibilityAddFunc(add, level), corpus, previousEnum, enum)
+			},
+		)
+	}
+}
+
+func newMessagePairCompatibilityCheckFunc(
+	f func(compatibilityAddFunc, *corpus, protosource.Message, protosource.Message) error,
+) func(Compatibility) internal.CheckFunc {
+	return func(level Compatibility) internal.CheckFunc {
+		return newMessagePairCheckFunc(
+			func(add addFunc, corpus *corpus, previousMessage protosource.Message, message protosource.Message) error {
+				return f(newCompatibilityAddFunc(add, level), corpus, previousMessage, message)
+			},
+		)
+	}
+}
+
+func newFieldPairCompatibilityCheckFunc(
+	f func(compatibilityAddFunc, *corpus, protosource.Field, protosource.Field) error,
+) func(Compatibility) internal.CheckFunc {
+	return func(level Compatibility) internal.CheckFunc {
+		return newFieldPairCheckFunc(
+			func(add addFunc, corpus *corpus, previousField protosource.Field, field protosource.Field) error {
+				return f(newCompatibilityAddFunc(add, level), corpus, previousField, field)
+			},
+		)
+	}
+}
+
+// newFieldTypeCompatibilityCheckFunc returns a new compatibility check function for
+// a check function of field type changes, whose failures break the Compatibility
+// of the type change.
+func newFieldTypeCompatibilityCheckFunc(
+	f func(addFunc, *corpus, protosource.Field, protosource.Field) error,
+) func(Compatibility) internal.CheckFunc {
+	return newFieldPairCompatibilityCheckFunc(
+		func(add compatibilityAddFunc, corpus *corpus, previousField protosource.Field, field protosource.Field) error {
+			compatibility, err := getFieldTypeCompatibility(corpus, previousField, field)
+			if err != nil {
+				return err
+			}
+			return f(add(compatibility), corpus, previousField, field)
+		},
+	)
+}
+
+func isFieldTypeWireCompatible(previousType protosource.FieldDescriptorProtoType, fieldType protosource.FieldDescriptorProtoType) (bool, error) {
+	previousWireCompatibilityGroup, ok := fieldDescriptorProtoTypeToWireCompatiblityGroup[previousType]
+	if !ok {
+		return false, fmt.Errorf("unknown FieldDescriptorProtoType: %v", previousType)
+	}
+	wireCompatibilityGroup, ok := fieldDescriptorProtoTypeToWireCompatiblityGroup[fieldType]
+	if !ok {
+		return false, fmt.Errorf("unknown FieldDescriptorProtoType: %v", fieldType)
+	}
+	// It is OK to evolve from string to bytes
+	return previousWireCompatibilityGroup == wireCompatibilityGroup ||
+		(previousType == protosource.FieldDescriptorProtoTypeString && fieldType == protosource.FieldDescriptorProtoTypeBytes), nil
+}
+
+func isFieldTypeJSONCompatible(previousType protosource.FieldDescriptorProtoType, fieldType protosource.FieldDescriptorProtoType) (bool, error) {
+	previousJSONCompatibilityGroup, ok := fieldDescriptorProtoTypeToJSONCompatibilityGroup[previousType]
+	if !ok {
+		return false, fmt.Errorf("unknown FieldDescriptorProtoType: %v", previousType)
+	}
+	jsonCompatibilityGroup, ok := fieldDescriptorProtoTypeToJSONCompatibilityGroup[fieldType]
+	if !ok {
+		return false, fmt.Errorf("unknown FieldDescriptorProtoType: %v", fieldType)
+	}
+	return previousJSONCompatibilityGroup == jsonCompatibilityGroup, nil
+}
+
+func appendCompatibility(message string, compatibility Compatibility) string {
+	return strings.TrimSpace(message) + " (" + compatibility.String() + ")"
+}
+
+func compatibilityBreakingString(breaking bool) string {
+	if breaking {
+		return "breaking"
+	}
+	return "compatible"
+}
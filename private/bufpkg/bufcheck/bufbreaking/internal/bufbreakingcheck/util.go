@@ -123,22 +123,16 @@ func newFilePairCheckFunc(
 ) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
 	return newFilesCheckFunc(
 		func(add addFunc, corpus *corpus) error {
-			previousFilePathToFile, err := protosource.FilePathToFile(corpus.previousFiles...)
-			if err != nil {
-				return err
-			}
-			filePathToFile, err := protosource.FilePathToFile(corpus.files...)
-			if err != nil {
-				return err
-			}
-			for previousFilePath, previousFile := range previousFilePathToFile {
-				if file, ok := filePathToFile[previousFilePath]; ok {
-					if err := f(add, corpus, previousFile, file); err != nil {
-						return err
+			return protosource.ForEachFilePair(
+				func(previousFile protosource.File, file protosource.File) error {
+					if previousFile == nil || file == nil {
+						return nil
 					}
-				}
-			}
-			return nil
+					return f(add, corpus, previousFile, file)
+				},
+				corpus.previousFiles,
+				corpus.files,
+			)
 		},
 	)
 }
@@ -148,22 +142,16 @@ func newEnumPairCheckFunc(
 ) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
 	return newFilesCheckFunc(
 		func(add addFunc, corpus *corpus) error {
-			previousFullNameToEnum, err := protosource.FullNameToEnum(corpus.previousFiles...)
-			if err != nil {
-				return err
-			}
-			fullNameToEnum, err := protosource.FullNameToEnum(corpus.files...)
-			if err != nil {
-				return err
-			}
-			for previousFullName, previousEnum := range previousFullNameToEnum {
-				if enum, ok := fullNameToEnum[previousFullName]; ok {
-					if err := f(add, corpus, previousEnum, enum); err != nil {
-						return err
+			return protosource.ForEachEnumPair(
+				func(previousEnum protosource.Enum, enum protosource.Enum) error {
+					if previousEnum == nil || enum == nil {
+						return nil
 					}
-				}
-			}
-			return nil
+					return f(add, corpus, previousEnum, enum)
+				},
+				corpus.previousFiles,
+				corpus.files,
+			)
 		},
 	)
 }
@@ -200,22 +188,16 @@ func newMessagePairCheckFunc(
 ) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
 	return newFilesCheckFunc(
 		func(add addFunc, corpus *corpus) error {
-			previousFullNameToMessage, err := protosource.FullNameToMessage(corpus.previousFiles...)
-			if err != nil {
-				return err
-			}
-			fullNameToMessage, err := protosource.FullNameToMessage(corpus.files...)
-			if err != nil {
-				return err
-			}
-			for previousFullName, previousMessage := range previousFullNameToMessage {
-				if message, ok := fullNameToMessage[previousFullName]; ok {
-					if err := f(add, corpus, previousMessage, message); err != nil {
-						return err
+			return protosource.ForEachMessagePair(
+				func(previousMessage protosource.Message, message protosource.Message) error {
+					if previousMessage == nil || message == nil {
+						return nil
 					}
-				}
-			}
-			return nil
+					return f(add, corpus, previousMessage, message)
+				},
+				corpus.previousFiles,
+				corpus.files,
+			)
 		},
 	)
 }
@@ -225,22 +207,16 @@ func newFieldPairCheckFunc(
 ) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
 	return newMessagePairCheckFunc(
 		func(add addFunc, corpus *corpus, previousMessage protosource.Message, message protosource.Message) error {
-			previousNumberToField, err := protosource.NumberToMessageField(previousMessage)
-			if err != nil {
-				return err
-			}
-			numberToField, err := protosource.NumberToMessageField(message)
-			if err != nil {
-				return err
-			}
-			for previousNumber, previousField := range previousNumberToField {
-				if field, ok := numberToField[previousNumber]; ok {
-					if err := f(add, corpus, previousField, field); err != nil {
-						return err
+			return protosource.ForEachFieldPair(
+				func(previousField protosource.Field, field protosource.Field) error {
+					if previousField == nil || field == nil {
+						return nil
 					}
-				}
-			}
-			return nil
+					return f(add, corpus, previousField, field)
+				},
+				previousMessage,
+				message,
+			)
 		},
 	)
 }
@@ -250,22 +226,16 @@ func newServicePairCheckFunc(
 ) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
 	return newFilesCheckFunc(
 		func(add addFunc, corpus *corpus) error {
-			previousFullNameToService, err := protosource.FullNameToService(corpus.previousFiles...)
-			if err != nil {
-				return err
-			}
-			fullNameToService, err := protosource.FullNameToService(corpus.files...)
-			if err != nil {
-				return err
-			}
-			for previousFullName, previousService := range previousFullNameToService {
-				if service, ok := fullNameToService[previousFullName]; ok {
-					if err := f(add, corpus, previousService, service); err != nil {
-						return err
+			return protosource.ForEachServicePair(
+				func(previousService protosource.Service, service protosource.Service) error {
+					if previousService == nil || service == nil {
+						return nil
 					}
-				}
-			}
-			return nil
+					return f(add, corpus, previousService, service)
+				},
+				corpus.previousFiles,
+				corpus.files,
+			)
 		},
 	)
 }
@@ -275,22 +245,16 @@ func newMethodPairCheckFunc(
 ) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
 	return newServicePairCheckFunc(
 		func(add addFunc, corpus *corpus, previousService protosource.Service, service protosource.Service) error {
-			previousNameToMethod, err := protosource.NameToMethod(previousService)
-			if err != nil {
-				return err
-			}
-			nameToMethod, err := protosource.NameToMethod(service)
-			if err != nil {
-				return err
-			}
-			for previousName, previousMethod := range previousNameToMethod {
-				if method, ok := nameToMethod[previousName]; ok {
-					if err := f(add, corpus, previousMethod, method); err != nil {
-						return err
+			return protosource.ForEachMethodPair(
+				func(previousMethod protosource.Method, method protosource.Method) error {
+					if previousMethod == nil || method == nil {
+						return nil
 					}
-				}
-			}
-			return nil
+					return f(add, corpus, previousMethod, method)
+				},
+				previousService,
+				service,
+			)
 		},
 	)
 }
@@ -323,6 +287,22 @@ func getDescriptorAndLocationForDeletedMessage(file protosource.File, nestedName
 	return file, nil
 }
 
+// isDeletedMessageDeprecated returns true if the deleted message was deprecated, or if
+// it was deleted along with an enclosing message that was deprecated.
+func isDeletedMessageDeprecated(previousMessage protosource.Message, nestedNameToMessage map[string]protosource.Message) bool {
+	for message := previousMessage; message != nil; message = message.Parent() {
+		if _, ok := nestedNameToMessage[message.NestedName()]; ok {
+			// the enclosing message still exists, so the nested message
+			// must have been deprecated itself
+			return false
+		}
+		if message.Deprecated() {
+			return true
+		}
+	}
+	return false
+}
+
 func getSortedEnumValueNames(nameToEnumValue map[string]protosource.EnumValue) []string {
 	names := make([]string, 0, len(nameToEnumValue))
 	for name := range nameToEnumValue {
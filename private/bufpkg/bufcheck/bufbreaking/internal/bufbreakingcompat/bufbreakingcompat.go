@@ -0,0 +1,114 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufbreakingcompat classifies the failures of the breaking rules by the
+// kinds of compatibility they break.
+package bufbreakingcompat
+
+import (
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
+)
+
+var (
+	// idToCompatibilityCheckFunc are the rules that classify each failure individually.
+	idToCompatibilityCheckFunc = map[string]func(bufbreakingcheck.Compatibility) internal.CheckFunc{
+		"ENUM_VALUE_NO_DELETE":              bufbreakingcheck.CheckEnumValueNoDeleteCompatibility,
+		"FIELD_NO_DELETE":                   bufbreakingcheck.CheckFieldNoDeleteCompatibility,
+		"FIELD_NO_DELETE_UNLESS_DEPRECATED": bufbreakingcheck.CheckFieldNoDeleteUnlessDeprecatedCompatibility,
+		"FIELD_SAME_TYPE":                   bufbreakingcheck.CheckFieldSameTypeCompatibility,
+		"FIELD_WIRE_COMPATIBLE_TYPE":        bufbreakingcheck.CheckFieldWireCompatibleTypeCompatibility,
+		"FIELD_WIRE_JSON_COMPATIBLE_TYPE":   bufbreakingcheck.CheckFieldWireJSONCompatibleTypeCompatibility,
+	}
+	// idToCompatibility are the rules whose failures all break the same kinds of compatibility,
+	// but that cannot be classified by their categories.
+	idToCompatibility = map[string]bufbreakingcheck.Compatibility{
+		// Deleted enum values and fields are only wire breaking if their numbers
+		// can be reused, and only JSON breaking if their names can be reused.
+		"ENUM_VALUE_NO_DELETE_UNLESS_NAME_RESERVED":   bufbreakingcheck.CompatibilitySource | bufbreakingcheck.CompatibilityJSON,
+		"ENUM_VALUE_NO_DELETE_UNLESS_NUMBER_RESERVED": bufbreakingcheck.CompatibilitySource | bufbreakingcheck.CompatibilityWire,
+		"FIELD_NO_DELETE_UNLESS_NAME_RESERVED":        bufbreakingcheck.CompatibilitySource | bufbreakingcheck.CompatibilityJSON,
+		"FIELD_NO_DELETE_UNLESS_NUMBER_RESERVED":      bufbreakingcheck.CompatibilitySource | bufbreakingcheck.CompatibilityWire,
+		// Files, packages, enums, messages, and oneofs are not part of the wire or JSON
+		// formats, only the enum values and fields within them are.
+		"FILE_NO_DELETE":                      bufbreakingcheck.CompatibilitySource,
+		"PACKAGE_NO_DELETE":                   bufbreakingcheck.CompatibilitySource,
+		"ENUM_NO_DELETE":                      bufbreakingcheck.CompatibilitySource,
+		"PACKAGE_ENUM_NO_DELETE":              bufbreakingcheck.CompatibilitySource,
+		"MESSAGE_NO_DELETE":                   bufbreakingcheck.CompatibilitySource,
+		"MESSAGE_NO_DELETE_UNLESS_DEPRECATED": bufbreakingcheck.CompatibilitySource,
+		"PACKAGE_MESSAGE_NO_DELETE":           bufbreakingcheck.CompatibilitySource,
+		"ONEOF_NO_DELETE":                     bufbreakingcheck.CompatibilitySource,
+		// Deleted services and RPCs can no longer be called by existing clients.
+		"SERVICE_NO_DELETE":               bufbreakingcheck.CompatibilitySource | bufbreakingcheck.CompatibilityWire | bufbreakingcheck.CompatibilityJSON,
+		"PACKAGE_SERVICE_NO_DELETE":       bufbreakingcheck.CompatibilitySource | bufbreakingcheck.CompatibilityWire | bufbreakingcheck.CompatibilityJSON,
+		"RPC_NO_DELETE":                   bufbreakingcheck.CompatibilitySource | bufbreakingcheck.CompatibilityWire | bufbreakingcheck.CompatibilityJSON,
+		"RPC_NO_DELETE_UNLESS_DEPRECATED": bufbreakingcheck.CompatibilitySource | bufbreakingcheck.CompatibilityWire | bufbreakingcheck.CompatibilityJSON,
+	}
+)
+
+// NewVersionSpec returns a new VersionSpec for the VersionSpec whose rules only
+// report failures that break the given level.
+//
+// The ids and categories are the same as the given VersionSpec. Every failure has
+// its Compatibility appended to its message.
+//
+// Rules that are not classified explicitly are classified by their categories:
+// FILE and PACKAGE rules break source compatibility, WIRE rules break wire
+// compatibility, and WIRE_JSON rules break JSON compatibility.
+func NewVersionSpec(versionSpec *internal.VersionSpec, level bufbreakingcheck.Compatibility) *internal.VersionSpec {
+	ruleBuilders := make([]*internal.RuleBuilder, 0, len(versionSpec.RuleBuilders))
+	for _, ruleBuilder := range versionSpec.RuleBuilders {
+		ruleBuilders = append(ruleBuilders, newRuleBuilder(ruleBuilder, versionSpec.IDToCategories[ruleBuilder.ID()], level))
+	}
+	return &internal.VersionSpec{
+		RuleBuilders:      ruleBuilders,
+		DefaultCategories: versionSpec.DefaultCategories,
+		IDToCategories:    versionSpec.IDToCategories,
+	}
+}
+
+func newRuleBuilder(ruleBuilder *internal.RuleBuilder, categories []string, level bufbreakingcheck.Compatibility) *internal.RuleBuilder {
+	if newCompatibilityCheckFunc, ok := idToCompatibilityCheckFunc[ruleBuilder.ID()]; ok {
+		return ruleBuilder.WithCheckFunc(
+			func(internal.CheckFunc) internal.CheckFunc {
+				return newCompatibilityCheckFunc(level)
+			},
+		)
+	}
+	compatibility, ok := idToCompatibility[ruleBuilder.ID()]
+	if !ok {
+		compatibility = compatibilityForCategories(categories)
+	}
+	return ruleBuilder.WithCheckFunc(
+		func(checkFunc internal.CheckFunc) internal.CheckFunc {
+			return bufbreakingcheck.NewCompatibilityCheckFunc(checkFunc, compatibility, level)
+		},
+	)
+}
+
+func compatibilityForCategories(categories []string) bufbreakingcheck.Compatibility {
+	var compatibility bufbreakingcheck.Compatibility
+	for _, category := range categories {
+		switch category {
+		case "FILE", "PACKAGE":
+			compatibility |= bufbreakingcheck.CompatibilitySource
+		case "WIRE":
+			compatibility |= bufbreakingcheck.CompatibilityWire
+		case "WIRE_JSON":
+			compatibility |= bufbreakingcheck.CompatibilityJSON
+		}
+	}
+	return compatibility
+}
@@ -0,0 +1,61 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreakingcompat
+
+import (
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingv1"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingv1beta1"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal/internaltesting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionSpec(t *testing.T) {
+	t.Parallel()
+	for _, versionSpec := range []*internal.VersionSpec{
+		bufbreakingv1beta1.VersionSpec,
+		bufbreakingv1.VersionSpec,
+	} {
+		for _, level := range []bufbreakingcheck.Compatibility{
+			bufbreakingcheck.CompatibilitySource,
+			bufbreakingcheck.CompatibilityWire,
+			bufbreakingcheck.CompatibilityJSON,
+		} {
+			internaltesting.RunTestVersionSpec(t, NewVersionSpec(versionSpec, level))
+		}
+	}
+}
+
+func TestAllRulesClassified(t *testing.T) {
+	t.Parallel()
+	for _, versionSpec := range []*internal.VersionSpec{
+		bufbreakingv1beta1.VersionSpec,
+		bufbreakingv1.VersionSpec,
+	} {
+		for _, ruleBuilder := range versionSpec.RuleBuilders {
+			id := ruleBuilder.ID()
+			if _, ok := idToCompatibilityCheckFunc[id]; ok {
+				continue
+			}
+			if _, ok := idToCompatibility[id]; ok {
+				continue
+			}
+			assert.NotZero(t, compatibilityForCategories(versionSpec.IDToCategories[id]), "id %q is not classified", id)
+		}
+	}
+}
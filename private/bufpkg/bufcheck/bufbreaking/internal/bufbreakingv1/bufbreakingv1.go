@@ -30,6 +30,9 @@ import "github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 // Splits FIELD_SAME_TYPE into FIELD_SAME_TYPE for FILE AND PACKAGE,
 // FIRE_WIRE_JSON_COMPATIBLE_TYPE for WIRE_JSON, and
 // FIELD_WIRE_COMPATIBLE_TYPE for WIRE.
+//
+// Adds FIELD_NO_DELETE_UNLESS_DEPRECATED, MESSAGE_NO_DELETE_UNLESS_DEPRECATED,
+// and RPC_NO_DELETE_UNLESS_DEPRECATED to DEPRECATION, which is not a default category.
 var VersionSpec = &internal.VersionSpec{
 	RuleBuilders:      v1RuleBuilders,
 	DefaultCategories: v1DefaultCategories,
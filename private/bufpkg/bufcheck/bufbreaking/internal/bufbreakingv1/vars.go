@@ -29,6 +29,7 @@ var (
 		bufbreakingbuild.EnumValueSameNameRuleBuilder,
 		bufbreakingbuild.ExtensionMessageNoDeleteRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteRuleBuilder,
+		bufbreakingbuild.FieldNoDeleteUnlessDeprecatedRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteUnlessNameReservedRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteUnlessNumberReservedRuleBuilder,
 		bufbreakingbuild.FieldSameCTypeRuleBuilder,
@@ -62,6 +63,7 @@ var (
 		bufbreakingbuild.FileSameCcEnableArenasRuleBuilder,
 		bufbreakingbuild.FileSameSyntaxRuleBuilder,
 		bufbreakingbuild.MessageNoDeleteRuleBuilder,
+		bufbreakingbuild.MessageNoDeleteUnlessDeprecatedRuleBuilder,
 		bufbreakingbuild.MessageNoRemoveStandardDescriptorAccessorRuleBuilder,
 		bufbreakingbuild.MessageSameMessageSetWireFormatRuleBuilder,
 		bufbreakingbuild.MessageSameRequiredFieldsRuleBuilder,
@@ -73,6 +75,7 @@ var (
 		bufbreakingbuild.ReservedEnumNoDeleteRuleBuilder,
 		bufbreakingbuild.ReservedMessageNoDeleteRuleBuilder,
 		bufbreakingbuild.RPCNoDeleteRuleBuilder,
+		bufbreakingbuild.RPCNoDeleteUnlessDeprecatedRuleBuilder,
 		bufbreakingbuild.RPCSameClientStreamingRuleBuilder,
 		bufbreakingbuild.RPCSameIdempotencyLevelRuleBuilder,
 		bufbreakingbuild.RPCSameRequestTypeRuleBuilder,
@@ -114,6 +117,9 @@ var (
 			"FILE",
 			"PACKAGE",
 		},
+		"FIELD_NO_DELETE_UNLESS_DEPRECATED": {
+			"DEPRECATION",
+		},
 		"FIELD_NO_DELETE_UNLESS_NAME_RESERVED": {
 			"WIRE_JSON",
 		},
@@ -249,6 +255,9 @@ var (
 		"MESSAGE_NO_DELETE": {
 			"FILE",
 		},
+		"MESSAGE_NO_DELETE_UNLESS_DEPRECATED": {
+			"DEPRECATION",
+		},
 		"MESSAGE_NO_REMOVE_STANDARD_DESCRIPTOR_ACCESSOR": {
 			"FILE",
 			"PACKAGE",
@@ -297,6 +306,9 @@ var (
 			"FILE",
 			"PACKAGE",
 		},
+		"RPC_NO_DELETE_UNLESS_DEPRECATED": {
+			"DEPRECATION",
+		},
 		"RPC_SAME_CLIENT_STREAMING": {
 			"FILE",
 			"PACKAGE",
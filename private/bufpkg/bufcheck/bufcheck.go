@@ -26,6 +26,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"go.uber.org/multierr"
 )
 
@@ -55,6 +56,17 @@ type Rule interface {
 	Purpose() string
 }
 
+// AnalysisRulesForRules converts the Rules to bufanalysis.Rules.
+//
+// This is used to attach rule metadata when printing FileAnnotations.
+func AnalysisRulesForRules(rules []Rule) []bufanalysis.Rule {
+	analysisRules := make([]bufanalysis.Rule, len(rules))
+	for i, rule := range rules {
+		analysisRules[i] = rule
+	}
+	return analysisRules
+}
+
 // PrintRules prints the rules to the writer.
 //
 // The empty string defaults to text.
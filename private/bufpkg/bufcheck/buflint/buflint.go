@@ -23,11 +23,13 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/internal/buflintv1"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/internal/buflintv1beta1"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"go.uber.org/zap"
 )
 
@@ -43,23 +45,115 @@ type Handler interface {
 	//
 	// The image should have source code info for this to work properly.
 	//
-	// Images should be filtered with regards to imports before passing to this function.
+	// Imports are not checked. Imports are passed to lint plugins, so the image
+	// should include imports if the configuration has lint plugins.
 	Check(
 		ctx context.Context,
 		config *buflintconfig.Config,
 		image bufimage.Image,
+		options ...CheckOption,
 	) ([]bufanalysis.FileAnnotation, error)
+	// Baseline runs the lint checks and returns the BaselineEntries for the
+	// FileAnnotations that Check would return.
+	//
+	// FileAnnotations that are not associated with a file are not recorded.
+	// The returned BaselineEntries are deduplicated and sorted.
+	//
+	// The image should have source code info for this to work properly.
+	//
+	// Imports are not checked.
+	Baseline(
+		ctx context.Context,
+		config *buflintconfig.Config,
+		image bufimage.Image,
+	) ([]*buflintconfig.BaselineEntry, error)
+	// Fix runs the lint checks and fixes the FileAnnotations that Check would
+	// return that can be fixed mechanically.
+	//
+	// Only the following rules can be fixed:
+	//
+	//   ENUM_VALUE_PREFIX
+	//   ENUM_VALUE_UPPER_SNAKE_CASE
+	//   ENUM_ZERO_VALUE_SUFFIX
+	//   FIELD_LOWER_SNAKE_CASE
+	//   IMPORT_USED
+	//
+	// Renames also update all references within the files in pathToData. Renames that
+	// would conflict with existing elements, or that cannot be safely applied to all
+	// references, are skipped.
+	//
+	// The image should have source code info for this to work properly.
+	//
+	// Unlike Check, the image should include imports. Only non-import files are
+	// checked, and only files in pathToData are fixed. pathToData should contain
+	// the current content of all files of the module, keyed by path, including the
+	// files that are imports because they are not targeted, so that references
+	// within them are also updated and the module still compiles.
+	//
+	// Only files that were changed are returned, sorted by path.
+	Fix(
+		ctx context.Context,
+		config *buflintconfig.Config,
+		image bufimage.Image,
+		pathToData map[string][]byte,
+		options ...CheckOption,
+	) ([]*FileFix, error)
+}
+
+// FileFix is the result of fixing a single file.
+type FileFix struct {
+	// Path is the path of the file.
+	Path string
+	// ExternalPath is the external path of the file.
+	ExternalPath string
+	// Data is the new content of the file.
+	Data []byte
+	// FileAnnotations describe the fixes that were applied.
+	//
+	// These use the locations within the original content of the file.
+	FileAnnotations []bufanalysis.FileAnnotation
 }
 
 // NewHandler returns a new Handler.
-func NewHandler(logger *zap.Logger) Handler {
-	return newHandler(logger)
+func NewHandler(logger *zap.Logger, options ...HandlerOption) Handler {
+	return newHandler(logger, options...)
+}
+
+// HandlerOption is an option for a new Handler.
+type HandlerOption func(*handlerOptions)
+
+// HandlerWithPluginExecutor returns a new HandlerOption that uses the given
+// Executor to execute the lint plugins in the configuration.
+//
+// If this is not set, configurations with lint plugins result in an error.
+func HandlerWithPluginExecutor(pluginExecutor buflintplugin.Executor) HandlerOption {
+	return func(handlerOptions *handlerOptions) {
+		handlerOptions.pluginExecutor = pluginExecutor
+	}
+}
+
+// CheckOption is an option for Check.
+type CheckOption func(*checkOptions)
+
+// CheckWithBaselineEntries returns a new CheckOption that suppresses the
+// FileAnnotations recorded in the given BaselineEntries.
+func CheckWithBaselineEntries(baselineEntries ...*buflintconfig.BaselineEntry) CheckOption {
+	return func(checkOptions *checkOptions) {
+		checkOptions.baselineEntries = append(checkOptions.baselineEntries, baselineEntries...)
+	}
 }
 
 // RulesForConfig returns the rules for a given config.
 //
+// Rules provided by lint plugins are not returned, as this would require
+// executing the plugins.
+//
 // Should only be used for printing.
 func RulesForConfig(config *buflintconfig.Config) ([]bufcheck.Rule, error) {
+	config, ok := configWithoutPlugins(config)
+	if !ok {
+		return nil, nil
+	}
 	internalConfig, err := internalConfigForConfig(config)
 	if err != nil {
 		return nil, err
@@ -113,13 +207,14 @@ func GetAllRulesAndCategoriesV1() []string {
 	return internal.AllCategoriesAndIDsForVersionSpec(buflintv1.VersionSpec)
 }
 
-func internalConfigForConfig(config *buflintconfig.Config) (*internal.Config, error) {
-	var versionSpec *internal.VersionSpec
-	switch config.Version {
-	case bufconfig.V1Beta1Version:
-		versionSpec = buflintv1beta1.VersionSpec
-	case bufconfig.V1Version:
-		versionSpec = buflintv1.VersionSpec
+func internalConfigForConfig(config *buflintconfig.Config, pluginRules ...*pluginRule) (*internal.Config, error) {
+	versionSpec := versionSpecForConfig(config)
+	if len(pluginRules) > 0 {
+		var err error
+		versionSpec, err = versionSpecWithPluginRules(versionSpec, pluginRules)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return internal.ConfigBuilder{
 		Use:                                  config.Use,
@@ -137,6 +232,94 @@ func internalConfigForConfig(config *buflintconfig.Config) (*internal.Config, er
 	)
 }
 
+func versionSpecForConfig(config *buflintconfig.Config) *internal.VersionSpec {
+	switch config.Version {
+	case bufconfig.V1Beta1Version:
+		return buflintv1beta1.VersionSpec
+	case bufconfig.V1Version:
+		return buflintv1.VersionSpec
+	default:
+		return nil
+	}
+}
+
+// configWithoutPlugins returns a copy of the config without the lint plugins, and
+// without the IDs and categories that are not built-in.
+//
+// Returns false if Use only contains IDs and categories that are not built-in, that
+// is if no built-in rules would be used.
+func configWithoutPlugins(config *buflintconfig.Config) (*buflintconfig.Config, bool) {
+	if len(config.Plugins) == 0 {
+		return config, true
+	}
+	versionSpec := versionSpecForConfig(config)
+	if versionSpec == nil {
+		return config, true
+	}
+	builtinIDsAndCategories := stringutil.SliceToMap(internal.AllCategoriesAndIDsForVersionSpec(versionSpec))
+	filter := func(idsOrCategories []string) []string {
+		var filtered []string
+		for _, idOrCategory := range idsOrCategories {
+			if _, ok := builtinIDsAndCategories[idOrCategory]; ok {
+				filtered = append(filtered, idOrCategory)
+			}
+		}
+		return filtered
+	}
+	configCopy := *config
+	configCopy.Plugins = nil
+	configCopy.Use = filter(config.Use)
+	configCopy.Except = filter(config.Except)
+	if len(config.Use) > 0 && len(configCopy.Use) == 0 {
+		return nil, false
+	}
+	configCopy.IgnoreIDOrCategoryToRootPaths = make(map[string][]string, len(config.IgnoreIDOrCategoryToRootPaths))
+	for idOrCategory, rootPaths := range config.IgnoreIDOrCategoryToRootPaths {
+		if _, ok := builtinIDsAndCategories[idOrCategory]; ok {
+			configCopy.IgnoreIDOrCategoryToRootPaths[idOrCategory] = rootPaths
+		}
+	}
+	return &configCopy, true
+}
+
+func internalConfigForConfigAndCheckOptions(
+	config *buflintconfig.Config,
+	checkOptions *checkOptions,
+	pluginRules ...*pluginRule,
+) (*internal.Config, error) {
+	internalConfig, err := internalConfigForConfig(config, pluginRules...)
+	if err != nil {
+		return nil, err
+	}
+	if len(checkOptions.baselineEntries) > 0 {
+		internalConfig.IgnoreBaselineKeys = make(map[internal.BaselineKey]struct{}, len(checkOptions.baselineEntries))
+		for _, baselineEntry := range checkOptions.baselineEntries {
+			internalConfig.IgnoreBaselineKeys[internal.BaselineKey{
+				ID:          baselineEntry.ID,
+				Path:        baselineEntry.Path,
+				ElementName: baselineEntry.ElementName,
+			}] = struct{}{}
+		}
+	}
+	return internalConfig, nil
+}
+
+type handlerOptions struct {
+	pluginExecutor buflintplugin.Executor
+}
+
+func newHandlerOptions() *handlerOptions {
+	return &handlerOptions{}
+}
+
+type checkOptions struct {
+	baselineEntries []*buflintconfig.BaselineEntry
+}
+
+func newCheckOptions() *checkOptions {
+	return &checkOptions{}
+}
+
 func rulesForInternalRules(rules []*internal.Rule) []bufcheck.Rule {
 	if rules == nil {
 		return nil
@@ -16,18 +16,25 @@ package buflint_test
 
 import (
 	"context"
+	"io"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis/bufanalysistesting"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagebuild"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/protosource"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/stretchr/testify/assert"
@@ -883,6 +890,27 @@ func TestCommentIgnoresCascadeOn(t *testing.T) {
 	)
 }
 
+func TestRunPlugin(t *testing.T) {
+	testLintConfigModifierHandlerOptions(
+		t,
+		"plugin",
+		nil,
+		[]buflint.HandlerOption{
+			buflint.HandlerWithPluginExecutor(
+				buflintplugin.NewExecutor(
+					zap.NewNop(),
+					app.NewContainer(nil, nil, nil, io.Discard),
+					func(pluginConfig *buflintconfig.PluginConfig) (appproto.Handler, error) {
+						return buflintplugin.NewHandler(testPlugin{}), nil
+					},
+				),
+			),
+		},
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 9, 5, 16, "SERVICE_OWNER"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 14, 3, 14, 20, "MONEY_NO_FLOAT"),
+	)
+}
+
 func testLint(
 	t *testing.T,
 	relDirPath string,
@@ -901,6 +929,22 @@ func testLintConfigModifier(
 	relDirPath string,
 	configModifier func(*bufconfig.Config),
 	expectedFileAnnotations ...bufanalysis.FileAnnotation,
+) {
+	testLintConfigModifierHandlerOptions(
+		t,
+		relDirPath,
+		configModifier,
+		nil,
+		expectedFileAnnotations...,
+	)
+}
+
+func testLintConfigModifierHandlerOptions(
+	t *testing.T,
+	relDirPath string,
+	configModifier func(*bufconfig.Config),
+	handlerOptions []buflint.HandlerOption,
+	expectedFileAnnotations ...bufanalysis.FileAnnotation,
 ) {
 	t.Parallel()
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -943,7 +987,7 @@ func testLintConfigModifier(
 	require.Empty(t, fileAnnotations)
 	image = bufimage.ImageWithoutImports(image)
 
-	handler := buflint.NewHandler(logger)
+	handler := buflint.NewHandler(logger, handlerOptions...)
 	fileAnnotations, err = handler.Check(
 		ctx,
 		config.Lint,
@@ -967,3 +1011,83 @@ func testGetConfig(
 	require.NoError(t, err)
 	return config
 }
+
+// testPlugin is a lint plugin that checks that services have an owner in their
+// comments, and that no fields use float.
+type testPlugin struct{}
+
+func (testPlugin) Rules() []*buflintplugin.Rule {
+	return []*buflintplugin.Rule{
+		{
+			ID:         "SERVICE_OWNER",
+			Categories: []string{"COMPANY"},
+			Purpose:    "all services have an owner",
+		},
+		{
+			ID:         "MONEY_NO_FLOAT",
+			Categories: []string{"COMPANY"},
+			Purpose:    "no fields use float",
+		},
+		{
+			ID:      "UNUSED",
+			Purpose: "nothing",
+		},
+	}
+}
+
+func (testPlugin) Check(ctx context.Context, request *buflintplugin.Request) ([]*buflintplugin.FileAnnotation, error) {
+	var fileAnnotations []*buflintplugin.FileAnnotation
+	for _, file := range request.Files {
+		// this rule is not used, so this should be filtered out
+		fileAnnotations = append(
+			fileAnnotations,
+			&buflintplugin.FileAnnotation{
+				Path:    file.Path(),
+				Type:    "UNUSED",
+				Message: "Unused.",
+			},
+		)
+		for _, service := range file.Services() {
+			if !strings.Contains(service.Location().LeadingComments(), request.Opt) {
+				fileAnnotations = append(
+					fileAnnotations,
+					newTestPluginFileAnnotation(file, service.NameLocation(), "SERVICE_OWNER", "Service has no owner."),
+				)
+			}
+		}
+		if err := protosource.ForEachMessage(
+			func(message protosource.Message) error {
+				for _, field := range message.Fields() {
+					if field.Type() == protosource.FieldDescriptorProtoTypeFloat {
+						fileAnnotations = append(
+							fileAnnotations,
+							newTestPluginFileAnnotation(file, field.Location(), "MONEY_NO_FLOAT", "Field uses float."),
+						)
+					}
+				}
+				return nil
+			},
+			file,
+		); err != nil {
+			return nil, err
+		}
+	}
+	return fileAnnotations, nil
+}
+
+func newTestPluginFileAnnotation(
+	file protosource.File,
+	location protosource.Location,
+	id string,
+	message string,
+) *buflintplugin.FileAnnotation {
+	return &buflintplugin.FileAnnotation{
+		Path:        file.Path(),
+		StartLine:   location.StartLine(),
+		StartColumn: location.StartColumn(),
+		EndLine:     location.EndLine(),
+		EndColumn:   location.EndColumn(),
+		Type:        id,
+		Message:     message,
+	}
+}
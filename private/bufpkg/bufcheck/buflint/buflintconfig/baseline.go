@@ -0,0 +1,120 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflintconfig
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/bufbuild/buf/private/pkg/encoding"
+)
+
+// BaselineEntry is an entry in a lint baseline.
+//
+// Entries do not contain line or column information, so that they continue
+// to match FileAnnotations when lines within a file shift.
+type BaselineEntry struct {
+	// ID is the rule ID.
+	ID string
+	// Path is the path of the file relative to the root of the module.
+	Path string
+	// ElementName is the fully-qualified name of the element the FileAnnotation
+	// was produced for, or the import path for imports.
+	//
+	// This is empty for file-level FileAnnotations.
+	ElementName string
+}
+
+// SortBaselineEntries sorts the BaselineEntries by path, then element name, then ID.
+func SortBaselineEntries(baselineEntries []*BaselineEntry) {
+	sort.Slice(
+		baselineEntries,
+		func(i int, j int) bool {
+			one := baselineEntries[i]
+			two := baselineEntries[j]
+			if one.Path != two.Path {
+				return one.Path < two.Path
+			}
+			if one.ElementName != two.ElementName {
+				return one.ElementName < two.ElementName
+			}
+			return one.ID < two.ID
+		},
+	)
+}
+
+// ReadBaseline reads the BaselineEntries from the data of a baseline file.
+func ReadBaseline(data []byte) ([]*BaselineEntry, error) {
+	var externalBaseline externalBaselineV1
+	if err := encoding.UnmarshalYAMLStrict(data, &externalBaseline); err != nil {
+		return nil, fmt.Errorf("could not read baseline: %w", err)
+	}
+	if externalBaseline.Version != v1Version {
+		return nil, fmt.Errorf("baseline has an invalid version %q, expected %q", externalBaseline.Version, v1Version)
+	}
+	baselineEntries := make([]*BaselineEntry, 0, len(externalBaseline.Entries))
+	for _, externalBaselineEntry := range externalBaseline.Entries {
+		if externalBaselineEntry.ID == "" {
+			return nil, fmt.Errorf("baseline entry for %q has no id", externalBaselineEntry.Path)
+		}
+		if externalBaselineEntry.Path == "" {
+			return nil, fmt.Errorf("baseline entry for %q has no path", externalBaselineEntry.ID)
+		}
+		baselineEntries = append(
+			baselineEntries,
+			&BaselineEntry{
+				ID:          externalBaselineEntry.ID,
+				Path:        externalBaselineEntry.Path,
+				ElementName: externalBaselineEntry.Element,
+			},
+		)
+	}
+	return baselineEntries, nil
+}
+
+// WriteBaseline writes the BaselineEntries as a baseline file to the Writer.
+//
+// The BaselineEntries are written in the order given.
+func WriteBaseline(writer io.Writer, baselineEntries []*BaselineEntry) error {
+	externalBaseline := externalBaselineV1{
+		Version: v1Version,
+		Entries: make([]externalBaselineEntryV1, len(baselineEntries)),
+	}
+	for i, baselineEntry := range baselineEntries {
+		externalBaseline.Entries[i] = externalBaselineEntryV1{
+			ID:      baselineEntry.ID,
+			Path:    baselineEntry.Path,
+			Element: baselineEntry.ElementName,
+		}
+	}
+	data, err := encoding.MarshalYAML(&externalBaseline)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+type externalBaselineV1 struct {
+	Version string                    `json:"version,omitempty" yaml:"version,omitempty"`
+	Entries []externalBaselineEntryV1 `json:"entries,omitempty" yaml:"entries,omitempty"`
+}
+
+type externalBaselineEntryV1 struct {
+	ID      string `json:"id,omitempty" yaml:"id,omitempty"`
+	Path    string `json:"path,omitempty" yaml:"path,omitempty"`
+	Element string `json:"element,omitempty" yaml:"element,omitempty"`
+}
@@ -59,10 +59,29 @@ type Config struct {
 	ServiceSuffix string
 	// AllowCommentIgnores turns on comment-driven ignores.
 	AllowCommentIgnores bool
+	// Baseline is the path to a baseline file written by buf lint --write-baseline.
+	// FileAnnotations recorded in the baseline are suppressed.
+	//
+	// Relative paths are relative to the directory of the configuration file.
+	// The path is relative to the current working directory.
+	Baseline string
+	// Plugins are the lint plugins that provide additional rules.
+	//
+	// The rules of plugins can be used in Use, Except, IgnoreIDOrCategoryToRootPaths,
+	// and comment ignores in the same way as built-in rules.
+	Plugins []*PluginConfig
 	// Version represents the version of the lint rule and category IDs that should be used with this config.
 	Version string
 }
 
+// PluginConfig is the configuration for a lint plugin.
+type PluginConfig struct {
+	// Plugin is the name of the plugin binary on the PATH, or the path to the plugin binary.
+	Plugin string
+	// Opt is the option string passed to the plugin.
+	Opt string
+}
+
 // NewConfigV1Beta1 returns a new Config.
 func NewConfigV1Beta1(externalConfig ExternalConfigV1Beta1) *Config {
 	return &Config{
@@ -76,6 +95,8 @@ func NewConfigV1Beta1(externalConfig ExternalConfigV1Beta1) *Config {
 		RPCAllowGoogleProtobufEmptyResponses: externalConfig.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        externalConfig.ServiceSuffix,
 		AllowCommentIgnores:                  externalConfig.AllowCommentIgnores,
+		Baseline:                             externalConfig.Baseline,
+		Plugins:                              pluginConfigsForExternalPluginConfigs(externalConfig.Plugins),
 		Version:                              v1Beta1Version,
 	}
 }
@@ -93,6 +114,8 @@ func NewConfigV1(externalConfig ExternalConfigV1) *Config {
 		RPCAllowGoogleProtobufEmptyResponses: externalConfig.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        externalConfig.ServiceSuffix,
 		AllowCommentIgnores:                  externalConfig.AllowCommentIgnores,
+		Baseline:                             externalConfig.Baseline,
+		Plugins:                              pluginConfigsForExternalPluginConfigs(externalConfig.Plugins),
 		Version:                              v1Version,
 	}
 }
@@ -138,13 +161,15 @@ type ExternalConfigV1Beta1 struct {
 	// IgnoreRootPaths
 	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
 	// IgnoreIDOrCategoryToRootPaths
-	IgnoreOnly                           map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
-	EnumZeroValueSuffix                  string              `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
-	RPCAllowSameRequestResponse          bool                `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
-	RPCAllowGoogleProtobufEmptyRequests  bool                `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
-	RPCAllowGoogleProtobufEmptyResponses bool                `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
-	ServiceSuffix                        string              `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
-	AllowCommentIgnores                  bool                `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	IgnoreOnly                           map[string][]string    `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
+	EnumZeroValueSuffix                  string                 `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
+	RPCAllowSameRequestResponse          bool                   `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
+	RPCAllowGoogleProtobufEmptyRequests  bool                   `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
+	RPCAllowGoogleProtobufEmptyResponses bool                   `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
+	ServiceSuffix                        string                 `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
+	AllowCommentIgnores                  bool                   `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	Baseline                             string                 `json:"baseline,omitempty" yaml:"baseline,omitempty"`
+	Plugins                              []ExternalPluginConfig `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
 // ExternalConfigV1 is an external config.
@@ -154,13 +179,15 @@ type ExternalConfigV1 struct {
 	// IgnoreRootPaths
 	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
 	// IgnoreIDOrCategoryToRootPaths
-	IgnoreOnly                           map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
-	EnumZeroValueSuffix                  string              `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
-	RPCAllowSameRequestResponse          bool                `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
-	RPCAllowGoogleProtobufEmptyRequests  bool                `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
-	RPCAllowGoogleProtobufEmptyResponses bool                `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
-	ServiceSuffix                        string              `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
-	AllowCommentIgnores                  bool                `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	IgnoreOnly                           map[string][]string    `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
+	EnumZeroValueSuffix                  string                 `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
+	RPCAllowSameRequestResponse          bool                   `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
+	RPCAllowGoogleProtobufEmptyRequests  bool                   `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
+	RPCAllowGoogleProtobufEmptyResponses bool                   `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
+	ServiceSuffix                        string                 `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
+	AllowCommentIgnores                  bool                   `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	Baseline                             string                 `json:"baseline,omitempty" yaml:"baseline,omitempty"`
+	Plugins                              []ExternalPluginConfig `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
 // ExternalConfigV1Beta1ForConfig takes a *Config and returns the v1beta1 externalconfig representation.
@@ -176,6 +203,8 @@ func ExternalConfigV1Beta1ForConfig(config *Config) ExternalConfigV1Beta1 {
 		RPCAllowGoogleProtobufEmptyResponses: config.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        config.ServiceSuffix,
 		AllowCommentIgnores:                  config.AllowCommentIgnores,
+		Baseline:                             config.Baseline,
+		Plugins:                              externalPluginConfigsForPluginConfigs(config.Plugins),
 	}
 }
 
@@ -192,9 +221,17 @@ func ExternalConfigV1ForConfig(config *Config) ExternalConfigV1 {
 		RPCAllowGoogleProtobufEmptyResponses: config.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        config.ServiceSuffix,
 		AllowCommentIgnores:                  config.AllowCommentIgnores,
+		Baseline:                             config.Baseline,
+		Plugins:                              externalPluginConfigsForPluginConfigs(config.Plugins),
 	}
 }
 
+// ExternalPluginConfig is an external lint plugin config.
+type ExternalPluginConfig struct {
+	Plugin string `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+	Opt    string `json:"opt,omitempty" yaml:"opt,omitempty"`
+}
+
 // BytesForConfig takes a *Config and returns the deterministic []byte representation.
 // We use an unexported intermediary JSON form and sort all fields to ensure that the bytes
 // associated with the *Config are deterministic.
@@ -216,9 +253,16 @@ type configJSON struct {
 	RPCAllowGoogleProtobufEmptyResponses bool          `json:"rpc_allow_google_protobuf_empty_response,omitempty"`
 	ServiceSuffix                        string        `json:"service_suffix,omitempty"`
 	AllowCommentIgnores                  bool          `json:"allow_comment_ignores,omitempty"`
+	Baseline                             string        `json:"baseline,omitempty"`
+	Plugins                              []pluginJSON  `json:"plugins,omitempty"`
 	Version                              string        `json:"version,omitempty"`
 }
 
+type pluginJSON struct {
+	Plugin string `json:"plugin,omitempty"`
+	Opt    string `json:"opt,omitempty"`
+}
+
 type idPathsJSON struct {
 	ID    string   `json:"id,omitempty"`
 	Paths []string `json:"paths,omitempty"`
@@ -237,6 +281,14 @@ func configToJSON(config *Config) *configJSON {
 	sort.Strings(config.Use)
 	sort.Strings(config.Except)
 	sort.Strings(config.IgnoreRootPaths)
+	// plugins are not sorted as the order of plugins is significant
+	pluginsJSON := make([]pluginJSON, len(config.Plugins))
+	for i, pluginConfig := range config.Plugins {
+		pluginsJSON[i] = pluginJSON{
+			Plugin: pluginConfig.Plugin,
+			Opt:    pluginConfig.Opt,
+		}
+	}
 	return &configJSON{
 		Use:                                  config.Use,
 		Except:                               config.Except,
@@ -248,6 +300,8 @@ func configToJSON(config *Config) *configJSON {
 		RPCAllowGoogleProtobufEmptyResponses: config.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        config.ServiceSuffix,
 		AllowCommentIgnores:                  config.AllowCommentIgnores,
+		Baseline:                             config.Baseline,
+		Plugins:                              pluginsJSON,
 		Version:                              config.Version,
 	}
 }
@@ -259,12 +313,13 @@ func PrintFileAnnotations(
 	writer io.Writer,
 	fileAnnotations []bufanalysis.FileAnnotation,
 	formatString string,
+	options ...bufanalysis.PrintFileAnnotationsOption,
 ) error {
 	switch s := strings.ToLower(strings.TrimSpace(formatString)); s {
 	case "config-ignore-yaml":
 		return printFileAnnotationsConfigIgnoreYAML(writer, fileAnnotations)
 	default:
-		return bufanalysis.PrintFileAnnotations(writer, fileAnnotations, s)
+		return bufanalysis.PrintFileAnnotations(writer, fileAnnotations, s, options...)
 	}
 }
 
@@ -324,6 +379,34 @@ lint:
 	return err
 }
 
+func pluginConfigsForExternalPluginConfigs(externalPluginConfigs []ExternalPluginConfig) []*PluginConfig {
+	if len(externalPluginConfigs) == 0 {
+		return nil
+	}
+	pluginConfigs := make([]*PluginConfig, len(externalPluginConfigs))
+	for i, externalPluginConfig := range externalPluginConfigs {
+		pluginConfigs[i] = &PluginConfig{
+			Plugin: externalPluginConfig.Plugin,
+			Opt:    externalPluginConfig.Opt,
+		}
+	}
+	return pluginConfigs
+}
+
+func externalPluginConfigsForPluginConfigs(pluginConfigs []*PluginConfig) []ExternalPluginConfig {
+	if len(pluginConfigs) == 0 {
+		return nil
+	}
+	externalPluginConfigs := make([]ExternalPluginConfig, len(pluginConfigs))
+	for i, pluginConfig := range pluginConfigs {
+		externalPluginConfigs[i] = ExternalPluginConfig{
+			Plugin: pluginConfig.Plugin,
+			Opt:    pluginConfig.Opt,
+		}
+	}
+	return externalPluginConfigs
+}
+
 func ignoreIDOrCategoryToRootPathsForProto(protoIgnoreIDPaths []*lintv1.IDPaths) map[string][]string {
 	if protoIgnoreIDPaths == nil {
 		return nil
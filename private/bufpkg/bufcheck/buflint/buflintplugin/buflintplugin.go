@@ -0,0 +1,154 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package buflintplugin implements lint plugins, which provide additional
+// lint rules to buf lint.
+//
+// Lint plugins use the protoc plugin protocol. The plugin is sent a
+// CodeGeneratorRequest with the files to lint as the files to generate,
+// and the plugin option string as the parameter. The plugin responds with a
+// CodeGeneratorResponse with a single file named by ResponseFileName, that
+// contains the JSON representation of a Response. The rules of the plugin
+// are returned in the same Response as the FileAnnotations, and buf filters
+// the FileAnnotations based on the lint configuration.
+package buflintplugin
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"go.uber.org/zap"
+)
+
+// ResponseFileName is the name of the file in the CodeGeneratorResponse that
+// contains the JSON representation of the Response.
+const ResponseFileName = "buf-lint-plugin-response.json"
+
+// Rule is a rule provided by a plugin.
+type Rule struct {
+	// ID is the ID of the rule, in UPPER_SNAKE_CASE.
+	//
+	// Must not be the ID of a built-in rule or of a rule from another plugin.
+	ID string `json:"id,omitempty"`
+	// Categories are the categories of the rule.
+	//
+	// These can be new categories, or built-in categories such as DEFAULT.
+	Categories []string `json:"categories,omitempty"`
+	// Purpose is the purpose of the rule.
+	//
+	// This will have "Checks that " prepended and "." appended, for example
+	// "all services have an owner".
+	Purpose string `json:"purpose,omitempty"`
+}
+
+// FileAnnotation is a check violation reported by a plugin.
+//
+// This matches the JSON format of FileAnnotations printed by buf lint.
+type FileAnnotation struct {
+	// Path is the path of the file relative to the root of its module.
+	//
+	// Must be the path of one of the files to generate.
+	Path string `json:"path,omitempty"`
+	// StartLine is the 1-indexed start line, or 0 if the whole file.
+	StartLine int `json:"start_line,omitempty"`
+	// StartColumn is the 1-indexed start column.
+	StartColumn int `json:"start_column,omitempty"`
+	// EndLine is the 1-indexed end line.
+	EndLine int `json:"end_line,omitempty"`
+	// EndColumn is the 1-indexed end column.
+	EndColumn int `json:"end_column,omitempty"`
+	// Type is the ID of the rule that failed.
+	//
+	// Must be the ID of one of the rules of the plugin.
+	Type string `json:"type,omitempty"`
+	// Message is the message that describes the violation.
+	Message string `json:"message,omitempty"`
+}
+
+// Response is the response of a plugin.
+type Response struct {
+	Rules           []*Rule           `json:"rules,omitempty"`
+	FileAnnotations []*FileAnnotation `json:"file_annotations,omitempty"`
+}
+
+// Request is a request to a Plugin.
+type Request struct {
+	// Image is the image that is linted, including imports.
+	Image bufimage.Image
+	// Files are the files to lint, that is the non-import files of the image.
+	Files []protosource.File
+	// Opt is the option string configured for the plugin.
+	Opt string
+}
+
+// Plugin is a lint plugin.
+type Plugin interface {
+	// Rules returns the rules of the plugin.
+	Rules() []*Rule
+	// Check runs all the rules of the plugin.
+	//
+	// Comment ignores and ignore paths are applied by buf, and should not be
+	// applied by the plugin.
+	Check(ctx context.Context, request *Request) ([]*FileAnnotation, error)
+}
+
+// Main runs the Plugin as a protoc plugin.
+func Main(ctx context.Context, plugin Plugin) {
+	appproto.Main(ctx, NewHandler(plugin))
+}
+
+// NewHandler returns a new appproto.Handler for the Plugin.
+func NewHandler(plugin Plugin) appproto.Handler {
+	return newHandler(plugin)
+}
+
+// Executor executes plugins.
+type Executor interface {
+	// Execute executes the plugin for the image, and returns the validated response.
+	//
+	// The image should include imports. The non-import files are linted.
+	Execute(
+		ctx context.Context,
+		pluginConfig *buflintconfig.PluginConfig,
+		image bufimage.Image,
+	) (*Response, error)
+}
+
+// NewExecutor returns a new Executor.
+func NewExecutor(
+	logger *zap.Logger,
+	container app.EnvStderrContainer,
+	handlerProvider HandlerProvider,
+) Executor {
+	return newExecutor(logger, container, handlerProvider)
+}
+
+// HandlerProvider provides appproto.Handlers for plugins.
+type HandlerProvider func(pluginConfig *buflintconfig.PluginConfig) (appproto.Handler, error)
+
+// NewBinaryHandlerProvider returns a new HandlerProvider that executes the
+// plugin binaries on the local filesystem.
+func NewBinaryHandlerProvider(
+	logger *zap.Logger,
+	storageosProvider storageos.Provider,
+	runner command.Runner,
+) HandlerProvider {
+	return newBinaryHandlerProvider(logger, storageosProvider, runner)
+}
@@ -0,0 +1,152 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflintplugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/app/appproto/appprotoexec"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+type executor struct {
+	logger          *zap.Logger
+	container       app.EnvStderrContainer
+	handlerProvider HandlerProvider
+}
+
+func newExecutor(
+	logger *zap.Logger,
+	container app.EnvStderrContainer,
+	handlerProvider HandlerProvider,
+) *executor {
+	return &executor{
+		logger:          logger.Named("buflintplugin"),
+		container:       container,
+		handlerProvider: handlerProvider,
+	}
+}
+
+func (e *executor) Execute(
+	ctx context.Context,
+	pluginConfig *buflintconfig.PluginConfig,
+	image bufimage.Image,
+) (*Response, error) {
+	response, err := e.execute(ctx, pluginConfig, image)
+	if err != nil {
+		return nil, fmt.Errorf("lint plugin %q: %w", pluginConfig.Plugin, err)
+	}
+	return response, nil
+}
+
+func (e *executor) execute(
+	ctx context.Context,
+	pluginConfig *buflintconfig.PluginConfig,
+	image bufimage.Image,
+) (*Response, error) {
+	handler, err := e.handlerProvider(pluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	codeGeneratorResponse, err := appproto.NewGenerator(e.logger, handler).Generate(
+		ctx,
+		e.container,
+		[]*pluginpb.CodeGeneratorRequest{
+			bufimage.ImageToCodeGeneratorRequest(
+				image,
+				pluginConfig.Opt,
+				nil,
+				false,
+				false,
+			),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	for _, file := range codeGeneratorResponse.GetFile() {
+		if file.GetName() != ResponseFileName {
+			return nil, fmt.Errorf("unexpected file %q in response", file.GetName())
+		}
+		if data != nil {
+			return nil, fmt.Errorf("duplicate file %q in response", ResponseFileName)
+		}
+		data = []byte(file.GetContent())
+	}
+	if data == nil {
+		return nil, fmt.Errorf("no file %q in response", ResponseFileName)
+	}
+	response := &Response{}
+	if err := json.Unmarshal(data, response); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if err := validateResponse(response, image); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func validateResponse(response *Response, image bufimage.Image) error {
+	ruleIDs := make(map[string]struct{}, len(response.Rules))
+	for _, rule := range response.Rules {
+		if rule == nil || rule.ID == "" {
+			return errors.New("rule with empty ID in response")
+		}
+		if _, ok := ruleIDs[rule.ID]; ok {
+			return fmt.Errorf("duplicate rule %q in response", rule.ID)
+		}
+		ruleIDs[rule.ID] = struct{}{}
+	}
+	for _, fileAnnotation := range response.FileAnnotations {
+		if fileAnnotation == nil {
+			return errors.New("nil file annotation in response")
+		}
+		if _, ok := ruleIDs[fileAnnotation.Type]; !ok {
+			return fmt.Errorf("file annotation for unknown rule %q in response", fileAnnotation.Type)
+		}
+		imageFile := image.GetFile(fileAnnotation.Path)
+		if imageFile == nil || imageFile.IsImport() {
+			return fmt.Errorf("file annotation for unknown file %q in response", fileAnnotation.Path)
+		}
+	}
+	return nil
+}
+
+func newBinaryHandlerProvider(
+	logger *zap.Logger,
+	storageosProvider storageos.Provider,
+	runner command.Runner,
+) HandlerProvider {
+	return func(pluginConfig *buflintconfig.PluginConfig) (appproto.Handler, error) {
+		return appprotoexec.NewHandler(
+			logger,
+			storageosProvider,
+			runner,
+			pluginConfig.Plugin,
+			appprotoexec.HandlerWithPluginPath(pluginConfig.Plugin),
+		)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflintplugin
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+type handler struct {
+	plugin Plugin
+}
+
+func newHandler(plugin Plugin) *handler {
+	return &handler{
+		plugin: plugin,
+	}
+}
+
+func (h *handler) Handle(
+	ctx context.Context,
+	container app.EnvStderrContainer,
+	responseWriter appproto.ResponseBuilder,
+	request *pluginpb.CodeGeneratorRequest,
+) error {
+	image, err := bufimage.NewImageForCodeGeneratorRequest(request)
+	if err != nil {
+		return err
+	}
+	files, err := protosource.NewFilesUnstable(
+		ctx,
+		bufimageutil.NewInputFiles(bufimage.ImageWithoutImports(image).Files())...,
+	)
+	if err != nil {
+		return err
+	}
+	fileAnnotations, err := h.plugin.Check(
+		ctx,
+		&Request{
+			Image: image,
+			Files: files,
+			Opt:   request.GetParameter(),
+		},
+	)
+	if err != nil {
+		// this is a plugin error, not a system error
+		responseWriter.AddError(err.Error())
+		return nil
+	}
+	data, err := json.Marshal(
+		&Response{
+			Rules:           h.plugin.Rules(),
+			FileAnnotations: fileAnnotations,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return responseWriter.AddFile(
+		&pluginpb.CodeGeneratorResponse_File{
+			Name:    proto.String(ResponseFileName),
+			Content: proto.String(string(data)),
+		},
+	)
+}
@@ -16,10 +16,13 @@ package buflint
 
 import (
 	"context"
+	"errors"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/internal/buflintcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/internal/buflintfix"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
@@ -28,13 +31,19 @@ import (
 )
 
 type handler struct {
-	logger *zap.Logger
-	runner *internal.Runner
+	logger         *zap.Logger
+	runner         *internal.Runner
+	pluginExecutor buflintplugin.Executor
 }
 
-func newHandler(logger *zap.Logger) *handler {
+func newHandler(logger *zap.Logger, options ...HandlerOption) *handler {
+	handlerOptions := newHandlerOptions()
+	for _, option := range options {
+		option(handlerOptions)
+	}
 	return &handler{
-		logger: logger,
+		logger:         logger,
+		pluginExecutor: handlerOptions.pluginExecutor,
 		// linting allows for comment ignores
 		// note that comment ignores still need to be enabled within the config
 		// for a given check, this just says that comment ignores are allowed
@@ -50,14 +59,137 @@ func (h *handler) Check(
 	ctx context.Context,
 	config *buflintconfig.Config,
 	image bufimage.Image,
+	options ...CheckOption,
 ) ([]bufanalysis.FileAnnotation, error) {
-	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	checkOptions := newCheckOptions()
+	for _, option := range options {
+		option(checkOptions)
+	}
+	files, err := protosource.NewFilesUnstable(
+		ctx,
+		bufimageutil.NewInputFiles(bufimage.ImageWithoutImports(image).Files())...,
+	)
 	if err != nil {
 		return nil, err
 	}
-	internalConfig, err := internalConfigForConfig(config)
+	pluginRules, err := h.getPluginRules(ctx, config, image)
+	if err != nil {
+		return nil, err
+	}
+	internalConfig, err := internalConfigForConfigAndCheckOptions(config, checkOptions, pluginRules...)
 	if err != nil {
 		return nil, err
 	}
 	return h.runner.Check(ctx, internalConfig, nil, files)
 }
+
+func (h *handler) Baseline(
+	ctx context.Context,
+	config *buflintconfig.Config,
+	image bufimage.Image,
+) ([]*buflintconfig.BaselineEntry, error) {
+	files, err := protosource.NewFilesUnstable(
+		ctx,
+		bufimageutil.NewInputFiles(bufimage.ImageWithoutImports(image).Files())...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	pluginRules, err := h.getPluginRules(ctx, config, image)
+	if err != nil {
+		return nil, err
+	}
+	internalConfig, err := internalConfigForConfig(config, pluginRules...)
+	if err != nil {
+		return nil, err
+	}
+	baselineKeys, err := h.runner.Baseline(ctx, internalConfig, nil, files)
+	if err != nil {
+		return nil, err
+	}
+	baselineEntries := make([]*buflintconfig.BaselineEntry, len(baselineKeys))
+	for i, baselineKey := range baselineKeys {
+		baselineEntries[i] = &buflintconfig.BaselineEntry{
+			ID:          baselineKey.ID,
+			Path:        baselineKey.Path,
+			ElementName: baselineKey.ElementName,
+		}
+	}
+	buflintconfig.SortBaselineEntries(baselineEntries)
+	return baselineEntries, nil
+}
+
+func (h *handler) Fix(
+	ctx context.Context,
+	config *buflintconfig.Config,
+	image bufimage.Image,
+	pathToData map[string][]byte,
+	options ...CheckOption,
+) ([]*FileFix, error) {
+	checkOptions := newCheckOptions()
+	for _, option := range options {
+		option(checkOptions)
+	}
+	// we need all files including imports to check for conflicts and references,
+	// but we only check the non-import files
+	allFiles, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	if err != nil {
+		return nil, err
+	}
+	var files []protosource.File
+	for _, file := range allFiles {
+		if imageFile := image.GetFile(file.Path()); imageFile != nil && !imageFile.IsImport() {
+			files = append(files, file)
+		}
+	}
+	pluginRules, err := h.getPluginRules(ctx, config, image)
+	if err != nil {
+		return nil, err
+	}
+	internalConfig, err := internalConfigForConfigAndCheckOptions(config, checkOptions, pluginRules...)
+	if err != nil {
+		return nil, err
+	}
+	failures, err := h.runner.Failures(ctx, internalConfig, nil, files)
+	if err != nil {
+		return nil, err
+	}
+	fixFileFixes, err := buflintfix.Fix(failures, allFiles, pathToData, config.EnumZeroValueSuffix)
+	if err != nil {
+		return nil, err
+	}
+	fileFixes := make([]*FileFix, len(fixFileFixes))
+	for i, fixFileFix := range fixFileFixes {
+		fileFixes[i] = &FileFix{
+			Path:            fixFileFix.File.Path(),
+			ExternalPath:    fixFileFix.File.ExternalPath(),
+			Data:            fixFileFix.Data,
+			FileAnnotations: fixFileFix.FileAnnotations,
+		}
+	}
+	return fileFixes, nil
+}
+
+// getPluginRules executes the lint plugins in the config, and returns their rules
+// along with the FileAnnotations they reported.
+func (h *handler) getPluginRules(
+	ctx context.Context,
+	config *buflintconfig.Config,
+	image bufimage.Image,
+) ([]*pluginRule, error) {
+	if len(config.Plugins) == 0 {
+		return nil, nil
+	}
+	if h.pluginExecutor == nil {
+		return nil, errors.New("lint plugins are not supported in this context")
+	}
+	var pluginRules []*pluginRule
+	for _, pluginConfig := range config.Plugins {
+		response, err := h.pluginExecutor.Execute(ctx, pluginConfig, image)
+		if err != nil {
+			return nil, err
+		}
+		pluginRules = append(pluginRules, newPluginRules(pluginConfig.Plugin, response)...)
+	}
+	return pluginRules, nil
+}
@@ -0,0 +1,407 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package buflintfix implements fixes for mechanically fixable lint failures.
+package buflintfix
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+)
+
+const (
+	enumValuePrefixID          = "ENUM_VALUE_PREFIX"
+	enumValueUpperSnakeCaseID  = "ENUM_VALUE_UPPER_SNAKE_CASE"
+	enumZeroValueSuffixID      = "ENUM_ZERO_VALUE_SUFFIX"
+	fieldLowerSnakeCaseID      = "FIELD_LOWER_SNAKE_CASE"
+	importUsedID               = "IMPORT_USED"
+	defaultEnumZeroValueSuffix = "_UNSPECIFIED"
+)
+
+// FileFix is the result of fixing a single file.
+type FileFix struct {
+	// File is the file that was fixed.
+	File protosource.File
+	// Data is the new content of the file with all fixes applied.
+	Data []byte
+	// FileAnnotations describe the fixes that were applied.
+	//
+	// These use the locations within the original content of the file.
+	FileAnnotations []bufanalysis.FileAnnotation
+}
+
+// Fix returns the fixes for the Failures.
+//
+// files must contain all files, including imports, so that renames can be
+// checked for conflicts and references.
+//
+// pathToData contains the current content of the files that can be modified,
+// keyed by path. Failures for files not in pathToData are not fixed, and
+// references within files not in pathToData are not updated.
+//
+// Failures that cannot be fixed safely are skipped. Only files that were
+// changed are returned, sorted by path.
+func Fix(
+	failures []*internal.Failure,
+	files []protosource.File,
+	pathToData map[string][]byte,
+	enumZeroValueSuffix string,
+) ([]*FileFix, error) {
+	if enumZeroValueSuffix == "" {
+		enumZeroValueSuffix = defaultEnumZeroValueSuffix
+	}
+	renames, importRemovals := getFixes(failures, pathToData, enumZeroValueSuffix)
+	if len(renames) == 0 && len(importRemovals) == 0 {
+		return nil, nil
+	}
+	pathToSource := make(map[string]*source, len(pathToData))
+	for path, data := range pathToData {
+		pathToSource[path] = newSource(data)
+	}
+	symbols := newSymbols(files)
+	var fixedRenames []*rename
+	for _, nameRenames := range groupRenamesByName(symbols.filterConflicts(renames)) {
+		ok, err := addRenameEdits(nameRenames, symbols, pathToSource)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			fixedRenames = append(fixedRenames, nameRenames...)
+		}
+	}
+	for _, importRemoval := range importRemovals {
+		if err := addImportRemovalEdit(importRemoval, pathToSource[importRemoval.File().Path()]); err != nil {
+			return nil, err
+		}
+	}
+	pathToFileAnnotations := make(map[string][]bufanalysis.FileAnnotation)
+	for _, rename := range fixedRenames {
+		path := rename.descriptor.File().Path()
+		pathToFileAnnotations[path] = append(pathToFileAnnotations[path], rename.fileAnnotation())
+	}
+	for _, importRemoval := range importRemovals {
+		path := importRemoval.File().Path()
+		pathToFileAnnotations[path] = append(
+			pathToFileAnnotations[path],
+			newFileAnnotation(
+				importRemoval,
+				importRemoval.Location(),
+				importUsedID,
+				"Removed unused import %q.",
+				importRemoval.Import(),
+			),
+		)
+	}
+	var fileFixes []*FileFix
+	for _, file := range files {
+		source, ok := pathToSource[file.Path()]
+		if !ok || len(source.edits) == 0 {
+			continue
+		}
+		data, err := source.apply()
+		if err != nil {
+			return nil, err
+		}
+		fileAnnotations := pathToFileAnnotations[file.Path()]
+		bufanalysis.SortFileAnnotations(fileAnnotations)
+		fileFixes = append(
+			fileFixes,
+			&FileFix{
+				File:            file,
+				Data:            data,
+				FileAnnotations: fileAnnotations,
+			},
+		)
+	}
+	sort.Slice(
+		fileFixes,
+		func(i int, j int) bool {
+			return fileFixes[i].File.Path() < fileFixes[j].File.Path()
+		},
+	)
+	return fileFixes, nil
+}
+
+// rename is the rename of a single descriptor.
+type rename struct {
+	descriptor protosource.NamedDescriptor
+	kind       string
+	newName    string
+	// ids are the sorted IDs of the failures fixed by this rename.
+	ids []string
+}
+
+func (r *rename) fileAnnotation() bufanalysis.FileAnnotation {
+	return newFileAnnotation(
+		r.descriptor,
+		r.descriptor.NameLocation(),
+		r.ids[0],
+		"Renamed %s %q to %q.",
+		r.kind,
+		r.descriptor.Name(),
+		r.newName,
+	)
+}
+
+// getFixes returns the renames and import removals for the failures.
+//
+// Failures for the same descriptor are merged into a single rename.
+func getFixes(
+	failures []*internal.Failure,
+	pathToData map[string][]byte,
+	enumZeroValueSuffix string,
+) ([]*rename, []protosource.FileImport) {
+	var fields []protosource.Field
+	fieldToIDs := make(map[protosource.Field][]string)
+	var enumValues []protosource.EnumValue
+	enumValueToIDs := make(map[protosource.EnumValue][]string)
+	var importRemovals []protosource.FileImport
+	seenImports := make(map[protosource.FileImport]struct{})
+	for _, failure := range failures {
+		if len(failure.Descriptors) == 0 || failure.Descriptors[0] == nil {
+			continue
+		}
+		descriptor := failure.Descriptors[0]
+		if _, ok := pathToData[descriptor.File().Path()]; !ok {
+			continue
+		}
+		switch failure.ID {
+		case fieldLowerSnakeCaseID:
+			field, ok := descriptor.(protosource.Field)
+			// group fields do not have their name in the source, only the group message does
+			if !ok || field.Type() == protosource.FieldDescriptorProtoTypeGroup {
+				continue
+			}
+			if _, ok := fieldToIDs[field]; !ok {
+				fields = append(fields, field)
+			}
+			fieldToIDs[field] = append(fieldToIDs[field], failure.ID)
+		case enumValuePrefixID, enumValueUpperSnakeCaseID, enumZeroValueSuffixID:
+			enumValue, ok := descriptor.(protosource.EnumValue)
+			if !ok {
+				continue
+			}
+			if _, ok := enumValueToIDs[enumValue]; !ok {
+				enumValues = append(enumValues, enumValue)
+			}
+			enumValueToIDs[enumValue] = append(enumValueToIDs[enumValue], failure.ID)
+		case importUsedID:
+			fileImport, ok := descriptor.(protosource.FileImport)
+			if !ok {
+				continue
+			}
+			if _, ok := seenImports[fileImport]; ok {
+				continue
+			}
+			seenImports[fileImport] = struct{}{}
+			importRemovals = append(importRemovals, fileImport)
+		}
+	}
+	var renames []*rename
+	for _, field := range fields {
+		renames = append(
+			renames,
+			&rename{
+				descriptor: field,
+				kind:       "field",
+				// this matches buflintcheck
+				newName: stringutil.ToLowerSnakeCase(field.Name()),
+				ids:     stringutil.SliceToUniqueSortedSlice(fieldToIDs[field]),
+			},
+		)
+	}
+	for _, enumValue := range enumValues {
+		ids := stringutil.SliceToUniqueSortedSlice(enumValueToIDs[enumValue])
+		renames = append(
+			renames,
+			&rename{
+				descriptor: enumValue,
+				kind:       "enum value",
+				newName:    getEnumValueNewName(enumValue, ids, enumZeroValueSuffix),
+				ids:        ids,
+			},
+		)
+	}
+	return renames, importRemovals
+}
+
+// getEnumValueNewName applies the fixes for the ids in order of case, prefix
+// and then zero value suffix, so that the result passes all three rules.
+func getEnumValueNewName(enumValue protosource.EnumValue, ids []string, enumZeroValueSuffix string) string {
+	idMap := stringutil.SliceToMap(ids)
+	newName := enumValue.Name()
+	if _, ok := idMap[enumValueUpperSnakeCaseID]; ok {
+		// this matches buflintcheck
+		newName = stringutil.ToUpperSnakeCase(newName)
+	}
+	if _, ok := idMap[enumValuePrefixID]; ok {
+		// this matches buflintcheck
+		expectedPrefix := stringutil.ToUpperSnakeCase(enumValue.Enum().Name()) + "_"
+		if !strings.HasPrefix(newName, expectedPrefix) {
+			newName = expectedPrefix + newName
+		}
+	}
+	if _, ok := idMap[enumZeroValueSuffixID]; ok {
+		if !strings.HasSuffix(newName, enumZeroValueSuffix) {
+			newName = newName + enumZeroValueSuffix
+		}
+	}
+	return newName
+}
+
+// groupRenamesByName groups the renames by the current name of their
+// descriptors, sorted by name.
+func groupRenamesByName(renames []*rename) [][]*rename {
+	nameToRenames := make(map[string][]*rename)
+	for _, rename := range renames {
+		name := rename.descriptor.Name()
+		nameToRenames[name] = append(nameToRenames[name], rename)
+	}
+	names := make([]string, 0, len(nameToRenames))
+	for name := range nameToRenames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	groups := make([][]*rename, len(names))
+	for i, name := range names {
+		groups[i] = nameToRenames[name]
+	}
+	return groups
+}
+
+// addRenameEdits adds the edits for renames that all have the same current name.
+//
+// If every element with this name is renamed to the same new name, all
+// identifiers with this name are renamed, which also updates all references,
+// for example in option values and field defaults.
+//
+// Otherwise, only the declarations are renamed, and only if the name does not
+// appear anywhere else, so that no reference is left dangling.
+//
+// Returns false if the renames could not be safely applied.
+func addRenameEdits(
+	renames []*rename,
+	symbols *symbols,
+	pathToSource map[string]*source,
+) (bool, error) {
+	name := renames[0].descriptor.Name()
+	if symbols.isRenamedConsistently(name, renames) {
+		for _, source := range pathToSource {
+			for _, token := range source.identifierTokens(name) {
+				source.addEdit(token.start, token.end, renames[0].newName)
+			}
+		}
+		return true, nil
+	}
+	declarationStarts := make(map[*source]map[int]struct{})
+	for _, descriptor := range symbols.nameToDescriptors[name] {
+		source, ok := pathToSource[descriptor.File().Path()]
+		if !ok {
+			continue
+		}
+		start, err := source.nameStart(descriptor)
+		if err != nil {
+			return false, err
+		}
+		if start < 0 {
+			continue
+		}
+		if declarationStarts[source] == nil {
+			declarationStarts[source] = make(map[int]struct{})
+		}
+		declarationStarts[source][start] = struct{}{}
+	}
+	for _, source := range pathToSource {
+		for _, token := range source.identifierTokens(name) {
+			if _, ok := declarationStarts[source][token.start]; !ok {
+				// there is a reference or other usage of this name that we
+				// cannot attribute to a single element
+				return false, nil
+			}
+		}
+	}
+	for _, rename := range renames {
+		source := pathToSource[rename.descriptor.File().Path()]
+		start, err := source.nameStart(rename.descriptor)
+		if err != nil {
+			return false, err
+		}
+		if start < 0 {
+			return false, nil
+		}
+	}
+	for _, rename := range renames {
+		source := pathToSource[rename.descriptor.File().Path()]
+		start, err := source.nameStart(rename.descriptor)
+		if err != nil {
+			return false, err
+		}
+		source.addEdit(start, start+len(name), rename.newName)
+	}
+	return true, nil
+}
+
+// addImportRemovalEdit adds an edit that removes the import statement, including
+// the entire line if nothing else is on it.
+func addImportRemovalEdit(fileImport protosource.FileImport, source *source) error {
+	location := fileImport.Location()
+	if location == nil {
+		return fmt.Errorf("no location for import %q", fileImport.Import())
+	}
+	start, err := source.offset(location.StartLine(), location.StartColumn())
+	if err != nil {
+		return err
+	}
+	end, err := source.offset(location.EndLine(), location.EndColumn())
+	if err != nil {
+		return err
+	}
+	start, end = source.expandToLines(start, end)
+	source.addEdit(start, end, "")
+	return nil
+}
+
+func newFileAnnotation(
+	descriptor protosource.Descriptor,
+	location protosource.Location,
+	id string,
+	format string,
+	args ...interface{},
+) bufanalysis.FileAnnotation {
+	startLine := 0
+	startColumn := 0
+	endLine := 0
+	endColumn := 0
+	if location != nil {
+		startLine = location.StartLine()
+		startColumn = location.StartColumn()
+		endLine = location.EndLine()
+		endColumn = location.EndColumn()
+	}
+	return bufanalysis.NewFileAnnotation(
+		descriptor.File(),
+		startLine,
+		startColumn,
+		endLine,
+		endColumn,
+		id,
+		fmt.Sprintf(format, args...),
+	)
+}
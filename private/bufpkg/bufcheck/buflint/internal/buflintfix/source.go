@@ -0,0 +1,265 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflintfix
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/bufbuild/buf/private/pkg/protosource"
+)
+
+// tabWidth is the width of a tab when computing columns in source locations.
+const tabWidth = 8
+
+// source is the content of a single file with the edits to apply.
+type source struct {
+	data []byte
+	// lazily computed
+	identifierTokensValue []*token
+	edits                 []*edit
+	editStarts            map[int]struct{}
+}
+
+func newSource(data []byte) *source {
+	return &source{
+		data:       data,
+		editStarts: make(map[int]struct{}),
+	}
+}
+
+// token is an identifier token.
+type token struct {
+	value string
+	start int
+	end   int
+}
+
+// edit replaces data[start:end] with text.
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+// addEdit adds an edit. Edits with the same start as an existing edit are ignored.
+func (s *source) addEdit(start int, end int, text string) {
+	if _, ok := s.editStarts[start]; ok {
+		return
+	}
+	s.editStarts[start] = struct{}{}
+	s.edits = append(s.edits, &edit{start: start, end: end, text: text})
+}
+
+// apply returns the data with all edits applied.
+func (s *source) apply() ([]byte, error) {
+	edits := make([]*edit, len(s.edits))
+	copy(edits, s.edits)
+	sort.Slice(
+		edits,
+		func(i int, j int) bool {
+			return edits[i].start < edits[j].start
+		},
+	)
+	buffer := bytes.NewBuffer(nil)
+	offset := 0
+	for _, edit := range edits {
+		if edit.start < offset {
+			return nil, fmt.Errorf("overlapping edits at offset %d", edit.start)
+		}
+		_, _ = buffer.Write(s.data[offset:edit.start])
+		_, _ = buffer.WriteString(edit.text)
+		offset = edit.end
+	}
+	_, _ = buffer.Write(s.data[offset:])
+	return buffer.Bytes(), nil
+}
+
+// identifierTokens returns the identifier tokens with the given value.
+func (s *source) identifierTokens(value string) []*token {
+	if s.identifierTokensValue == nil {
+		s.identifierTokensValue = tokenizeIdentifiers(s.data)
+	}
+	var tokens []*token
+	for _, token := range s.identifierTokensValue {
+		if token.value == value {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// nameStart returns the offset of the name of the descriptor.
+//
+// Returns -1 if the name location does not point to the name.
+func (s *source) nameStart(descriptor protosource.NamedDescriptor) (int, error) {
+	location := descriptor.NameLocation()
+	if location == nil {
+		return -1, nil
+	}
+	start, err := s.offset(location.StartLine(), location.StartColumn())
+	if err != nil {
+		return 0, err
+	}
+	name := descriptor.Name()
+	end := start + len(name)
+	if end > len(s.data) || string(s.data[start:end]) != name {
+		return -1, nil
+	}
+	if end < len(s.data) && isIdentifierByte(s.data[end]) {
+		return -1, nil
+	}
+	return start, nil
+}
+
+// offset returns the byte offset of the 1-indexed line and column.
+//
+// Columns count runes, with tabs advancing to the next tab stop.
+func (s *source) offset(line int, column int) (int, error) {
+	if line < 1 || column < 1 {
+		return 0, fmt.Errorf("invalid location %d:%d", line, column)
+	}
+	offset := 0
+	for currentLine := 1; currentLine < line; currentLine++ {
+		index := bytes.IndexByte(s.data[offset:], '\n')
+		if index < 0 {
+			return 0, fmt.Errorf("line %d is out of range", line)
+		}
+		offset += index + 1
+	}
+	currentColumn := 1
+	for currentColumn < column {
+		if offset >= len(s.data) || s.data[offset] == '\n' {
+			return 0, fmt.Errorf("column %d is out of range on line %d", column, line)
+		}
+		r, size := utf8.DecodeRune(s.data[offset:])
+		if r == '\t' {
+			currentColumn += tabWidth - (currentColumn-1)%tabWidth
+		} else {
+			currentColumn++
+		}
+		offset += size
+	}
+	return offset, nil
+}
+
+// expandToLines expands the range to include the entire lines including the
+// trailing newline if the rest of the lines are only whitespace.
+//
+// Otherwise, returns the range unchanged.
+func (s *source) expandToLines(start int, end int) (int, int) {
+	newStart := start
+	for newStart > 0 && isHorizontalSpace(s.data[newStart-1]) {
+		newStart--
+	}
+	if newStart > 0 && s.data[newStart-1] != '\n' {
+		return start, end
+	}
+	newEnd := end
+	for newEnd < len(s.data) && isHorizontalSpace(s.data[newEnd]) {
+		newEnd++
+	}
+	switch {
+	case newEnd == len(s.data):
+		return newStart, newEnd
+	case s.data[newEnd] == '\n':
+		newEnd++
+	case s.data[newEnd] == '\r' && newEnd+1 < len(s.data) && s.data[newEnd+1] == '\n':
+		newEnd += 2
+	default:
+		return start, end
+	}
+	// if the lines are surrounded by empty lines, also remove the following
+	// empty line so that we do not leave two empty lines in a row
+	if newStart >= 2 && s.data[newStart-2] == '\n' {
+		switch {
+		case bytes.HasPrefix(s.data[newEnd:], []byte("\n")):
+			newEnd++
+		case bytes.HasPrefix(s.data[newEnd:], []byte("\r\n")):
+			newEnd += 2
+		}
+	}
+	return newStart, newEnd
+}
+
+// tokenizeIdentifiers returns all identifier tokens in the data, skipping
+// comments, strings, and numbers.
+func tokenizeIdentifiers(data []byte) []*token {
+	var tokens []*token
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			i += 2
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			index := bytes.Index(data[i+2:], []byte("*/"))
+			if index < 0 {
+				i = len(data)
+			} else {
+				i += index + 4
+			}
+		case c == '"' || c == '\'':
+			i++
+			for i < len(data) && data[i] != c && data[i] != '\n' {
+				if data[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case isDigit(c) || (c == '.' && i+1 < len(data) && isDigit(data[i+1])):
+			for i < len(data) {
+				if (data[i] == 'e' || data[i] == 'E') && i+1 < len(data) && (data[i+1] == '+' || data[i+1] == '-') {
+					i += 2
+					continue
+				}
+				if !isIdentifierByte(data[i]) && data[i] != '.' {
+					break
+				}
+				i++
+			}
+		case isIdentifierStartByte(c):
+			start := i
+			for i < len(data) && isIdentifierByte(data[i]) {
+				i++
+			}
+			tokens = append(tokens, &token{value: string(data[start:i]), start: start, end: i})
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+func isIdentifierStartByte(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isIdentifierByte(c byte) bool {
+	return isIdentifierStartByte(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isHorizontalSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
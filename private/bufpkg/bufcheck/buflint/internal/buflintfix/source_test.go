@@ -0,0 +1,86 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflintfix
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenizeIdentifiers(t *testing.T) {
+	t.Parallel()
+	data := []byte(`// foo comment
+message Foo { /* foo */
+  optional int64 foo = 1 [default = 1e+5, json_name = "foo\"foo"];
+  optional double bar = 2 [default = .5e-3];
+}
+`)
+	var values []string
+	for _, token := range tokenizeIdentifiers(data) {
+		values = append(values, token.value)
+		assert.Equal(t, token.value, string(data[token.start:token.end]))
+	}
+	assert.Equal(
+		t,
+		[]string{
+			"message", "Foo",
+			"optional", "int64", "foo", "default", "json_name",
+			"optional", "double", "bar", "default",
+		},
+		values,
+	)
+}
+
+func TestOffset(t *testing.T) {
+	t.Parallel()
+	source := newSource([]byte("a\n\tb\n  é c\n"))
+	testOffset(t, source, 1, 1, 0)
+	testOffset(t, source, 2, 9, 3)
+	testOffset(t, source, 3, 5, 10)
+	// end columns point one past the end
+	testOffset(t, source, 3, 6, 11)
+	_, err := source.offset(3, 7)
+	assert.Error(t, err)
+	_, err = source.offset(5, 1)
+	assert.Error(t, err)
+}
+
+func TestExpandToLines(t *testing.T) {
+	t.Parallel()
+	testExpandToLines(t, "a;\nimport \"b\";\nc;\n", "import \"b\";", "a;\nc;\n")
+	testExpandToLines(t, "a;\n\nimport \"b\";\n\nc;\n", "import \"b\";", "a;\n\nc;\n")
+	testExpandToLines(t, "a;\nimport \"b\"; // b\n", "import \"b\";", "a;\n // b\n")
+	testExpandToLines(t, "a; import \"b\";\n", "import \"b\";", "a; \n")
+}
+
+func testOffset(t *testing.T, source *source, line int, column int, expected int) {
+	actual, err := source.offset(line, column)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func testExpandToLines(t *testing.T, data string, remove string, expected string) {
+	source := newSource([]byte(data))
+	start := strings.Index(data, remove)
+	require.True(t, start >= 0)
+	start, end := source.expandToLines(start, start+len(remove))
+	source.addEdit(start, end, "")
+	actual, err := source.apply()
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(actual))
+}
@@ -0,0 +1,230 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflintfix
+
+import (
+	"strings"
+
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// protoKeywords are the words that have meaning in the Protobuf language and
+// could appear as identifiers that do not reference an element.
+var protoKeywords = map[string]struct{}{
+	"bool":       {},
+	"bytes":      {},
+	"default":    {},
+	"double":     {},
+	"enum":       {},
+	"extend":     {},
+	"extensions": {},
+	"false":      {},
+	"fixed32":    {},
+	"fixed64":    {},
+	"float":      {},
+	"group":      {},
+	"import":     {},
+	"inf":        {},
+	"int32":      {},
+	"int64":      {},
+	"json_name":  {},
+	"map":        {},
+	"max":        {},
+	"message":    {},
+	"nan":        {},
+	"oneof":      {},
+	"option":     {},
+	"optional":   {},
+	"package":    {},
+	"public":     {},
+	"repeated":   {},
+	"required":   {},
+	"reserved":   {},
+	"returns":    {},
+	"rpc":        {},
+	"service":    {},
+	"sfixed32":   {},
+	"sfixed64":   {},
+	"sint32":     {},
+	"sint64":     {},
+	"stream":     {},
+	"string":     {},
+	"syntax":     {},
+	"to":         {},
+	"true":       {},
+	"uint32":     {},
+	"uint64":     {},
+	"weak":       {},
+}
+
+// symbols is the inventory of all elements across a set of files.
+type symbols struct {
+	nameToDescriptors map[string][]protosource.NamedDescriptor
+	scopedNames       map[string]struct{}
+	// reservedNames are names that can appear as identifiers without
+	// referencing an element, such as keywords, built-in option names,
+	// and package components.
+	reservedNames map[string]struct{}
+}
+
+func newSymbols(files []protosource.File) *symbols {
+	s := &symbols{
+		nameToDescriptors: make(map[string][]protosource.NamedDescriptor),
+		scopedNames:       make(map[string]struct{}),
+		reservedNames:     make(map[string]struct{}),
+	}
+	for name := range protoKeywords {
+		s.reservedNames[name] = struct{}{}
+	}
+	addDescriptorOptionNames(s.reservedNames, descriptorpb.File_google_protobuf_descriptor_proto.Messages())
+	for _, file := range files {
+		for _, packageComponent := range strings.Split(file.Package(), ".") {
+			s.reservedNames[packageComponent] = struct{}{}
+		}
+		s.addFile(file)
+	}
+	return s
+}
+
+// filterConflicts returns the renames whose new names do not conflict with
+// any existing element or any earlier rename.
+func (s *symbols) filterConflicts(renames []*rename) []*rename {
+	claimedScopedNames := make(map[string]struct{})
+	var filteredRenames []*rename
+	for _, rename := range renames {
+		if rename.newName == rename.descriptor.Name() {
+			continue
+		}
+		if _, ok := s.reservedNames[rename.newName]; ok {
+			continue
+		}
+		scopedName := getScope(rename.descriptor) + rename.newName
+		if _, ok := s.scopedNames[scopedName]; ok {
+			continue
+		}
+		if _, ok := claimedScopedNames[scopedName]; ok {
+			continue
+		}
+		claimedScopedNames[scopedName] = struct{}{}
+		filteredRenames = append(filteredRenames, rename)
+	}
+	return filteredRenames
+}
+
+// isRenamedConsistently returns true if every element with the name is
+// renamed to the same new name, and the name cannot appear as an identifier
+// for anything other than these elements.
+func (s *symbols) isRenamedConsistently(name string, renames []*rename) bool {
+	if _, ok := s.reservedNames[name]; ok {
+		return false
+	}
+	if len(s.nameToDescriptors[name]) != len(renames) {
+		return false
+	}
+	for _, rename := range renames {
+		if rename.newName != renames[0].newName {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *symbols) addFile(file protosource.File) {
+	for _, message := range file.Messages() {
+		s.addMessage(message)
+	}
+	for _, enum := range file.Enums() {
+		s.addEnum(enum)
+	}
+	for _, extension := range file.Extensions() {
+		s.add(extension)
+	}
+	for _, service := range file.Services() {
+		s.add(service)
+		for _, method := range service.Methods() {
+			s.add(method)
+		}
+	}
+}
+
+func (s *symbols) addMessage(message protosource.Message) {
+	s.add(message)
+	for _, field := range message.Fields() {
+		s.add(field)
+	}
+	for _, extension := range message.Extensions() {
+		s.add(extension)
+	}
+	for _, oneof := range message.Oneofs() {
+		s.add(oneof)
+	}
+	for _, nestedMessage := range message.Messages() {
+		s.addMessage(nestedMessage)
+	}
+	for _, enum := range message.Enums() {
+		s.addEnum(enum)
+	}
+}
+
+func (s *symbols) addEnum(enum protosource.Enum) {
+	s.add(enum)
+	for _, enumValue := range enum.Values() {
+		s.add(enumValue)
+	}
+}
+
+func (s *symbols) add(descriptor protosource.NamedDescriptor) {
+	name := descriptor.Name()
+	s.nameToDescriptors[name] = append(s.nameToDescriptors[name], descriptor)
+	s.scopedNames[getScope(descriptor)+name] = struct{}{}
+}
+
+// getScope returns the scope the name of the descriptor is declared in,
+// including the trailing period if not empty.
+//
+// Enum values are scoped as siblings of their enum, not as children.
+func getScope(descriptor protosource.NamedDescriptor) string {
+	fullName := descriptor.FullName()
+	if _, ok := descriptor.(protosource.EnumValue); ok {
+		fullName = trimLastComponent(fullName)
+	}
+	scope := trimLastComponent(fullName)
+	if scope == "" {
+		return ""
+	}
+	return scope + "."
+}
+
+func trimLastComponent(fullName string) string {
+	if index := strings.LastIndexByte(fullName, '.'); index >= 0 {
+		return fullName[:index]
+	}
+	return ""
+}
+
+// addDescriptorOptionNames adds the names of all fields of the messages in
+// descriptor.proto, which includes all built-in option names.
+func addDescriptorOptionNames(names map[string]struct{}, messageDescriptors protoreflect.MessageDescriptors) {
+	for i := 0; i < messageDescriptors.Len(); i++ {
+		messageDescriptor := messageDescriptors.Get(i)
+		fieldDescriptors := messageDescriptor.Fields()
+		for j := 0; j < fieldDescriptors.Len(); j++ {
+			names[string(fieldDescriptors.Get(j).Name())] = struct{}{}
+		}
+		addDescriptorOptionNames(names, messageDescriptor.Messages())
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflint
+
+import (
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintplugin"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+)
+
+// pluginRule is a rule provided by a plugin, along with the FileAnnotations
+// the plugin reported for the rule.
+type pluginRule struct {
+	plugin          string
+	rule            *buflintplugin.Rule
+	fileAnnotations []*buflintplugin.FileAnnotation
+}
+
+func newPluginRules(plugin string, response *buflintplugin.Response) []*pluginRule {
+	idToFileAnnotations := make(map[string][]*buflintplugin.FileAnnotation)
+	for _, fileAnnotation := range response.FileAnnotations {
+		idToFileAnnotations[fileAnnotation.Type] = append(idToFileAnnotations[fileAnnotation.Type], fileAnnotation)
+	}
+	pluginRules := make([]*pluginRule, len(response.Rules))
+	for i, rule := range response.Rules {
+		pluginRules[i] = &pluginRule{
+			plugin:          plugin,
+			rule:            rule,
+			fileAnnotations: idToFileAnnotations[rule.ID],
+		}
+	}
+	return pluginRules
+}
+
+// versionSpecWithPluginRules returns a copy of the VersionSpec with the plugin rules added.
+func versionSpecWithPluginRules(versionSpec *internal.VersionSpec, pluginRules []*pluginRule) (*internal.VersionSpec, error) {
+	ruleBuilders := make([]*internal.RuleBuilder, 0, len(versionSpec.RuleBuilders)+len(pluginRules))
+	ruleBuilders = append(ruleBuilders, versionSpec.RuleBuilders...)
+	idToCategories := make(map[string][]string, len(versionSpec.IDToCategories)+len(pluginRules))
+	for id, categories := range versionSpec.IDToCategories {
+		idToCategories[id] = categories
+	}
+	for _, pluginRule := range pluginRules {
+		id := pluginRule.rule.ID
+		if _, ok := idToCategories[id]; ok {
+			return nil, fmt.Errorf("lint plugin %q: rule %q is already defined", pluginRule.plugin, id)
+		}
+		categories := pluginRule.rule.Categories
+		if categories == nil {
+			// the map must contain an entry for every rule
+			categories = []string{}
+		}
+		idToCategories[id] = categories
+		ruleBuilders = append(
+			ruleBuilders,
+			internal.NewNopRuleBuilder(
+				id,
+				pluginRule.rule.Purpose,
+				newPluginCheckFunc(pluginRule.fileAnnotations),
+			),
+		)
+	}
+	return &internal.VersionSpec{
+		RuleBuilders:      ruleBuilders,
+		DefaultCategories: versionSpec.DefaultCategories,
+		IDToCategories:    idToCategories,
+	}, nil
+}
+
+// newPluginCheckFunc returns a new CheckFunc that returns the FileAnnotations
+// reported by a plugin for the files being checked.
+//
+// To apply ignores, each FileAnnotation is associated with the descriptor
+// that starts at the start of the FileAnnotation, or the file if there is none.
+func newPluginCheckFunc(fileAnnotations []*buflintplugin.FileAnnotation) internal.CheckFunc {
+	return func(
+		id string,
+		ignoreFunc internal.IgnoreFunc,
+		previousFiles []protosource.File,
+		files []protosource.File,
+	) ([]bufanalysis.FileAnnotation, error) {
+		filePathToFile, err := protosource.FilePathToFile(files...)
+		if err != nil {
+			return nil, err
+		}
+		var result []bufanalysis.FileAnnotation
+		for _, fileAnnotation := range fileAnnotations {
+			file, ok := filePathToFile[fileAnnotation.Path]
+			if !ok {
+				// the file was not one of the files being checked
+				continue
+			}
+			var descriptor protosource.Descriptor = file
+			var location protosource.Location
+			if namedDescriptor := getDescriptorStartingAt(file, fileAnnotation.StartLine, fileAnnotation.StartColumn); namedDescriptor != nil {
+				descriptor = namedDescriptor
+				location = namedDescriptor.Location()
+			}
+			if ignoreFunc(id, []protosource.Descriptor{descriptor}, []protosource.Location{location}) {
+				continue
+			}
+			result = append(
+				result,
+				bufanalysis.NewFileAnnotation(
+					file,
+					fileAnnotation.StartLine,
+					fileAnnotation.StartColumn,
+					fileAnnotation.EndLine,
+					fileAnnotation.EndColumn,
+					id,
+					fileAnnotation.Message,
+				),
+			)
+		}
+		return result, nil
+	}
+}
+
+// getDescriptorStartingAt returns the outermost descriptor whose location or
+// name location starts at the given line and column, or nil if there is none.
+func getDescriptorStartingAt(file protosource.File, line int, column int) protosource.NamedDescriptor {
+	if line == 0 {
+		return nil
+	}
+	var result protosource.NamedDescriptor
+	visit := func(namedDescriptor protosource.NamedDescriptor) {
+		if result != nil {
+			return
+		}
+		if locationStartsAt(namedDescriptor.Location(), line, column) ||
+			locationStartsAt(namedDescriptor.NameLocation(), line, column) {
+			result = namedDescriptor
+		}
+	}
+	_ = protosource.ForEachMessage(
+		func(message protosource.Message) error {
+			visit(message)
+			for _, field := range message.Fields() {
+				visit(field)
+			}
+			for _, extension := range message.Extensions() {
+				visit(extension)
+			}
+			for _, oneof := range message.Oneofs() {
+				visit(oneof)
+			}
+			return nil
+		},
+		file,
+	)
+	_ = protosource.ForEachEnum(
+		func(enum protosource.Enum) error {
+			visit(enum)
+			for _, enumValue := range enum.Values() {
+				visit(enumValue)
+			}
+			return nil
+		},
+		file,
+	)
+	for _, service := range file.Services() {
+		visit(service)
+		for _, method := range service.Methods() {
+			visit(method)
+		}
+	}
+	for _, extension := range file.Extensions() {
+		visit(extension)
+	}
+	return result
+}
+
+func locationStartsAt(location protosource.Location, line int, column int) bool {
+	return location != nil && location.StartLine() == line && location.StartColumn() == column
+}
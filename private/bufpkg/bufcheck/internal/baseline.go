@@ -0,0 +1,56 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import "github.com/bufbuild/buf/private/pkg/protosource"
+
+// BaselineKey is the key used to match a FileAnnotation against a baseline.
+//
+// Keys do not contain line or column information so that they survive
+// line shifts within a file.
+type BaselineKey struct {
+	// ID is the rule ID.
+	ID string
+	// Path is the path of the file relative to the root it is contained within.
+	Path string
+	// ElementName is the fully-qualified name of the element the FileAnnotation was
+	// produced for, or the import path for imports.
+	//
+	// This is empty for file-level FileAnnotations.
+	ElementName string
+}
+
+// newBaselineKey returns a new BaselineKey for the id and descriptors.
+//
+// The first descriptor is the descriptor the FileAnnotation is produced for.
+// Returns false if there is no such descriptor.
+func newBaselineKey(id string, descriptors []protosource.Descriptor) (BaselineKey, bool) {
+	if len(descriptors) == 0 || descriptors[0] == nil {
+		return BaselineKey{}, false
+	}
+	descriptor := descriptors[0]
+	var elementName string
+	switch t := descriptor.(type) {
+	case protosource.NamedDescriptor:
+		elementName = t.FullName()
+	case protosource.FileImport:
+		elementName = t.Import()
+	}
+	return BaselineKey{
+		ID:          id,
+		Path:        descriptor.File().Path(),
+		ElementName: elementName,
+	}, true
+}
@@ -41,6 +41,11 @@ type Config struct {
 
 	AllowCommentIgnores    bool
 	IgnoreUnstablePackages bool
+
+	// IgnoreBaselineKeys are the keys of FileAnnotations recorded in a baseline.
+	//
+	// FileAnnotations that match one of these keys are ignored.
+	IgnoreBaselineKeys map[BaselineKey]struct{}
 }
 
 // ConfigBuilder is a config builder.
@@ -0,0 +1,35 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import "github.com/bufbuild/buf/private/pkg/protosource"
+
+// Failure is a Rule failure that results in a FileAnnotation.
+type Failure struct {
+	// ID is the ID of the Rule that failed.
+	ID string
+	// Descriptors are the descriptors the failure was produced for.
+	//
+	// The first descriptor is the primary descriptor, and may be nil.
+	// The remaining descriptors are the extra descriptors checked for ignores.
+	Descriptors []protosource.Descriptor
+}
+
+func newFailure(id string, descriptors []protosource.Descriptor) *Failure {
+	return &Failure{
+		ID:          id,
+		Descriptors: descriptors,
+	}
+}
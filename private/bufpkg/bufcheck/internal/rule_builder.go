@@ -81,6 +81,22 @@ func (c *RuleBuilder) ID() string {
 	return c.id
 }
 
+// WithCheckFunc returns a new RuleBuilder with the same id and purpose
+// whose CheckFunc is wrapped by f.
+func (c *RuleBuilder) WithCheckFunc(f func(CheckFunc) CheckFunc) *RuleBuilder {
+	return NewRuleBuilder(
+		c.id,
+		c.newPurpose,
+		func(configBuilder ConfigBuilder) (CheckFunc, error) {
+			checkFunc, err := c.newCheck(configBuilder)
+			if err != nil {
+				return nil, err
+			}
+			return f(checkFunc), nil
+		},
+	)
+}
+
 func newNopPurpose(purpose string) func(ConfigBuilder) (string, error) {
 	return func(ConfigBuilder) (string, error) {
 		return purpose, nil
@@ -17,6 +17,7 @@ package internal
 import (
 	"context"
 	"strings"
+	"sync"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
@@ -62,6 +63,70 @@ func RunnerWithIgnorePrefix(ignorePrefix string) RunnerOption {
 
 // Check runs the Rules.
 func (r *Runner) Check(ctx context.Context, config *Config, previousFiles []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+	return r.check(ctx, config, r.newIgnoreFunc(config), previousFiles, files)
+}
+
+// Baseline runs the Rules and returns the BaselineKeys for the FileAnnotations
+// that would be produced by Check.
+//
+// FileAnnotations that do not have a descriptor cannot be recorded and are skipped.
+// The BaselineKeys in the Config are not used.
+//
+// The returned BaselineKeys are deduplicated, but not sorted.
+func (r *Runner) Baseline(ctx context.Context, config *Config, previousFiles []protosource.File, files []protosource.File) ([]BaselineKey, error) {
+	baselineConfig := *config
+	baselineConfig.IgnoreBaselineKeys = nil
+	failures, err := r.Failures(ctx, &baselineConfig, previousFiles, files)
+	if err != nil {
+		return nil, err
+	}
+	var baselineKeys []BaselineKey
+	seen := make(map[BaselineKey]struct{}, len(failures))
+	for _, failure := range failures {
+		baselineKey, ok := newBaselineKey(failure.ID, failure.Descriptors)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[baselineKey]; ok {
+			continue
+		}
+		seen[baselineKey] = struct{}{}
+		baselineKeys = append(baselineKeys, baselineKey)
+	}
+	return baselineKeys, nil
+}
+
+// Failures runs the Rules and returns a Failure for each FileAnnotation
+// that would be produced by Check.
+//
+// The returned Failures are not sorted.
+func (r *Runner) Failures(ctx context.Context, config *Config, previousFiles []protosource.File, files []protosource.File) ([]*Failure, error) {
+	ignoreFunc := r.newIgnoreFunc(config)
+	var lock sync.Mutex
+	var failures []*Failure
+	recordingIgnoreFunc := func(id string, descriptors []protosource.Descriptor, locations []protosource.Location) bool {
+		if ignoreFunc(id, descriptors, locations) {
+			return true
+		}
+		// if not ignored, this will result in a FileAnnotation, so we record it
+		lock.Lock()
+		failures = append(failures, newFailure(id, descriptors))
+		lock.Unlock()
+		return false
+	}
+	if _, err := r.check(ctx, config, recordingIgnoreFunc, previousFiles, files); err != nil {
+		return nil, err
+	}
+	return failures, nil
+}
+
+func (r *Runner) check(
+	ctx context.Context,
+	config *Config,
+	ignoreFunc IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+) ([]bufanalysis.FileAnnotation, error) {
 	rules := config.Rules
 	if len(rules) == 0 {
 		return nil, nil
@@ -73,7 +138,6 @@ func (r *Runner) Check(ctx context.Context, config *Config, previousFiles []prot
 	)
 	defer span.End()
 
-	ignoreFunc := r.newIgnoreFunc(config)
 	var fileAnnotations []bufanalysis.FileAnnotation
 	resultC := make(chan *result, len(rules))
 	for _, rule := range rules {
@@ -118,6 +182,13 @@ func (r *Runner) newIgnoreFunc(config *Config) IgnoreFunc {
 				}
 			}
 		}
+		if len(config.IgnoreBaselineKeys) > 0 {
+			if baselineKey, ok := newBaselineKey(id, descriptors); ok {
+				if _, ok := config.IgnoreBaselineKeys[baselineKey]; ok {
+					return true
+				}
+			}
+		}
 		return false
 	}
 }
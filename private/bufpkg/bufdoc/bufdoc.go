@@ -0,0 +1,122 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufdoc generates API documentation from Protobuf files.
+package bufdoc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"github.com/bufbuild/buf/private/pkg/storage"
+)
+
+const (
+	// FormatMarkdown is the Markdown format.
+	FormatMarkdown Format = iota + 1
+	// FormatHTML is the static HTML format.
+	FormatHTML
+)
+
+var (
+	// AllFormatStrings is all format strings.
+	//
+	// Sorted in the order we want to display them.
+	AllFormatStrings = []string{
+		"markdown",
+		"html",
+	}
+
+	stringToFormat = map[string]Format{
+		"markdown": FormatMarkdown,
+		"html":     FormatHTML,
+	}
+	formatToString = map[Format]string{
+		FormatMarkdown: "markdown",
+		FormatHTML:     "html",
+	}
+	formatToExtension = map[Format]string{
+		FormatMarkdown: ".md",
+		FormatHTML:     ".html",
+	}
+)
+
+// Format is a documentation format.
+type Format int
+
+// String implements fmt.Stringer.
+func (f Format) String() string {
+	s, ok := formatToString[f]
+	if !ok {
+		return strconv.Itoa(int(f))
+	}
+	return s
+}
+
+// ParseFormat parses the Format.
+//
+// The empty strings defaults to FormatMarkdown.
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return FormatMarkdown, nil
+	}
+	f, ok := stringToFormat[s]
+	if ok {
+		return f, nil
+	}
+	return 0, fmt.Errorf("unknown format: %q", s)
+}
+
+// Generate generates documentation for the files and writes it to the bucket.
+//
+// One document is written per package, named after the package, along with an
+// index document that links to every package. Types are cross-linked if they
+// are defined in one of the given files.
+func Generate(
+	ctx context.Context,
+	writeBucket storage.WriteBucket,
+	format Format,
+	files []protosource.File,
+) error {
+	extension, ok := formatToExtension[format]
+	if !ok {
+		return fmt.Errorf("unknown format: %v", format)
+	}
+	packageDocs, err := newPackageDocs(files, extension)
+	if err != nil {
+		return err
+	}
+	render := renderMarkdown
+	if format == FormatHTML {
+		render = renderHTML
+	}
+	for _, packageDoc := range packageDocs {
+		data, err := render(packageTemplateName, packageDoc)
+		if err != nil {
+			return err
+		}
+		if err := storage.PutPath(ctx, writeBucket, packageDoc.Path, data); err != nil {
+			return err
+		}
+	}
+	data, err := render(indexTemplateName, packageDocs)
+	if err != nil {
+		return err
+	}
+	return storage.PutPath(ctx, writeBucket, indexName+extension, data)
+}
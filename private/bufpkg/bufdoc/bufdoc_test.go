@@ -0,0 +1,112 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufdoc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagebuild"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGenerateMarkdown(t *testing.T) {
+	t.Parallel()
+	readBucket := testGenerate(t, FormatMarkdown)
+	testAssertPaths(t, readBucket, "acme.common.v1.md", "acme.pet.v1.md", "index.md")
+	index := testGetString(t, readBucket, "index.md")
+	assert.Contains(t, index, "- [`acme.common.v1`](acme.common.v1.md)")
+	assert.Contains(t, index, "- [`acme.pet.v1`](acme.pet.v1.md)")
+	pet := testGetString(t, readBucket, "acme.pet.v1.md")
+	assert.Contains(t, pet, "# Package `acme.pet.v1`")
+	assert.Contains(t, pet, "| `GetPet` | [`acme.pet.v1.GetPetRequest`](#acme.pet.v1.GetPetRequest) | [`acme.pet.v1.GetPetResponse`](#acme.pet.v1.GetPetResponse) | GetPet gets a pet. |")
+	assert.Contains(t, pet, "| `WatchPets` | [`acme.pet.v1.GetPetRequest`](#acme.pet.v1.GetPetRequest) | stream [`acme.pet.v1.Pet`](#acme.pet.v1.Pet) | **Deprecated.** |")
+	assert.Contains(t, pet, "| `price` | 3 | [`acme.common.v1.Money`](acme.common.v1.md#acme.common.v1.Money) |  |  |")
+	assert.Contains(t, pet, "| `labels` | 4 | map&lt;`string`, `string`&gt; |  |  |")
+	assert.Contains(t, pet, "| `company` | 6 | `string` | oneof `owner` | **Deprecated.** |")
+	assert.Contains(t, pet, "| `age` | 7 | `int32` | optional |  |")
+	assert.Contains(t, pet, "| `PET_TYPE_CAT` | 1 | A cat. |")
+	assert.NotContains(t, pet, "LabelsEntry")
+	common := testGetString(t, readBucket, "acme.common.v1.md")
+	assert.Contains(t, common, "| `units` | 2 | `int64` |  | The whole units. |")
+}
+
+func TestGenerateHTML(t *testing.T) {
+	t.Parallel()
+	readBucket := testGenerate(t, FormatHTML)
+	testAssertPaths(t, readBucket, "acme.common.v1.html", "acme.pet.v1.html", "index.html")
+	pet := testGetString(t, readBucket, "acme.pet.v1.html")
+	assert.Contains(t, pet, `<h3 id="acme.pet.v1.Pet">Pet</h3>`)
+	assert.Contains(t, pet, `<a href="acme.common.v1.html#acme.common.v1.Money"><code>acme.common.v1.Money</code></a>`)
+	assert.Contains(t, pet, "It has | pipes and &lt;html&gt;.")
+	assert.Contains(t, pet, `<p class="deprecated">Deprecated.</p>`)
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, FormatMarkdown, format)
+	format, err = ParseFormat("HTML")
+	require.NoError(t, err)
+	assert.Equal(t, FormatHTML, format)
+	_, err = ParseFormat("pdf")
+	assert.Error(t, err)
+}
+
+func testGenerate(t *testing.T, format Format) storage.ReadBucket {
+	ctx := context.Background()
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket("testdata")
+	require.NoError(t, err)
+	module, err := bufmodule.NewModuleForBucket(ctx, readWriteBucket)
+	require.NoError(t, err)
+	moduleFileSet, err := bufmodulebuild.NewModuleFileSetBuilder(
+		zap.NewNop(),
+		bufmodule.NewNopModuleReader(),
+	).Build(
+		ctx,
+		module,
+	)
+	require.NoError(t, err)
+	image, annotations, err := bufimagebuild.NewBuilder(zap.NewNop()).Build(ctx, moduleFileSet)
+	require.NoError(t, err)
+	require.Empty(t, annotations)
+	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	require.NoError(t, err)
+	memReadWriteBucket := storagemem.NewReadWriteBucket()
+	require.NoError(t, Generate(ctx, memReadWriteBucket, format, files))
+	return memReadWriteBucket
+}
+
+func testAssertPaths(t *testing.T, readBucket storage.ReadBucket, expectedPaths ...string) {
+	paths, err := storage.AllPaths(context.Background(), readBucket, "")
+	require.NoError(t, err)
+	assert.Equal(t, expectedPaths, paths)
+}
+
+func testGetString(t *testing.T, readBucket storage.ReadBucket, path string) string {
+	data, err := storage.ReadPath(context.Background(), readBucket, path)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,142 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufdoc
+
+import (
+	"bytes"
+	"html/template"
+)
+
+var htmlTemplate = template.Must(template.New("html").Parse(htmlTemplateData))
+
+const htmlTemplateData = `
+{{- define "head" -}}
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ . }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 60em; padding: 0 1em; }
+table { border-collapse: collapse; margin: 1em 0; width: 100%; }
+th, td { border: 1px solid #ddd; padding: 0.4em; text-align: left; vertical-align: top; }
+.comment { white-space: pre-line; }
+.deprecated { color: #b00; font-weight: bold; }
+</style>
+</head>
+<body>
+{{- end -}}
+
+{{- define "foot" }}
+</body>
+</html>
+{{ end -}}
+
+{{- define "typeLink" -}}
+{{ if .Link }}<a href="{{ .Link }}"><code>{{ .Name }}</code></a>{{ else }}<code>{{ .Name }}</code>{{ end }}
+{{- end -}}
+
+{{- define "fieldType" -}}
+{{ if .MapKeyType }}map&lt;<code>{{ .MapKeyType }}</code>, {{ template "typeLink" .Type }}&gt;{{ else }}{{ template "typeLink" .Type }}{{ end }}
+{{- end -}}
+
+{{- define "description" -}}
+{{ if .Deprecated }}<p class="deprecated">Deprecated.</p>{{ end }}{{ with .Comment }}<p class="comment">{{ . }}</p>{{ end }}
+{{- end -}}
+
+{{- define "index" -}}
+{{ template "head" "Protobuf API Documentation" }}
+<h1>Protobuf API Documentation</h1>
+<ul>
+{{- range . }}
+<li><a href="{{ .Path }}">{{ if .Name }}<code>{{ .Name }}</code>{{ else }}Default package{{ end }}</a></li>
+{{- end }}
+</ul>
+{{- template "foot" -}}
+{{- end -}}
+
+{{- define "package" -}}
+{{ template "head" (or .Name "Default package") }}
+<h1>{{ if .Name }}Package <code>{{ .Name }}</code>{{ else }}Default package{{ end }}</h1>
+{{ with .Comment }}<p class="comment">{{ . }}</p>{{ end }}
+<h2>Files</h2>
+<ul>
+{{- range .Files }}
+<li><code>{{ . }}</code></li>
+{{- end }}
+</ul>
+{{- if .Services }}
+<h2>Services</h2>
+{{- range .Services }}
+<h3 id="{{ .Anchor }}">{{ .Name }}</h3>
+{{- template "description" . }}
+<table>
+<tr><th>Method</th><th>Request</th><th>Response</th><th>Description</th></tr>
+{{- range .Methods }}
+<tr><td><code>{{ .Name }}</code></td><td>{{ if .ClientStreaming }}stream {{ end }}{{ template "typeLink" .Request }}</td><td>{{ if .ServerStreaming }}stream {{ end }}{{ template "typeLink" .Response }}</td><td>{{ template "description" . }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- end }}
+{{- if .Messages }}
+<h2>Messages</h2>
+{{- range .Messages }}
+<h3 id="{{ .Anchor }}">{{ .Name }}</h3>
+{{- template "description" . }}
+{{- if .Fields }}
+<table>
+<tr><th>Field</th><th>Number</th><th>Type</th><th>Label</th><th>Description</th></tr>
+{{- range .Fields }}
+<tr><td><code>{{ .Name }}</code></td><td>{{ .Number }}</td><td>{{ template "fieldType" . }}</td><td>{{ if .Oneof }}oneof <code>{{ .Oneof }}</code>{{ else }}{{ .Label }}{{ end }}</td><td>{{ template "description" . }}</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<p>This message has no fields.</p>
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if .Enums }}
+<h2>Enums</h2>
+{{- range .Enums }}
+<h3 id="{{ .Anchor }}">{{ .Name }}</h3>
+{{- template "description" . }}
+<table>
+<tr><th>Name</th><th>Number</th><th>Description</th></tr>
+{{- range .Values }}
+<tr><td><code>{{ .Name }}</code></td><td>{{ .Number }}</td><td>{{ template "description" . }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- end }}
+{{- if .Extensions }}
+<h2>Extensions</h2>
+<table>
+<tr><th>Extension</th><th>Extendee</th><th>Number</th><th>Type</th><th>Label</th><th>Description</th></tr>
+{{- range .Extensions }}
+<tr><td><code>{{ .Name }}</code></td><td>{{ template "typeLink" .Extendee }}</td><td>{{ .Number }}</td><td>{{ template "fieldType" . }}</td><td>{{ .Label }}</td><td>{{ template "description" . }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- template "foot" -}}
+{{- end -}}
+`
+
+func renderHTML(templateName string, data interface{}) ([]byte, error) {
+	buffer := bytes.NewBuffer(nil)
+	if err := htmlTemplate.ExecuteTemplate(buffer, templateName, data); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufdoc
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const (
+	packageTemplateName = "package"
+	indexTemplateName   = "index"
+)
+
+var (
+	markdownTemplate = template.Must(
+		template.New("markdown").Funcs(
+			template.FuncMap{
+				"typeLink":    markdownTypeLink,
+				"fieldType":   markdownFieldType,
+				"description": markdownDescription,
+				"cell":        markdownCell,
+			},
+		).Parse(markdownTemplateData),
+	)
+	markdownMultipleNewlinesRegexp = regexp.MustCompile("\n{3,}")
+)
+
+const markdownTemplateData = `
+{{- define "index" -}}
+# Protobuf API Documentation
+
+{{ range . -}}
+- [{{ if .Name }}` + "`{{ .Name }}`" + `{{ else }}Default package{{ end }}]({{ .Path }})
+{{ end }}
+{{- end -}}
+
+{{- define "package" -}}
+# {{ if .Name }}Package ` + "`{{ .Name }}`" + `{{ else }}Default package{{ end }}
+
+{{ .Comment }}
+
+## Files
+
+{{ range .Files -}}
+- ` + "`{{ . }}`" + `
+{{ end }}
+{{- if .Services }}
+## Services
+{{ range .Services }}
+<a name="{{ .Anchor }}"></a>
+
+### {{ .Name }}
+
+{{ description .Deprecated .Comment }}
+
+| Method | Request | Response | Description |
+| ------ | ------- | -------- | ----------- |
+{{ range .Methods -}}
+| ` + "`{{ .Name }}`" + ` | {{ if .ClientStreaming }}stream {{ end }}{{ typeLink .Request }} | {{ if .ServerStreaming }}stream {{ end }}{{ typeLink .Response }} | {{ cell .Deprecated .Comment }} |
+{{ end }}
+{{- end }}
+{{- end }}
+{{- if .Messages }}
+## Messages
+{{ range .Messages }}
+<a name="{{ .Anchor }}"></a>
+
+### {{ .Name }}
+
+{{ description .Deprecated .Comment }}
+{{ if .Fields }}
+| Field | Number | Type | Label | Description |
+| ----- | ------ | ---- | ----- | ----------- |
+{{ range .Fields -}}
+| ` + "`{{ .Name }}`" + ` | {{ .Number }} | {{ fieldType . }} | {{ if .Oneof }}oneof ` + "`{{ .Oneof }}`" + `{{ else }}{{ .Label }}{{ end }} | {{ cell .Deprecated .Comment }} |
+{{ end }}
+{{- else }}
+This message has no fields.
+{{ end }}
+{{- end }}
+{{- end }}
+{{- if .Enums }}
+## Enums
+{{ range .Enums }}
+<a name="{{ .Anchor }}"></a>
+
+### {{ .Name }}
+
+{{ description .Deprecated .Comment }}
+
+| Name | Number | Description |
+| ---- | ------ | ----------- |
+{{ range .Values -}}
+| ` + "`{{ .Name }}`" + ` | {{ .Number }} | {{ cell .Deprecated .Comment }} |
+{{ end }}
+{{- end }}
+{{- end }}
+{{- if .Extensions }}
+## Extensions
+
+| Extension | Extendee | Number | Type | Label | Description |
+| --------- | -------- | ------ | ---- | ----- | ----------- |
+{{ range .Extensions -}}
+| ` + "`{{ .Name }}`" + ` | {{ typeLink .Extendee }} | {{ .Number }} | {{ fieldType . }} | {{ .Label }} | {{ cell .Deprecated .Comment }} |
+{{ end }}
+{{- end }}
+{{- end -}}
+`
+
+func renderMarkdown(templateName string, data interface{}) ([]byte, error) {
+	buffer := bytes.NewBuffer(nil)
+	if err := markdownTemplate.ExecuteTemplate(buffer, templateName, data); err != nil {
+		return nil, err
+	}
+	output := markdownMultipleNewlinesRegexp.ReplaceAllString(buffer.String(), "\n\n")
+	return []byte(strings.TrimSpace(output) + "\n"), nil
+}
+
+func markdownTypeLink(typeDoc *typeDoc) string {
+	if typeDoc.Link == "" {
+		return "`" + typeDoc.Name + "`"
+	}
+	return fmt.Sprintf("[`%s`](%s)", typeDoc.Name, typeDoc.Link)
+}
+
+func markdownFieldType(fieldDoc *fieldDoc) string {
+	if fieldDoc.MapKeyType != "" {
+		return fmt.Sprintf("map&lt;`%s`, %s&gt;", fieldDoc.MapKeyType, markdownTypeLink(fieldDoc.Type))
+	}
+	return markdownTypeLink(fieldDoc.Type)
+}
+
+func markdownDescription(deprecated bool, comment string) string {
+	if deprecated {
+		return strings.TrimSpace("**Deprecated.**\n\n" + comment)
+	}
+	return comment
+}
+
+// markdownCell returns the description for use in a table cell.
+func markdownCell(deprecated bool, comment string) string {
+	description := strings.ReplaceAll(markdownDescription(deprecated, comment), "|", `\|`)
+	return strings.ReplaceAll(strings.ReplaceAll(description, "\n\n", "<br><br>"), "\n", " ")
+}
@@ -0,0 +1,351 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufdoc
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/private/pkg/protosource"
+)
+
+const (
+	indexName = "index"
+	// defaultPackageDocName is the document name for files without a package.
+	defaultPackageDocName = "_default"
+)
+
+type packageDoc struct {
+	// Name is empty for files without a package.
+	Name       string
+	Path       string
+	Comment    string
+	Files      []string
+	Services   []*serviceDoc
+	Messages   []*messageDoc
+	Enums      []*enumDoc
+	Extensions []*fieldDoc
+}
+
+type serviceDoc struct {
+	Anchor     string
+	Name       string
+	Comment    string
+	Deprecated bool
+	Methods    []*methodDoc
+}
+
+type methodDoc struct {
+	Name            string
+	Comment         string
+	Deprecated      bool
+	Request         *typeDoc
+	Response        *typeDoc
+	ClientStreaming bool
+	ServerStreaming bool
+}
+
+type messageDoc struct {
+	Anchor     string
+	Name       string
+	Comment    string
+	Deprecated bool
+	Fields     []*fieldDoc
+}
+
+type fieldDoc struct {
+	Name   string
+	Number int
+	Label  string
+	Type   *typeDoc
+	// MapKeyType is set if the field is a map, in which case Type is the
+	// type of the map values.
+	MapKeyType string
+	// Oneof is empty unless the field is part of a oneof.
+	Oneof string
+	// Extendee is nil unless the field is an extension.
+	Extendee   *typeDoc
+	Comment    string
+	Deprecated bool
+}
+
+type enumDoc struct {
+	Anchor     string
+	Name       string
+	Comment    string
+	Deprecated bool
+	Values     []*enumValueDoc
+}
+
+type enumValueDoc struct {
+	Name       string
+	Number     int
+	Comment    string
+	Deprecated bool
+}
+
+type typeDoc struct {
+	Name string
+	// Link is empty if the type is not documented.
+	Link string
+}
+
+type packageDocBuilder struct {
+	extension         string
+	fullNameToMessage map[string]protosource.Message
+	fullNameToEnum    map[string]protosource.Enum
+}
+
+func newPackageDocs(files []protosource.File, extension string) ([]*packageDoc, error) {
+	packageToFiles, err := protosource.PackageToFiles(files...)
+	if err != nil {
+		return nil, err
+	}
+	fullNameToMessage, err := protosource.FullNameToMessage(files...)
+	if err != nil {
+		return nil, err
+	}
+	fullNameToEnum, err := protosource.FullNameToEnum(files...)
+	if err != nil {
+		return nil, err
+	}
+	builder := &packageDocBuilder{
+		extension:         extension,
+		fullNameToMessage: fullNameToMessage,
+		fullNameToEnum:    fullNameToEnum,
+	}
+	packages := make([]string, 0, len(packageToFiles))
+	for pkg := range packageToFiles {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+	packageDocs := make([]*packageDoc, len(packages))
+	for i, pkg := range packages {
+		packageDoc, err := builder.newPackageDoc(pkg, packageToFiles[pkg])
+		if err != nil {
+			return nil, err
+		}
+		packageDocs[i] = packageDoc
+	}
+	return packageDocs, nil
+}
+
+func (b *packageDocBuilder) newPackageDoc(pkg string, files []protosource.File) (*packageDoc, error) {
+	packageDoc := &packageDoc{
+		Name: pkg,
+		Path: b.packageDocPath(pkg),
+	}
+	var packageComments []string
+	seenPackageComments := make(map[string]struct{})
+	for _, file := range files {
+		packageDoc.Files = append(packageDoc.Files, file.Path())
+		if packageComment := getComment(file.PackageLocation()); packageComment != "" {
+			if _, ok := seenPackageComments[packageComment]; !ok {
+				seenPackageComments[packageComment] = struct{}{}
+				packageComments = append(packageComments, packageComment)
+			}
+		}
+		for _, service := range file.Services() {
+			packageDoc.Services = append(packageDoc.Services, b.newServiceDoc(pkg, service))
+		}
+		if err := protosource.ForEachMessage(
+			func(message protosource.Message) error {
+				if message.IsMapEntry() {
+					return nil
+				}
+				packageDoc.Messages = append(packageDoc.Messages, b.newMessageDoc(pkg, message))
+				for _, extension := range message.Extensions() {
+					packageDoc.Extensions = append(packageDoc.Extensions, b.newFieldDoc(pkg, extension))
+				}
+				return nil
+			},
+			file,
+		); err != nil {
+			return nil, err
+		}
+		if err := protosource.ForEachEnum(
+			func(enum protosource.Enum) error {
+				packageDoc.Enums = append(packageDoc.Enums, newEnumDoc(enum))
+				return nil
+			},
+			file,
+		); err != nil {
+			return nil, err
+		}
+		for _, extension := range file.Extensions() {
+			packageDoc.Extensions = append(packageDoc.Extensions, b.newFieldDoc(pkg, extension))
+		}
+	}
+	packageDoc.Comment = strings.Join(packageComments, "\n\n")
+	return packageDoc, nil
+}
+
+func (b *packageDocBuilder) newServiceDoc(pkg string, service protosource.Service) *serviceDoc {
+	serviceDoc := &serviceDoc{
+		Anchor:     service.FullName(),
+		Name:       service.Name(),
+		Comment:    getComment(service.Location()),
+		Deprecated: service.Deprecated(),
+	}
+	for _, method := range service.Methods() {
+		serviceDoc.Methods = append(
+			serviceDoc.Methods,
+			&methodDoc{
+				Name:            method.Name(),
+				Comment:         getComment(method.Location()),
+				Deprecated:      method.Deprecated(),
+				Request:         b.newTypeDoc(pkg, method.InputTypeName()),
+				Response:        b.newTypeDoc(pkg, method.OutputTypeName()),
+				ClientStreaming: method.ClientStreaming(),
+				ServerStreaming: method.ServerStreaming(),
+			},
+		)
+	}
+	return serviceDoc
+}
+
+func (b *packageDocBuilder) newMessageDoc(pkg string, message protosource.Message) *messageDoc {
+	messageDoc := &messageDoc{
+		Anchor:     message.FullName(),
+		Name:       message.NestedName(),
+		Comment:    getComment(message.Location()),
+		Deprecated: message.Deprecated(),
+	}
+	for _, field := range message.Fields() {
+		messageDoc.Fields = append(messageDoc.Fields, b.newFieldDoc(pkg, field))
+	}
+	return messageDoc
+}
+
+func (b *packageDocBuilder) newFieldDoc(pkg string, field protosource.Field) *fieldDoc {
+	fieldDoc := &fieldDoc{
+		Name:       field.Name(),
+		Number:     field.Number(),
+		Label:      getLabel(field),
+		Type:       b.newFieldTypeDoc(pkg, field),
+		Comment:    getComment(field.Location()),
+		Deprecated: field.Deprecated(),
+	}
+	if oneof := field.Oneof(); oneof != nil && !field.Proto3Optional() {
+		fieldDoc.Oneof = oneof.Name()
+	}
+	if extendee := field.Extendee(); extendee != "" {
+		fieldDoc.Extendee = b.newTypeDoc(pkg, extendee)
+	}
+	if message, ok := b.fullNameToMessage[field.TypeName()]; ok && message.IsMapEntry() {
+		if fields := message.Fields(); len(fields) == 2 {
+			fieldDoc.Label = ""
+			fieldDoc.MapKeyType = fields[0].Type().String()
+			fieldDoc.Type = b.newFieldTypeDoc(pkg, fields[1])
+		}
+	}
+	return fieldDoc
+}
+
+func (b *packageDocBuilder) newFieldTypeDoc(pkg string, field protosource.Field) *typeDoc {
+	switch field.Type() {
+	case protosource.FieldDescriptorProtoTypeMessage,
+		protosource.FieldDescriptorProtoTypeGroup,
+		protosource.FieldDescriptorProtoTypeEnum:
+		return b.newTypeDoc(pkg, field.TypeName())
+	default:
+		return &typeDoc{
+			Name: field.Type().String(),
+		}
+	}
+}
+
+// newTypeDoc returns a new typeDoc for the fully-qualified message or
+// enum name, linked relative to the document for the given package.
+func (b *packageDocBuilder) newTypeDoc(pkg string, fullName string) *typeDoc {
+	typeDoc := &typeDoc{
+		Name: fullName,
+	}
+	var typePackage string
+	if message, ok := b.fullNameToMessage[fullName]; ok {
+		typePackage = message.File().Package()
+	} else if enum, ok := b.fullNameToEnum[fullName]; ok {
+		typePackage = enum.File().Package()
+	} else {
+		return typeDoc
+	}
+	typeDoc.Link = "#" + fullName
+	if typePackage != pkg {
+		typeDoc.Link = b.packageDocPath(typePackage) + typeDoc.Link
+	}
+	return typeDoc
+}
+
+func (b *packageDocBuilder) packageDocPath(pkg string) string {
+	if pkg == "" {
+		return defaultPackageDocName + b.extension
+	}
+	return pkg + b.extension
+}
+
+func newEnumDoc(enum protosource.Enum) *enumDoc {
+	enumDoc := &enumDoc{
+		Anchor:     enum.FullName(),
+		Name:       enum.NestedName(),
+		Comment:    getComment(enum.Location()),
+		Deprecated: enum.Deprecated(),
+	}
+	for _, enumValue := range enum.Values() {
+		enumDoc.Values = append(
+			enumDoc.Values,
+			&enumValueDoc{
+				Name:       enumValue.Name(),
+				Number:     enumValue.Number(),
+				Comment:    getComment(enumValue.Location()),
+				Deprecated: enumValue.Deprecated(),
+			},
+		)
+	}
+	return enumDoc
+}
+
+func getLabel(field protosource.Field) string {
+	switch field.Label() {
+	case protosource.FieldDescriptorProtoLabelRepeated:
+		return "repeated"
+	case protosource.FieldDescriptorProtoLabelRequired:
+		return "required"
+	default:
+		if field.Proto3Optional() || field.File().Syntax() != protosource.SyntaxProto3 {
+			return "optional"
+		}
+		return ""
+	}
+}
+
+// getComment returns the leading comments for the location, or the
+// trailing comments if there are no leading comments.
+//
+// The leading space that is conventionally put after the comment
+// delimiter is removed from every line.
+func getComment(location protosource.Location) string {
+	if location == nil {
+		return ""
+	}
+	comment := location.LeadingComments()
+	if strings.TrimSpace(comment) == "" {
+		comment = location.TrailingComments()
+	}
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(strings.TrimPrefix(line, " "), " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
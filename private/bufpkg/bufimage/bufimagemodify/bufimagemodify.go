@@ -72,6 +72,15 @@ func NewFileOptionSweeper() Sweeper {
 	return newFileOptionSweeper()
 }
 
+// NewFieldOptionSweeper constructs a new field option Sweeper that removes
+// the SourceCodeInfo_Locations associated with the marks.
+//
+// This must be used with field option modifiers such as JSType, and file
+// option modifiers must use a Sweeper constructed with NewFileOptionSweeper.
+func NewFieldOptionSweeper() Sweeper {
+	return newFieldOptionSweeper()
+}
+
 // Merge merges the given modifiers together so that they are run in the order
 // they are provided. This is particularly useful for constructing a modifier
 // from its initial 'nil' value.
@@ -230,12 +239,101 @@ func GoPackageImportPathForFile(imageFile bufimage.ImageFile, importPathPrefix s
 //  * If the resulting abbreviation is 1 character, add "XX".
 //  * If the resulting abbreviation is "GPB", change it to "GPX".
 //    "GPB" is reserved by Google for the Protocol Buffers implementation.
+//
+// If defaultPrefix is non-empty, it is used instead of the package-derived prefix.
+// Files in the except modules are left unmodified, and files in the moduleOverrides
+// modules use the given prefix.
 func ObjcClassPrefix(
 	logger *zap.Logger,
 	sweeper Sweeper,
+	defaultPrefix string,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]string,
 	overrides map[string]string,
 ) Modifier {
-	return objcClassPrefix(logger, sweeper, overrides)
+	return objcClassPrefix(logger, sweeper, defaultPrefix, except, moduleOverrides, overrides)
+}
+
+// SwiftPrefix returns a Modifier that sets the swift_prefix file option
+// to the given defaultPrefix. Files in the except modules are left unmodified,
+// and files in the moduleOverrides modules use the given prefix.
+func SwiftPrefix(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	defaultPrefix string,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]string,
+	overrides map[string]string,
+) (Modifier, error) {
+	return swiftPrefix(logger, sweeper, defaultPrefix, except, moduleOverrides, overrides)
+}
+
+// PhpClassPrefix returns a Modifier that sets the php_class_prefix file option
+// to the given defaultPrefix. Files in the except modules are left unmodified,
+// and files in the moduleOverrides modules use the given prefix.
+func PhpClassPrefix(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	defaultPrefix string,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]string,
+	overrides map[string]string,
+) (Modifier, error) {
+	return phpClassPrefix(logger, sweeper, defaultPrefix, except, moduleOverrides, overrides)
+}
+
+// CcGenericServices returns a Modifier that sets the cc_generic_services
+// file option to the given value. Files in the except modules are left unmodified,
+// and files in the moduleOverrides modules use the given value.
+func CcGenericServices(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value bool,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]bool,
+	overrides map[string]string,
+) (Modifier, error) {
+	validatedOverrides, err := stringOverridesToBoolOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", CcGenericServicesID, err)
+	}
+	return ccGenericServices(logger, sweeper, value, except, moduleOverrides, validatedOverrides), nil
+}
+
+// JavaGenericServices returns a Modifier that sets the java_generic_services
+// file option to the given value. Files in the except modules are left unmodified,
+// and files in the moduleOverrides modules use the given value.
+func JavaGenericServices(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value bool,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]bool,
+	overrides map[string]string,
+) (Modifier, error) {
+	validatedOverrides, err := stringOverridesToBoolOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", JavaGenericServicesID, err)
+	}
+	return javaGenericServices(logger, sweeper, value, except, moduleOverrides, validatedOverrides), nil
+}
+
+// PyGenericServices returns a Modifier that sets the py_generic_services
+// file option to the given value. Files in the except modules are left unmodified,
+// and files in the moduleOverrides modules use the given value.
+func PyGenericServices(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value bool,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]bool,
+	overrides map[string]string,
+) (Modifier, error) {
+	validatedOverrides, err := stringOverridesToBoolOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", PyGenericServicesID, err)
+	}
+	return pyGenericServices(logger, sweeper, value, except, moduleOverrides, validatedOverrides), nil
 }
 
 // CsharpNamespace returns a Modifier that sets the csharp_namespace file option
@@ -280,11 +378,78 @@ func RubyPackage(
 	return rubyPackage(logger, sweeper, overrides)
 }
 
+// JSType returns a Modifier that sets the jstype field option on all of the
+// 64-bit integer fields contained in the Image to the given defaultValue.
+//
+// The keys of the overrides are either 64-bit integer field types (i.e. "int64"),
+// fully-qualified message names, or fully-qualified field names. Field names take
+// precedence over message names, which take precedence over field types. Files in
+// the except modules are left unmodified.
+func JSType(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	defaultValue descriptorpb.FieldOptions_JSType,
+	except []bufmoduleref.ModuleIdentity,
+	overrides map[string]string,
+) (Modifier, error) {
+	validatedOverrides, err := stringOverridesToJSTypeOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid override for %s: %w", JSTypeID, err)
+	}
+	return jsType(logger, sweeper, defaultValue, except, validatedOverrides), nil
+}
+
 // isWellKnownType returns true if the given path is one of the well-known types.
 func isWellKnownType(ctx context.Context, imageFile bufimage.ImageFile) bool {
 	return datawkt.Exists(imageFile.Path())
 }
 
+// walkFields calls f for each field and extension in the given file, including
+// the fields of nested messages. The fully-qualified names of the field and
+// its parent message are passed to f, along with the SourceCodeInfo path of
+// the field. The parent message name is empty for top-level extensions.
+func walkFields(
+	fileDescriptor *descriptorpb.FileDescriptorProto,
+	f func(fieldName string, messageName string, field *descriptorpb.FieldDescriptorProto, path []int32),
+) {
+	prefix := fileDescriptor.GetPackage()
+	if prefix != "" {
+		prefix += "."
+	}
+	for i, extension := range fileDescriptor.GetExtension() {
+		f(prefix+extension.GetName(), "", extension, []int32{7, int32(i)})
+	}
+	for i, message := range fileDescriptor.GetMessageType() {
+		walkMessageFields(prefix, message, []int32{4, int32(i)}, f)
+	}
+}
+
+func walkMessageFields(
+	prefix string,
+	message *descriptorpb.DescriptorProto,
+	path []int32,
+	f func(fieldName string, messageName string, field *descriptorpb.FieldDescriptorProto, path []int32),
+) {
+	messageName := prefix + message.GetName()
+	for i, field := range message.GetField() {
+		f(messageName+"."+field.GetName(), messageName, field, appendPath(path, 2, int32(i)))
+	}
+	for i, extension := range message.GetExtension() {
+		f(messageName+"."+extension.GetName(), messageName, extension, appendPath(path, 6, int32(i)))
+	}
+	for i, nestedMessage := range message.GetNestedType() {
+		walkMessageFields(messageName+".", nestedMessage, appendPath(path, 3, int32(i)), f)
+	}
+}
+
+// appendPath returns a new path with the given elements appended to it,
+// so that the given path is never modified.
+func appendPath(path []int32, elems ...int32) []int32 {
+	newPath := make([]int32, 0, len(path)+len(elems))
+	newPath = append(newPath, path...)
+	return append(newPath, elems...)
+}
+
 // int32SliceIsEqual returns true if x and y contain the same elements.
 func int32SliceIsEqual(x []int32, y []int32) bool {
 	if len(x) != len(y) {
@@ -321,3 +486,15 @@ func stringOverridesToOptimizeModeOverrides(stringOverrides map[string]string) (
 	}
 	return validatedOverrides, nil
 }
+
+func stringOverridesToJSTypeOverrides(stringOverrides map[string]string) (map[string]descriptorpb.FieldOptions_JSType, error) {
+	validatedOverrides := make(map[string]descriptorpb.FieldOptions_JSType, len(stringOverrides))
+	for name, stringOverride := range stringOverrides {
+		jsType, ok := descriptorpb.FieldOptions_JSType_value[stringOverride]
+		if !ok {
+			return nil, fmt.Errorf("invalid jstype %s set for %s", stringOverride, name)
+		}
+		validatedOverrides[name] = descriptorpb.FieldOptions_JSType(jsType)
+	}
+	return validatedOverrides, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// CcGenericServicesID is the ID of the cc_generic_services modifier.
+const CcGenericServicesID = "CC_GENERIC_SERVICES"
+
+// ccGenericServicesPath is the SourceCodeInfo path for the cc_generic_services option.
+// https://github.com/protocolbuffers/protobuf/blob/61689226c0e3ec88287eaed66164614d9c4f2bf7/src/google/protobuf/descriptor.proto#L404
+var ccGenericServicesPath = []int32{8, 16}
+
+func ccGenericServices(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value bool,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]bool,
+	overrides map[string]bool,
+) Modifier {
+	return boolModuleOption(
+		logger,
+		sweeper,
+		CcGenericServicesID,
+		ccGenericServicesPath,
+		descriptorpb.Default_FileOptions_CcGenericServices,
+		value,
+		except,
+		moduleOverrides,
+		overrides,
+		func(options *descriptorpb.FileOptions) bool {
+			return options.GetCcGenericServices()
+		},
+		func(options *descriptorpb.FileOptions, value bool) {
+			options.CcGenericServices = proto.Bool(value)
+		},
+	)
+}
@@ -0,0 +1,97 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type fieldOptionSweeper struct {
+	// Filepath -> SourceCodeInfo_Location.Path keys.
+	sourceCodeInfoPaths map[string]map[string]struct{}
+}
+
+func newFieldOptionSweeper() *fieldOptionSweeper {
+	return &fieldOptionSweeper{
+		sourceCodeInfoPaths: make(map[string]map[string]struct{}),
+	}
+}
+
+// mark is used to mark the given SourceCodeInfo_Location indices for
+// deletion. This method should be called in each of the field option
+// modifiers.
+func (s *fieldOptionSweeper) mark(imageFilePath string, path []int32) {
+	paths, ok := s.sourceCodeInfoPaths[imageFilePath]
+	if !ok {
+		paths = make(map[string]struct{})
+		s.sourceCodeInfoPaths[imageFilePath] = paths
+	}
+	paths[getPathKey(path)] = struct{}{}
+}
+
+// Sweep applies all of the marks and sweeps the field option SourceCodeInfo_Locations.
+func (s *fieldOptionSweeper) Sweep(ctx context.Context, image bufimage.Image) error {
+	for _, imageFile := range image.Files() {
+		descriptor := imageFile.Proto()
+		if descriptor.SourceCodeInfo == nil {
+			continue
+		}
+		paths, ok := s.sourceCodeInfoPaths[imageFile.Path()]
+		if !ok {
+			continue
+		}
+		// Unlike file options, all of the options of a field are declared
+		// within a single set of brackets, so the parent location
+		// (i.e. [4, 0, 2, 0, 8]) is shared between them. The parent
+		// location is only removed if none of its other options remain.
+		parentPathKeys := make(map[string]struct{}, len(paths))
+		locations := make([]*descriptorpb.SourceCodeInfo_Location, 0, len(descriptor.SourceCodeInfo.Location))
+		for _, location := range descriptor.SourceCodeInfo.Location {
+			if _, ok := paths[getPathKey(location.Path)]; ok {
+				parentPathKeys[getPathKey(location.Path[:len(location.Path)-1])] = struct{}{}
+				continue
+			}
+			locations = append(locations, location)
+		}
+		if len(parentPathKeys) == 0 {
+			continue
+		}
+		remainingParentPathKeys := make(map[string]struct{}, len(parentPathKeys))
+		for _, location := range locations {
+			if len(location.Path) < 2 {
+				continue
+			}
+			parentPathKey := getPathKey(location.Path[:len(location.Path)-1])
+			if _, ok := parentPathKeys[parentPathKey]; ok {
+				remainingParentPathKeys[parentPathKey] = struct{}{}
+			}
+		}
+		sweptLocations := make([]*descriptorpb.SourceCodeInfo_Location, 0, len(locations))
+		for _, location := range locations {
+			pathKey := getPathKey(location.Path)
+			if _, ok := parentPathKeys[pathKey]; ok {
+				if _, ok := remainingParentPathKeys[pathKey]; !ok {
+					continue
+				}
+			}
+			sweptLocations = append(sweptLocations, location)
+		}
+		descriptor.SourceCodeInfo.Location = sweptLocations
+	}
+	return nil
+}
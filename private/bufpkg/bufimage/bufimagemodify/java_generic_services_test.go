@@ -0,0 +1,184 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestJavaGenericServicesEmptyOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "emptyoptions")
+	t.Run("with default value", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		assertFileOptionSourceCodeInfoEmpty(t, image, javaGenericServicesPath, true)
+
+		sweeper := NewFileOptionSweeper()
+		javaGenericServicesModifier, err := JavaGenericServices(zap.NewNop(), sweeper, false, nil, nil, nil)
+		require.NoError(t, err)
+
+		modifier := NewMultiModifier(javaGenericServicesModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, testGetImage(t, dirPath, true), image)
+	})
+
+	t.Run("with non-default value", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		assertFileOptionSourceCodeInfoEmpty(t, image, javaGenericServicesPath, true)
+
+		sweeper := NewFileOptionSweeper()
+		javaGenericServicesModifier, err := JavaGenericServices(zap.NewNop(), sweeper, true, nil, nil, nil)
+		require.NoError(t, err)
+
+		modifier := NewMultiModifier(javaGenericServicesModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+
+		for _, imageFile := range image.Files() {
+			descriptor := imageFile.Proto()
+			assert.True(t, descriptor.GetOptions().GetJavaGenericServices())
+		}
+		assertFileOptionSourceCodeInfoEmpty(t, image, javaGenericServicesPath, true)
+	})
+}
+
+func TestJavaGenericServicesAllOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "alloptions")
+	testModuleIdentity, err := bufmoduleref.NewModuleIdentity(
+		testRemote,
+		testRepositoryOwner,
+		testRepositoryName,
+	)
+	require.NoError(t, err)
+
+	t.Run("with value", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		assertFileOptionSourceCodeInfoNotEmpty(t, image, javaGenericServicesPath)
+
+		sweeper := NewFileOptionSweeper()
+		javaGenericServicesModifier, err := JavaGenericServices(zap.NewNop(), sweeper, true, nil, nil, nil)
+		require.NoError(t, err)
+
+		modifier := NewMultiModifier(javaGenericServicesModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+
+		for _, imageFile := range image.Files() {
+			descriptor := imageFile.Proto()
+			assert.True(t, descriptor.GetOptions().GetJavaGenericServices())
+		}
+		assertFileOptionSourceCodeInfoEmpty(t, image, javaGenericServicesPath, true)
+	})
+
+	t.Run("with except", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		sweeper := NewFileOptionSweeper()
+		javaGenericServicesModifier, err := JavaGenericServices(
+			zap.NewNop(),
+			sweeper,
+			true,
+			[]bufmoduleref.ModuleIdentity{testModuleIdentity},
+			nil,
+			nil,
+		)
+		require.NoError(t, err)
+
+		modifier := NewMultiModifier(javaGenericServicesModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, testGetImage(t, dirPath, true), image)
+	})
+
+	t.Run("with module override", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, false)
+		sweeper := NewFileOptionSweeper()
+		modifier, err := JavaGenericServices(
+			zap.NewNop(),
+			sweeper,
+			false,
+			nil,
+			map[bufmoduleref.ModuleIdentity]bool{testModuleIdentity: true},
+			nil,
+		)
+		require.NoError(t, err)
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+
+		for _, imageFile := range image.Files() {
+			descriptor := imageFile.Proto()
+			assert.True(t, descriptor.GetOptions().GetJavaGenericServices())
+		}
+	})
+
+	t.Run("with module override and per-file overrides", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, false)
+		sweeper := NewFileOptionSweeper()
+		modifier, err := JavaGenericServices(
+			zap.NewNop(),
+			sweeper,
+			true,
+			nil,
+			map[bufmoduleref.ModuleIdentity]bool{testModuleIdentity: true},
+			map[string]string{"a.proto": "false"},
+		)
+		require.NoError(t, err)
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+
+		for _, imageFile := range image.Files() {
+			descriptor := imageFile.Proto()
+			assert.False(t, descriptor.GetOptions().GetJavaGenericServices())
+		}
+	})
+
+	t.Run("with invalid per-file override", func(t *testing.T) {
+		t.Parallel()
+		_, err := JavaGenericServices(zap.NewNop(), NewFileOptionSweeper(), true, nil, nil, map[string]string{"a.proto": "foo"})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,120 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// JSTypeID is the ID of the jstype modifier.
+const JSTypeID = "JSTYPE"
+
+// jsTypeOptionPath is the SourceCodeInfo path for the jstype option relative to its field.
+// https://github.com/protocolbuffers/protobuf/blob/61689226c0e3ec88287eaed66164614d9c4f2bf7/src/google/protobuf/descriptor.proto
+var jsTypeOptionPath = []int32{8, 6}
+
+// jsTypeFieldTypes are the field types that the jstype option can be set on,
+// keyed by the name used for type overrides.
+var jsTypeFieldTypes = map[string]descriptorpb.FieldDescriptorProto_Type{
+	"int64":    descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	"uint64":   descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+	"sint64":   descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+	"fixed64":  descriptorpb.FieldDescriptorProto_TYPE_FIXED64,
+	"sfixed64": descriptorpb.FieldDescriptorProto_TYPE_SFIXED64,
+}
+
+func jsType(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	defaultValue descriptorpb.FieldOptions_JSType,
+	except []bufmoduleref.ModuleIdentity,
+	overrides map[string]descriptorpb.FieldOptions_JSType,
+) Modifier {
+	exceptModuleIdentityStrings := moduleIdentityStringSet(except)
+	typeOverrides := make(map[descriptorpb.FieldDescriptorProto_Type]descriptorpb.FieldOptions_JSType)
+	nameOverrides := make(map[string]descriptorpb.FieldOptions_JSType)
+	for key, value := range overrides {
+		if fieldType, ok := jsTypeFieldTypes[key]; ok {
+			typeOverrides[fieldType] = value
+			continue
+		}
+		nameOverrides[key] = value
+	}
+	return ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			seenNameOverrides := make(map[string]struct{}, len(nameOverrides))
+			for _, imageFile := range image.Files() {
+				if shouldSkipModuleOptionForFile(ctx, imageFile, exceptModuleIdentityStrings) {
+					continue
+				}
+				walkFields(imageFile.Proto(), func(fieldName string, messageName string, field *descriptorpb.FieldDescriptorProto, path []int32) {
+					if !isJSTypeFieldType(field.GetType()) {
+						return
+					}
+					value := defaultValue
+					if typeOverrideValue, ok := typeOverrides[field.GetType()]; ok {
+						value = typeOverrideValue
+					}
+					if messageOverrideValue, ok := nameOverrides[messageName]; ok {
+						value = messageOverrideValue
+						seenNameOverrides[messageName] = struct{}{}
+					}
+					if fieldOverrideValue, ok := nameOverrides[fieldName]; ok {
+						value = fieldOverrideValue
+						seenNameOverrides[fieldName] = struct{}{}
+					}
+					options := field.GetOptions()
+					switch {
+					case options != nil && options.Jstype != nil && options.GetJstype() == value:
+						// The option is already set to the same value, don't do anything.
+						return
+					case (options == nil || options.Jstype == nil) && descriptorpb.Default_FieldOptions_Jstype == value:
+						// The option is not set, but the value we want to set is the
+						// same as the default, don't do anything.
+						return
+					}
+					if options == nil {
+						field.Options = &descriptorpb.FieldOptions{}
+					}
+					field.Options.Jstype = value.Enum()
+					if sweeper != nil {
+						sweeper.mark(imageFile.Path(), appendPath(path, jsTypeOptionPath...))
+					}
+				})
+			}
+			for nameOverride := range nameOverrides {
+				if _, ok := seenNameOverrides[nameOverride]; !ok {
+					logger.Sugar().Warnf("%s override for %q was unused", JSTypeID, nameOverride)
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// isJSTypeFieldType returns true if the jstype option applies to the given field type.
+func isJSTypeFieldType(fieldType descriptorpb.FieldDescriptorProto_Type) bool {
+	for _, jsTypeFieldType := range jsTypeFieldTypes {
+		if fieldType == jsTypeFieldType {
+			return true
+		}
+	}
+	return false
+}
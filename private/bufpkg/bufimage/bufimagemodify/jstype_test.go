@@ -0,0 +1,192 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestJSType(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "jstypeoptions")
+	t.Run("with SourceCodeInfo", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		assertSourceCodeInfoLocation(t, image, []int32{4, 0, 2, 3, 8}, true)
+		assertSourceCodeInfoLocation(t, image, []int32{4, 0, 2, 3, 8, 6}, true)
+		assertSourceCodeInfoLocation(t, image, []int32{4, 0, 2, 4, 8}, true)
+		assertSourceCodeInfoLocation(t, image, []int32{4, 0, 2, 4, 8, 6}, true)
+
+		sweeper := NewFieldOptionSweeper()
+		jsTypeModifier, err := JSType(zap.NewNop(), sweeper, descriptorpb.FieldOptions_JS_STRING, nil, nil)
+		require.NoError(t, err)
+
+		modifier := NewMultiModifier(jsTypeModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			map[string]descriptorpb.FieldOptions_JSType{
+				"acme.weather.v1.Reading.id":           descriptorpb.FieldOptions_JS_STRING,
+				"acme.weather.v1.Reading.count":        descriptorpb.FieldOptions_JS_STRING,
+				"acme.weather.v1.Reading.checksum":     descriptorpb.FieldOptions_JS_STRING,
+				"acme.weather.v1.Reading.offset":       descriptorpb.FieldOptions_JS_STRING,
+				"acme.weather.v1.Reading.Sample.value": descriptorpb.FieldOptions_JS_STRING,
+			},
+			testGetJSTypes(image),
+		)
+		// The deprecated option remains, so only the jstype location is removed.
+		assertSourceCodeInfoLocation(t, image, []int32{4, 0, 2, 3, 8}, true)
+		assertSourceCodeInfoLocation(t, image, []int32{4, 0, 2, 3, 8, 6}, false)
+		// The offset field already has the same value, so it is untouched.
+		assertSourceCodeInfoLocation(t, image, []int32{4, 0, 2, 4, 8}, true)
+		assertSourceCodeInfoLocation(t, image, []int32{4, 0, 2, 4, 8, 6}, true)
+	})
+
+	t.Run("with SourceCodeInfo and overrides", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+
+		sweeper := NewFieldOptionSweeper()
+		jsTypeModifier, err := JSType(
+			zap.NewNop(),
+			sweeper,
+			descriptorpb.FieldOptions_JS_STRING,
+			nil,
+			map[string]string{
+				"uint64":                         "JS_NUMBER",
+				"acme.weather.v1.Reading.Sample": "JS_NUMBER",
+				"acme.weather.v1.Reading.offset": "JS_NORMAL",
+			},
+		)
+		require.NoError(t, err)
+
+		modifier := NewMultiModifier(jsTypeModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			map[string]descriptorpb.FieldOptions_JSType{
+				"acme.weather.v1.Reading.id":           descriptorpb.FieldOptions_JS_STRING,
+				"acme.weather.v1.Reading.count":        descriptorpb.FieldOptions_JS_NUMBER,
+				"acme.weather.v1.Reading.checksum":     descriptorpb.FieldOptions_JS_STRING,
+				"acme.weather.v1.Reading.offset":       descriptorpb.FieldOptions_JS_NORMAL,
+				"acme.weather.v1.Reading.Sample.value": descriptorpb.FieldOptions_JS_NUMBER,
+			},
+			testGetJSTypes(image),
+		)
+		// The jstype option was the only option on the offset field.
+		assertSourceCodeInfoLocation(t, image, []int32{4, 0, 2, 4, 8}, false)
+		assertSourceCodeInfoLocation(t, image, []int32{4, 0, 2, 4, 8, 6}, false)
+	})
+
+	t.Run("without SourceCodeInfo", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, false)
+
+		sweeper := NewFieldOptionSweeper()
+		modifier, err := JSType(zap.NewNop(), sweeper, descriptorpb.FieldOptions_JS_NUMBER, nil, nil)
+		require.NoError(t, err)
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		for _, jsType := range testGetJSTypes(image) {
+			assert.Equal(t, descriptorpb.FieldOptions_JS_NUMBER, jsType)
+		}
+	})
+
+	t.Run("with except", func(t *testing.T) {
+		t.Parallel()
+		testModuleIdentity, err := bufmoduleref.NewModuleIdentity(
+			testRemote,
+			testRepositoryOwner,
+			testRepositoryName,
+		)
+		require.NoError(t, err)
+		image := testGetImage(t, dirPath, true)
+
+		sweeper := NewFieldOptionSweeper()
+		jsTypeModifier, err := JSType(
+			zap.NewNop(),
+			sweeper,
+			descriptorpb.FieldOptions_JS_STRING,
+			[]bufmoduleref.ModuleIdentity{testModuleIdentity},
+			nil,
+		)
+		require.NoError(t, err)
+
+		modifier := NewMultiModifier(jsTypeModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, testGetImage(t, dirPath, true), image)
+	})
+
+	t.Run("with invalid override", func(t *testing.T) {
+		t.Parallel()
+		_, err := JSType(
+			zap.NewNop(),
+			NewFieldOptionSweeper(),
+			descriptorpb.FieldOptions_JS_STRING,
+			nil,
+			map[string]string{"int64": "JS_BIGINT"},
+		)
+		require.Error(t, err)
+	})
+}
+
+func testGetJSTypes(image bufimage.Image) map[string]descriptorpb.FieldOptions_JSType {
+	jsTypes := make(map[string]descriptorpb.FieldOptions_JSType)
+	for _, imageFile := range image.Files() {
+		walkFields(imageFile.Proto(), func(fieldName string, _ string, field *descriptorpb.FieldDescriptorProto, _ []int32) {
+			if isJSTypeFieldType(field.GetType()) {
+				jsTypes[fieldName] = field.GetOptions().GetJstype()
+			}
+		})
+	}
+	return jsTypes
+}
+
+func assertSourceCodeInfoLocation(t *testing.T, image bufimage.Image, path []int32, expected bool) {
+	for _, imageFile := range image.Files() {
+		var hasLocation bool
+		for _, location := range imageFile.Proto().GetSourceCodeInfo().GetLocation() {
+			if int32SliceIsEqual(location.Path, path) {
+				hasLocation = true
+				break
+			}
+		}
+		assert.Equal(t, expected, hasLocation, "location %v", path)
+	}
+}
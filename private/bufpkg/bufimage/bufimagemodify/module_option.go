@@ -0,0 +1,209 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// stringModuleOption returns a Modifier that sets a string file option
+// according to the given default value, module exceptions, module overrides,
+// and per-file overrides.
+//
+// The defaultValue function is used for every file that is not part of an
+// overridden module, and files that resolve to an empty value are left unmodified.
+func stringModuleOption(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	modifierID string,
+	optionPath []int32,
+	defaultValue func(bufimage.ImageFile) string,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]string,
+	overrides map[string]string,
+	setOption func(*descriptorpb.FileOptions, string),
+) Modifier {
+	exceptModuleIdentityStrings := moduleIdentityStringSet(except)
+	overrideModuleIdentityStrings := make(map[string]string, len(moduleOverrides))
+	for moduleIdentity, value := range moduleOverrides {
+		overrideModuleIdentityStrings[moduleIdentity.IdentityString()] = value
+	}
+	return ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			unusedModuleIdentityStrings := make(map[string]struct{}, len(overrideModuleIdentityStrings))
+			for moduleIdentityString := range overrideModuleIdentityStrings {
+				unusedModuleIdentityStrings[moduleIdentityString] = struct{}{}
+			}
+			unusedOverrideFiles := make(map[string]struct{}, len(overrides))
+			for overrideFile := range overrides {
+				unusedOverrideFiles[overrideFile] = struct{}{}
+			}
+			for _, imageFile := range image.Files() {
+				var value string
+				var hasModuleOverride bool
+				if moduleIdentity := imageFile.ModuleIdentity(); moduleIdentity != nil {
+					moduleIdentityString := moduleIdentity.IdentityString()
+					if moduleOverrideValue, ok := overrideModuleIdentityStrings[moduleIdentityString]; ok {
+						value = moduleOverrideValue
+						hasModuleOverride = true
+						delete(unusedModuleIdentityStrings, moduleIdentityString)
+					}
+				}
+				if !hasModuleOverride {
+					value = defaultValue(imageFile)
+				}
+				if overrideValue, ok := overrides[imageFile.Path()]; ok {
+					value = overrideValue
+					delete(unusedOverrideFiles, imageFile.Path())
+				}
+				if value == "" || shouldSkipModuleOptionForFile(ctx, imageFile, exceptModuleIdentityStrings) {
+					continue
+				}
+				descriptor := imageFile.Proto()
+				if descriptor.Options == nil {
+					descriptor.Options = &descriptorpb.FileOptions{}
+				}
+				setOption(descriptor.Options, value)
+				if sweeper != nil {
+					sweeper.mark(imageFile.Path(), optionPath)
+				}
+			}
+			warnUnusedModuleOptionOverrides(logger, modifierID, unusedModuleIdentityStrings, unusedOverrideFiles)
+			return nil
+		},
+	)
+}
+
+// boolModuleOption returns a Modifier that sets a bool file option
+// according to the given default value, module exceptions, module overrides,
+// and per-file overrides.
+//
+// The option is left unset if it is not already set and the resolved value
+// is equal to the option's defaultOptionValue.
+func boolModuleOption(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	modifierID string,
+	optionPath []int32,
+	defaultOptionValue bool,
+	value bool,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]bool,
+	overrides map[string]bool,
+	getOption func(*descriptorpb.FileOptions) bool,
+	setOption func(*descriptorpb.FileOptions, bool),
+) Modifier {
+	exceptModuleIdentityStrings := moduleIdentityStringSet(except)
+	overrideModuleIdentityStrings := make(map[string]bool, len(moduleOverrides))
+	for moduleIdentity, value := range moduleOverrides {
+		overrideModuleIdentityStrings[moduleIdentity.IdentityString()] = value
+	}
+	return ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			unusedModuleIdentityStrings := make(map[string]struct{}, len(overrideModuleIdentityStrings))
+			for moduleIdentityString := range overrideModuleIdentityStrings {
+				unusedModuleIdentityStrings[moduleIdentityString] = struct{}{}
+			}
+			unusedOverrideFiles := make(map[string]struct{}, len(overrides))
+			for overrideFile := range overrides {
+				unusedOverrideFiles[overrideFile] = struct{}{}
+			}
+			for _, imageFile := range image.Files() {
+				modifierValue := value
+				if moduleIdentity := imageFile.ModuleIdentity(); moduleIdentity != nil {
+					moduleIdentityString := moduleIdentity.IdentityString()
+					if moduleOverrideValue, ok := overrideModuleIdentityStrings[moduleIdentityString]; ok {
+						modifierValue = moduleOverrideValue
+						delete(unusedModuleIdentityStrings, moduleIdentityString)
+					}
+				}
+				if overrideValue, ok := overrides[imageFile.Path()]; ok {
+					modifierValue = overrideValue
+					delete(unusedOverrideFiles, imageFile.Path())
+				}
+				if shouldSkipModuleOptionForFile(ctx, imageFile, exceptModuleIdentityStrings) {
+					continue
+				}
+				descriptor := imageFile.Proto()
+				options := descriptor.GetOptions()
+				switch {
+				case options != nil && getOption(options) == modifierValue:
+					// The option is already set to the same value, don't do anything.
+					continue
+				case options == nil && defaultOptionValue == modifierValue:
+					// The option is not set, but the value we want to set is the
+					// same as the default, don't do anything.
+					continue
+				}
+				if options == nil {
+					descriptor.Options = &descriptorpb.FileOptions{}
+				}
+				setOption(descriptor.Options, modifierValue)
+				if sweeper != nil {
+					sweeper.mark(imageFile.Path(), optionPath)
+				}
+			}
+			warnUnusedModuleOptionOverrides(logger, modifierID, unusedModuleIdentityStrings, unusedOverrideFiles)
+			return nil
+		},
+	)
+}
+
+// shouldSkipModuleOptionForFile returns true if the given file is a well-known type
+// or belongs to one of the excepted modules.
+func shouldSkipModuleOptionForFile(
+	ctx context.Context,
+	imageFile bufimage.ImageFile,
+	exceptModuleIdentityStrings map[string]struct{},
+) bool {
+	if isWellKnownType(ctx, imageFile) {
+		return true
+	}
+	if moduleIdentity := imageFile.ModuleIdentity(); moduleIdentity != nil {
+		if _, ok := exceptModuleIdentityStrings[moduleIdentity.IdentityString()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleIdentityStringSet converts the bufmoduleref.ModuleIdentity types into
+// strings so that they're comparable.
+func moduleIdentityStringSet(moduleIdentities []bufmoduleref.ModuleIdentity) map[string]struct{} {
+	moduleIdentityStrings := make(map[string]struct{}, len(moduleIdentities))
+	for _, moduleIdentity := range moduleIdentities {
+		moduleIdentityStrings[moduleIdentity.IdentityString()] = struct{}{}
+	}
+	return moduleIdentityStrings
+}
+
+func warnUnusedModuleOptionOverrides(
+	logger *zap.Logger,
+	modifierID string,
+	unusedModuleIdentityStrings map[string]struct{},
+	unusedOverrideFiles map[string]struct{},
+) {
+	for moduleIdentityString := range unusedModuleIdentityStrings {
+		logger.Sugar().Warnf("%s module override for %q was unused", modifierID, moduleIdentityString)
+	}
+	for overrideFile := range unusedOverrideFiles {
+		logger.Sugar().Warnf("%s override for %q was unused", modifierID, overrideFile)
+	}
+}
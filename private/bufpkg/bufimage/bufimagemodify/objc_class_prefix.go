@@ -15,11 +15,11 @@
 package bufimagemodify
 
 import (
-	"context"
 	"strings"
 	"unicode"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/pkg/protoversion"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/proto"
@@ -36,53 +36,32 @@ var objcClassPrefixPath = []int32{8, 36}
 func objcClassPrefix(
 	logger *zap.Logger,
 	sweeper Sweeper,
+	defaultPrefix string,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]string,
 	overrides map[string]string,
 ) Modifier {
-	return ModifierFunc(
-		func(ctx context.Context, image bufimage.Image) error {
-			seenOverrideFiles := make(map[string]struct{}, len(overrides))
-			for _, imageFile := range image.Files() {
-				objcClassPrefixValue := objcClassPrefixValue(imageFile)
-				if overrideValue, ok := overrides[imageFile.Path()]; ok {
-					objcClassPrefixValue = overrideValue
-					seenOverrideFiles[imageFile.Path()] = struct{}{}
-				}
-				if err := objcClassPrefixForFile(ctx, sweeper, imageFile, objcClassPrefixValue); err != nil {
-					return err
-				}
-			}
-			for overrideFile := range overrides {
-				if _, ok := seenOverrideFiles[overrideFile]; !ok {
-					logger.Sugar().Warnf("%s override for %q was unused", ObjcClassPrefixID, overrideFile)
-				}
-			}
-			return nil
+	defaultValue := objcClassPrefixValue
+	if defaultPrefix != "" {
+		defaultValue = func(bufimage.ImageFile) string {
+			return defaultPrefix
+		}
+	}
+	return stringModuleOption(
+		logger,
+		sweeper,
+		ObjcClassPrefixID,
+		objcClassPrefixPath,
+		defaultValue,
+		except,
+		moduleOverrides,
+		overrides,
+		func(options *descriptorpb.FileOptions, value string) {
+			options.ObjcClassPrefix = proto.String(value)
 		},
 	)
 }
 
-func objcClassPrefixForFile(
-	ctx context.Context,
-	sweeper Sweeper,
-	imageFile bufimage.ImageFile,
-	objcClassPrefixValue string,
-) error {
-	descriptor := imageFile.Proto()
-	if isWellKnownType(ctx, imageFile) || objcClassPrefixValue == "" {
-		// This is a well-known type or we could not resolve a non-empty objc_class_prefix
-		// value, so this is a no-op.
-		return nil
-	}
-	if descriptor.Options == nil {
-		descriptor.Options = &descriptorpb.FileOptions{}
-	}
-	descriptor.Options.ObjcClassPrefix = proto.String(objcClassPrefixValue)
-	if sweeper != nil {
-		sweeper.mark(imageFile.Path(), objcClassPrefixPath)
-	}
-	return nil
-}
-
 // objcClassPrefixValue returns the objc_class_prefix for the given ImageFile based on its
 // package declaration. If the image file doesn't have a package declaration, an
 // empty string is returned.
@@ -0,0 +1,60 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// PhpClassPrefixID is the ID of the php_class_prefix modifier.
+const PhpClassPrefixID = "PHP_CLASS_PREFIX"
+
+// phpClassPrefixPath is the SourceCodeInfo path for the php_class_prefix option.
+// https://github.com/protocolbuffers/protobuf/blob/61689226c0e3ec88287eaed66164614d9c4f2bf7/src/google/protobuf/descriptor.proto#L438
+var phpClassPrefixPath = []int32{8, 40}
+
+func phpClassPrefix(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	defaultPrefix string,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]string,
+	overrides map[string]string,
+) (Modifier, error) {
+	if defaultPrefix == "" {
+		return nil, fmt.Errorf("a non-empty prefix is required")
+	}
+	return stringModuleOption(
+		logger,
+		sweeper,
+		PhpClassPrefixID,
+		phpClassPrefixPath,
+		func(bufimage.ImageFile) string {
+			return defaultPrefix
+		},
+		except,
+		moduleOverrides,
+		overrides,
+		func(options *descriptorpb.FileOptions, value string) {
+			options.PhpClassPrefix = proto.String(value)
+		},
+	), nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// PyGenericServicesID is the ID of the py_generic_services modifier.
+const PyGenericServicesID = "PY_GENERIC_SERVICES"
+
+// pyGenericServicesPath is the SourceCodeInfo path for the py_generic_services option.
+// https://github.com/protocolbuffers/protobuf/blob/61689226c0e3ec88287eaed66164614d9c4f2bf7/src/google/protobuf/descriptor.proto#L406
+var pyGenericServicesPath = []int32{8, 18}
+
+func pyGenericServices(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	value bool,
+	except []bufmoduleref.ModuleIdentity,
+	moduleOverrides map[bufmoduleref.ModuleIdentity]bool,
+	overrides map[string]bool,
+) Modifier {
+	return boolModuleOption(
+		logger,
+		sweeper,
+		PyGenericServicesID,
+		pyGenericServicesPath,
+		descriptorpb.Default_FileOptions_PyGenericServices,
+		value,
+		except,
+		moduleOverrides,
+		overrides,
+		func(options *descriptorpb.FileOptions) bool {
+			return options.GetPyGenericServices()
+		},
+		func(options *descriptorpb.FileOptions, value bool) {
+			options.PyGenericServices = proto.Bool(value)
+		},
+	)
+}
@@ -0,0 +1,166 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSwiftPrefixEmptyOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "emptyoptions")
+	t.Run("with SourceCodeInfo", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		assertFileOptionSourceCodeInfoEmpty(t, image, swiftPrefixPath, true)
+
+		sweeper := NewFileOptionSweeper()
+		swiftPrefixModifier, err := SwiftPrefix(zap.NewNop(), sweeper, "ACME", nil, nil, nil)
+		require.NoError(t, err)
+
+		modifier := NewMultiModifier(swiftPrefixModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.NotEqual(t, testGetImage(t, dirPath, true), image)
+
+		for _, imageFile := range image.Files() {
+			descriptor := imageFile.Proto()
+			assert.Equal(t, "ACME", descriptor.GetOptions().GetSwiftPrefix())
+		}
+		assertFileOptionSourceCodeInfoEmpty(t, image, swiftPrefixPath, true)
+	})
+
+	t.Run("without SourceCodeInfo and with per-file overrides", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, false)
+		assertFileOptionSourceCodeInfoEmpty(t, image, swiftPrefixPath, false)
+
+		sweeper := NewFileOptionSweeper()
+		modifier, err := SwiftPrefix(zap.NewNop(), sweeper, "ACME", nil, nil, map[string]string{"a.proto": "override"})
+		require.NoError(t, err)
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+
+		for _, imageFile := range image.Files() {
+			descriptor := imageFile.Proto()
+			assert.Equal(t, "override", descriptor.GetOptions().GetSwiftPrefix())
+		}
+	})
+}
+
+func TestSwiftPrefixAllOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "alloptions")
+	image := testGetImage(t, dirPath, true)
+	assertFileOptionSourceCodeInfoNotEmpty(t, image, swiftPrefixPath)
+
+	sweeper := NewFileOptionSweeper()
+	swiftPrefixModifier, err := SwiftPrefix(zap.NewNop(), sweeper, "ACME", nil, nil, nil)
+	require.NoError(t, err)
+
+	modifier := NewMultiModifier(swiftPrefixModifier, ModifierFunc(sweeper.Sweep))
+	err = modifier.Modify(
+		context.Background(),
+		image,
+	)
+	require.NoError(t, err)
+
+	for _, imageFile := range image.Files() {
+		descriptor := imageFile.Proto()
+		assert.Equal(t, "ACME", descriptor.GetOptions().GetSwiftPrefix())
+	}
+	assertFileOptionSourceCodeInfoEmpty(t, image, swiftPrefixPath, true)
+}
+
+func TestSwiftPrefixWithExceptAndOverride(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "alloptions")
+	testModuleIdentity, err := bufmoduleref.NewModuleIdentity(
+		testRemote,
+		testRepositoryOwner,
+		testRepositoryName,
+	)
+	require.NoError(t, err)
+
+	t.Run("with except", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		sweeper := NewFileOptionSweeper()
+		swiftPrefixModifier, err := SwiftPrefix(
+			zap.NewNop(),
+			sweeper,
+			"ACME",
+			[]bufmoduleref.ModuleIdentity{testModuleIdentity},
+			nil,
+			nil,
+		)
+		require.NoError(t, err)
+
+		modifier := NewMultiModifier(swiftPrefixModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, testGetImage(t, dirPath, true), image)
+	})
+
+	t.Run("with module override", func(t *testing.T) {
+		t.Parallel()
+		image := testGetImage(t, dirPath, true)
+		sweeper := NewFileOptionSweeper()
+		swiftPrefixModifier, err := SwiftPrefix(
+			zap.NewNop(),
+			sweeper,
+			"ACME",
+			nil,
+			map[bufmoduleref.ModuleIdentity]string{testModuleIdentity: "MOD"},
+			nil,
+		)
+		require.NoError(t, err)
+
+		modifier := NewMultiModifier(swiftPrefixModifier, ModifierFunc(sweeper.Sweep))
+		err = modifier.Modify(
+			context.Background(),
+			image,
+		)
+		require.NoError(t, err)
+
+		for _, imageFile := range image.Files() {
+			descriptor := imageFile.Proto()
+			assert.Equal(t, "MOD", descriptor.GetOptions().GetSwiftPrefix())
+		}
+		assertFileOptionSourceCodeInfoEmpty(t, image, swiftPrefixPath, true)
+	})
+}
+
+func TestSwiftPrefixRequiresDefault(t *testing.T) {
+	t.Parallel()
+	_, err := SwiftPrefix(zap.NewNop(), NewFileOptionSweeper(), "", nil, nil, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,66 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufjsonschema generates JSON Schemas for Protobuf messages.
+package bufjsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufreflect"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SchemaURI is the URI of the JSON Schema dialect of generated schemas.
+const SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// Generate generates a JSON Schema for the fully qualified message typeName
+// in the bufimage.Image.
+//
+// The schema describes the protojson encoding of the message: 64-bit integers
+// are accepted as strings or numbers, enums as names or numbers, well-known
+// types use their special JSON representations, and at most one field of each
+// oneof may be set. Both the JSON name and the original name of each field are
+// accepted. Every message and enum referenced is defined under "$defs".
+func Generate(image bufimage.Image, typeName string) ([]byte, error) {
+	if err := bufreflect.ValidateTypeName(typeName); err != nil {
+		return nil, err
+	}
+	files, err := protodesc.NewFiles(bufimage.ImageToFileDescriptorSet(image))
+	if err != nil {
+		return nil, err
+	}
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(typeName))
+	if err != nil {
+		return nil, err
+	}
+	messageDescriptor, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q must be a message but is a %T", typeName, descriptor)
+	}
+	generator := newGenerator()
+	schema := generator.messageSchema(messageDescriptor)
+	schema["$schema"] = SchemaURI
+	if len(generator.defs) > 0 {
+		schema["$defs"] = generator.defs
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufjsonschema
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagebuild"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+	data, err := Generate(testGetImage(t), "acme.v1.Config")
+	require.NoError(t, err)
+	var root map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &root))
+	assert.Equal(t, SchemaURI, root["$schema"])
+	assert.Equal(t, "#/$defs/acme.v1.Config", root["$ref"])
+	defs := root["$defs"].(map[string]interface{})
+	config := defs["acme.v1.Config"].(map[string]interface{})
+	assert.Equal(t, "Config", config["title"])
+	assert.Equal(t, "Config is a config.", config["description"])
+	assert.Equal(t, false, config["additionalProperties"])
+	properties := config["properties"].(map[string]interface{})
+	int64Schema := map[string]interface{}{
+		"type":    []interface{}{"string", "integer"},
+		"pattern": "^-?[0-9]+$",
+	}
+	assert.Equal(t, int64Schema, properties["bigNumber"])
+	assert.Equal(t, int64Schema, properties["big_number"])
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"type":    "integer",
+			"minimum": float64(0),
+			"maximum": float64(4294967295),
+		},
+		properties["smallNumber"],
+	)
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "number"},
+				map[string]interface{}{"enum": []interface{}{"NaN", "Infinity", "-Infinity"}},
+			},
+		},
+		properties["ratio"],
+	)
+	assert.Equal(t, map[string]interface{}{"type": "string", "contentEncoding": "base64"}, properties["blob"])
+	assert.Equal(t, map[string]interface{}{"$ref": "#/$defs/acme.v1.Level"}, properties["level"])
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		},
+		properties["names"],
+	)
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"type":                 "object",
+			"propertyNames":        map[string]interface{}{"pattern": "^-?[0-9]+$"},
+			"additionalProperties": map[string]interface{}{"$ref": "#/$defs/acme.v1.Config"},
+		},
+		properties["children"],
+	)
+	assert.Equal(t, map[string]interface{}{"type": "string", "format": "date-time"}, properties["createdAt"])
+	assert.Equal(t, "string", properties["timeout"].(map[string]interface{})["type"])
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"type":    []interface{}{"string", "integer"},
+			"pattern": "^[0-9]+$",
+		},
+		properties["limit"],
+	)
+	assert.Equal(t, map[string]interface{}{"type": "string"}, properties["comment"])
+	assert.Equal(
+		t,
+		[]interface{}{
+			map[string]interface{}{
+				"not": map[string]interface{}{
+					"anyOf": []interface{}{
+						map[string]interface{}{"required": []interface{}{"path"}},
+						map[string]interface{}{"required": []interface{}{"url"}},
+					},
+				},
+			},
+			map[string]interface{}{"required": []interface{}{"path"}},
+			map[string]interface{}{"required": []interface{}{"url"}},
+		},
+		config["oneOf"],
+	)
+	assert.Nil(t, config["allOf"])
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"title":       "Level",
+			"description": "Level is a level.",
+			"enum":        []interface{}{"LEVEL_UNSPECIFIED", "LEVEL_HIGH", float64(0), float64(1)},
+		},
+		defs["acme.v1.Level"],
+	)
+}
+
+func TestGenerateError(t *testing.T) {
+	t.Parallel()
+	image := testGetImage(t)
+	_, err := Generate(image, "acme.v1.Level")
+	assert.Error(t, err)
+	_, err = Generate(image, "acme.v1.Unknown")
+	assert.Error(t, err)
+	_, err = Generate(image, "acme/v1")
+	assert.Error(t, err)
+}
+
+func testGetImage(t *testing.T) bufimage.Image {
+	ctx := context.Background()
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket("testdata")
+	require.NoError(t, err)
+	module, err := bufmodule.NewModuleForBucket(ctx, readWriteBucket)
+	require.NoError(t, err)
+	moduleFileSet, err := bufmodulebuild.NewModuleFileSetBuilder(
+		zap.NewNop(),
+		bufmodule.NewNopModuleReader(),
+	).Build(
+		ctx,
+		module,
+	)
+	require.NoError(t, err)
+	image, annotations, err := bufimagebuild.NewBuilder(zap.NewNop()).Build(ctx, moduleFileSet)
+	require.NoError(t, err)
+	require.Empty(t, annotations)
+	return image
+}
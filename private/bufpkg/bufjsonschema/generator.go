@@ -0,0 +1,300 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufjsonschema
+
+import (
+	"math"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	defsPrefix    = "#/$defs/"
+	signedPattern = "^-?[0-9]+$"
+	// unsignedPattern also matches protojson map keys of unsigned types.
+	unsignedPattern = "^[0-9]+$"
+)
+
+// schema is a JSON Schema object.
+type schema map[string]interface{}
+
+type generator struct {
+	defs map[string]schema
+}
+
+func newGenerator() *generator {
+	return &generator{
+		defs: make(map[string]schema),
+	}
+}
+
+// messageSchema returns the schema to use for a field of the message type.
+//
+// Well-known types are inlined, all other messages are referenced in defs.
+func (g *generator) messageSchema(messageDescriptor protoreflect.MessageDescriptor) schema {
+	if wellKnownTypeSchema, ok := g.wellKnownTypeSchema(messageDescriptor); ok {
+		return wellKnownTypeSchema
+	}
+	name := string(messageDescriptor.FullName())
+	if _, ok := g.defs[name]; !ok {
+		// Set the def before generating the properties in case the message is recursive.
+		def := newDescribedSchema(messageDescriptor)
+		g.defs[name] = def
+		g.populateMessageDef(def, messageDescriptor)
+	}
+	return schema{"$ref": defsPrefix + name}
+}
+
+func (g *generator) populateMessageDef(def schema, messageDescriptor protoreflect.MessageDescriptor) {
+	def["type"] = "object"
+	def["additionalProperties"] = false
+	properties := make(map[string]schema)
+	fields := messageDescriptor.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		fieldSchema := g.fieldSchema(field)
+		for _, name := range fieldNames(field) {
+			properties[name] = fieldSchema
+		}
+	}
+	def["properties"] = properties
+	var oneofSchemas []schema
+	oneofs := messageDescriptor.Oneofs()
+	for i := 0; i < oneofs.Len(); i++ {
+		oneof := oneofs.Get(i)
+		if oneof.IsSynthetic() {
+			continue
+		}
+		oneofSchemas = append(oneofSchemas, oneofSchema(oneof))
+	}
+	switch len(oneofSchemas) {
+	case 0:
+	case 1:
+		def["oneOf"] = oneofSchemas[0]["oneOf"]
+	default:
+		def["allOf"] = oneofSchemas
+	}
+}
+
+func (g *generator) fieldSchema(field protoreflect.FieldDescriptor) schema {
+	if field.IsMap() {
+		return schema{
+			"type":                 "object",
+			"propertyNames":        mapKeySchema(field.MapKey()),
+			"additionalProperties": g.singularFieldSchema(field.MapValue()),
+		}
+	}
+	if field.IsList() {
+		return schema{
+			"type":  "array",
+			"items": g.singularFieldSchema(field),
+		}
+	}
+	return g.singularFieldSchema(field)
+}
+
+func (g *generator) singularFieldSchema(field protoreflect.FieldDescriptor) schema {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return schema{"type": "boolean"}
+	case protoreflect.StringKind:
+		return schema{"type": "string"}
+	case protoreflect.BytesKind:
+		return bytesSchema()
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return integerSchema(math.MinInt32, math.MaxInt32)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return integerSchema(0, math.MaxUint32)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return int64Schema(signedPattern)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64Schema(unsignedPattern)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return floatSchema()
+	case protoreflect.EnumKind:
+		return g.enumSchema(field.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return g.messageSchema(field.Message())
+	default:
+		return schema{}
+	}
+}
+
+// enumSchema returns the schema to use for a field of the enum type.
+//
+// Enums are referenced in defs and accept both the value names and numbers.
+func (g *generator) enumSchema(enumDescriptor protoreflect.EnumDescriptor) schema {
+	if enumDescriptor.FullName() == "google.protobuf.NullValue" {
+		return schema{"type": "null"}
+	}
+	name := string(enumDescriptor.FullName())
+	if _, ok := g.defs[name]; !ok {
+		def := newDescribedSchema(enumDescriptor)
+		values := enumDescriptor.Values()
+		names := make([]interface{}, 0, values.Len())
+		numbers := make([]interface{}, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			value := values.Get(i)
+			names = append(names, string(value.Name()))
+			numbers = append(numbers, int32(value.Number()))
+		}
+		def["enum"] = append(names, numbers...)
+		g.defs[name] = def
+	}
+	return schema{"$ref": defsPrefix + name}
+}
+
+func (g *generator) wellKnownTypeSchema(messageDescriptor protoreflect.MessageDescriptor) (schema, bool) {
+	if messageDescriptor.ParentFile().Package() != "google.protobuf" {
+		return nil, false
+	}
+	switch messageDescriptor.Name() {
+	case "Any":
+		return schema{
+			"type": "object",
+			"properties": map[string]schema{
+				"@type": {"type": "string"},
+			},
+			"required": []string{"@type"},
+		}, true
+	case "Timestamp":
+		return schema{"type": "string", "format": "date-time"}, true
+	case "Duration":
+		return schema{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]{0,9})?s$`}, true
+	case "FieldMask":
+		return schema{"type": "string"}, true
+	case "Struct":
+		return schema{"type": "object"}, true
+	case "ListValue":
+		return schema{"type": "array"}, true
+	case "Value":
+		return schema{}, true
+	case "BoolValue":
+		return schema{"type": "boolean"}, true
+	case "StringValue":
+		return schema{"type": "string"}, true
+	case "BytesValue":
+		return bytesSchema(), true
+	case "Int32Value":
+		return integerSchema(math.MinInt32, math.MaxInt32), true
+	case "UInt32Value":
+		return integerSchema(0, math.MaxUint32), true
+	case "Int64Value":
+		return int64Schema(signedPattern), true
+	case "UInt64Value":
+		return int64Schema(unsignedPattern), true
+	case "FloatValue", "DoubleValue":
+		return floatSchema(), true
+	default:
+		return nil, false
+	}
+}
+
+// oneofSchema returns a schema with a single "oneOf" key that matches if at
+// most one field of the oneof is set.
+func oneofSchema(oneof protoreflect.OneofDescriptor) schema {
+	fields := oneof.Fields()
+	fieldSetSchemas := make([]schema, 0, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		fieldSetSchemas = append(fieldSetSchemas, fieldSetSchema(fields.Get(i)))
+	}
+	oneOf := []schema{
+		{"not": schema{"anyOf": fieldSetSchemas}},
+	}
+	return schema{"oneOf": append(oneOf, fieldSetSchemas...)}
+}
+
+// fieldSetSchema returns a schema that matches if the field is set under any of its names.
+func fieldSetSchema(field protoreflect.FieldDescriptor) schema {
+	names := fieldNames(field)
+	if len(names) == 1 {
+		return schema{"required": names}
+	}
+	requiredSchemas := make([]schema, 0, len(names))
+	for _, name := range names {
+		requiredSchemas = append(requiredSchemas, schema{"required": []string{name}})
+	}
+	return schema{"anyOf": requiredSchemas}
+}
+
+func mapKeySchema(field protoreflect.FieldDescriptor) schema {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return schema{"enum": []string{"true", "false"}}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return schema{"pattern": signedPattern}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return schema{"pattern": unsignedPattern}
+	default:
+		return schema{}
+	}
+}
+
+func integerSchema(minimum int64, maximum int64) schema {
+	return schema{
+		"type":    "integer",
+		"minimum": minimum,
+		"maximum": maximum,
+	}
+}
+
+// int64Schema returns the schema for 64-bit integers, which protojson encodes
+// as strings but also accepts as numbers.
+func int64Schema(pattern string) schema {
+	return schema{
+		"type":    []string{"string", "integer"},
+		"pattern": pattern,
+	}
+}
+
+func floatSchema() schema {
+	return schema{
+		"anyOf": []schema{
+			{"type": "number"},
+			{"enum": []string{"NaN", "Infinity", "-Infinity"}},
+		},
+	}
+}
+
+func bytesSchema() schema {
+	return schema{
+		"type":            "string",
+		"contentEncoding": "base64",
+	}
+}
+
+func newDescribedSchema(descriptor protoreflect.Descriptor) schema {
+	s := schema{
+		"title": string(descriptor.Name()),
+	}
+	location := descriptor.ParentFile().SourceLocations().ByDescriptor(descriptor)
+	if comment := strings.TrimSpace(location.LeadingComments); comment != "" {
+		s["description"] = comment
+	}
+	return s
+}
+
+// fieldNames returns the JSON name of the field, followed by its original name
+// if it differs, as protojson accepts both.
+func fieldNames(field protoreflect.FieldDescriptor) []string {
+	names := []string{field.JSONName()}
+	if name := string(field.Name()); name != field.JSONName() {
+		names = append(names, name)
+	}
+	return names
+}
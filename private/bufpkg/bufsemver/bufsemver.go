@@ -0,0 +1,161 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufsemver suggests semantic version bumps from the changes between two sets of files.
+package bufsemver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+)
+
+const (
+	// BumpPatch is a patch version bump, for changes that neither break nor add to the API.
+	BumpPatch Bump = iota + 1
+	// BumpMinor is a minor version bump, for changes that add to the API or
+	// remove from the API without breaking it.
+	BumpMinor
+	// BumpMajor is a major version bump, for changes that break the API.
+	BumpMajor
+)
+
+var (
+	bumpToString = map[Bump]string{
+		BumpPatch: "patch",
+		BumpMinor: "minor",
+		BumpMajor: "major",
+	}
+)
+
+// Bump is a semantic version bump.
+type Bump int
+
+// String implements fmt.Stringer.
+func (b Bump) String() string {
+	s, ok := bumpToString[b]
+	if !ok {
+		return strconv.Itoa(int(b))
+	}
+	return s
+}
+
+// Suggestion is a suggested Bump along with the changes that require it.
+type Suggestion struct {
+	// Bump is the suggested Bump.
+	Bump Bump
+	// BreakingFileAnnotations are the breaking changes, which require BumpMajor.
+	BreakingFileAnnotations []bufanalysis.FileAnnotation
+	// AdditionFileAnnotations are the additive changes, which require BumpMinor.
+	AdditionFileAnnotations []bufanalysis.FileAnnotation
+	// RemovalFileAnnotations are the removals, which require BumpMinor.
+	//
+	// Removals that break the API are also BreakingFileAnnotations, while the
+	// other removals are allowed by the breaking rules, for example deleting
+	// a field with a reserved number.
+	RemovalFileAnnotations []bufanalysis.FileAnnotation
+}
+
+// NewSuggestion returns a new Suggestion for the breaking changes, additions, and removals.
+//
+// The FileAnnotations are deduplicated and sorted.
+func NewSuggestion(
+	breakingFileAnnotations []bufanalysis.FileAnnotation,
+	additionFileAnnotations []bufanalysis.FileAnnotation,
+	removalFileAnnotations []bufanalysis.FileAnnotation,
+) *Suggestion {
+	breakingFileAnnotations = bufanalysis.DeduplicateAndSortFileAnnotations(breakingFileAnnotations)
+	additionFileAnnotations = bufanalysis.DeduplicateAndSortFileAnnotations(additionFileAnnotations)
+	removalFileAnnotations = bufanalysis.DeduplicateAndSortFileAnnotations(removalFileAnnotations)
+	bump := BumpPatch
+	switch {
+	case len(breakingFileAnnotations) > 0:
+		bump = BumpMajor
+	case len(additionFileAnnotations) > 0, len(removalFileAnnotations) > 0:
+		bump = BumpMinor
+	}
+	return &Suggestion{
+		Bump:                    bump,
+		BreakingFileAnnotations: breakingFileAnnotations,
+		AdditionFileAnnotations: additionFileAnnotations,
+		RemovalFileAnnotations:  removalFileAnnotations,
+	}
+}
+
+// GetAdditionsAndRemovals returns FileAnnotations for the messages, enums, services, fields,
+// enum values, and RPCs that were added to and removed from the previous files.
+//
+// These are the KindAdded and KindRemoved changes from bufchangelog.GetChanges, so the
+// members of added or removed messages, enums, and services are not reported separately.
+// Removals are reported at their location in the previous files.
+func GetAdditionsAndRemovals(
+	previousFiles []protosource.File,
+	files []protosource.File,
+) ([]bufanalysis.FileAnnotation, []bufanalysis.FileAnnotation, error) {
+	return getAdditionsAndRemovals(previousFiles, files)
+}
+
+// PrintSuggestion prints the Suggestion to the writer.
+//
+// The text format prints the Bump on the first line, followed by one line for
+// each breaking change, then each addition, and then each removal.
+func PrintSuggestion(writer io.Writer, suggestion *Suggestion, asJSON bool) error {
+	if asJSON {
+		data, err := json.Marshal(newExternalSuggestion(suggestion))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(writer, string(data))
+		return err
+	}
+	if _, err := fmt.Fprintln(writer, suggestion.Bump.String()); err != nil {
+		return err
+	}
+	for _, fileAnnotation := range suggestion.BreakingFileAnnotations {
+		if _, err := fmt.Fprintf(writer, "  breaking: %s\n", fileAnnotation.String()); err != nil {
+			return err
+		}
+	}
+	for _, fileAnnotation := range suggestion.AdditionFileAnnotations {
+		if _, err := fmt.Fprintf(writer, "  addition: %s\n", fileAnnotation.String()); err != nil {
+			return err
+		}
+	}
+	for _, fileAnnotation := range suggestion.RemovalFileAnnotations {
+		if _, err := fmt.Fprintf(writer, "  removal: %s\n", fileAnnotation.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type externalSuggestion struct {
+	Bump      string                       `json:"bump,omitempty" yaml:"bump,omitempty"`
+	Breaking  []bufanalysis.FileAnnotation `json:"breaking,omitempty" yaml:"breaking,omitempty"`
+	Additions []bufanalysis.FileAnnotation `json:"additions,omitempty" yaml:"additions,omitempty"`
+	Removals  []bufanalysis.FileAnnotation `json:"removals,omitempty" yaml:"removals,omitempty"`
+}
+
+func newExternalSuggestion(suggestion *Suggestion) externalSuggestion {
+	return externalSuggestion{
+		Bump:      suggestion.Bump.String(),
+		Breaking:  suggestion.BreakingFileAnnotations,
+		Additions: suggestion.AdditionFileAnnotations,
+		Removals:  suggestion.RemovalFileAnnotations,
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufsemver
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis/bufanalysistesting"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagebuild"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGetAdditionsAndRemovals(t *testing.T) {
+	t.Parallel()
+	additions, removals, err := GetAdditionsAndRemovals(testGetFiles(t, "previous"), testGetFiles(t, "current"))
+	require.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		[]bufanalysis.FileAnnotation{
+			bufanalysistesting.NewFileAnnotation(t, "a.proto", 7, 3, 7, 18, "FIELD_ADDED"),
+			bufanalysistesting.NewFileAnnotation(t, "a.proto", 8, 3, 11, 4, "MESSAGE_ADDED"),
+			bufanalysistesting.NewFileAnnotation(t, "a.proto", 16, 3, 16, 15, "ENUM_VALUE_ADDED"),
+			bufanalysistesting.NewFileAnnotation(t, "a.proto", 21, 3, 21, 31, "RPC_ADDED"),
+			bufanalysistesting.NewFileAnnotation(t, "a.proto", 24, 1, 26, 2, "SERVICE_ADDED"),
+			bufanalysistesting.NewFileAnnotation(t, "a.proto", 28, 1, 28, 16, "MESSAGE_ADDED"),
+		},
+		bufanalysis.DeduplicateAndSortFileAnnotations(additions),
+	)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		[]bufanalysis.FileAnnotation{
+			bufanalysistesting.NewFileAnnotation(t, "a.proto", 17, 1, 17, 15, "MESSAGE_REMOVED"),
+		},
+		bufanalysis.DeduplicateAndSortFileAnnotations(removals),
+	)
+	additions, removals, err = GetAdditionsAndRemovals(testGetFiles(t, "current"), testGetFiles(t, "current"))
+	require.NoError(t, err)
+	assert.Empty(t, additions)
+	assert.Empty(t, removals)
+}
+
+func TestNewSuggestion(t *testing.T) {
+	t.Parallel()
+	breaking := bufanalysistesting.NewFileAnnotation(t, "a.proto", 1, 1, 1, 1, "FIELD_NO_DELETE")
+	addition := bufanalysistesting.NewFileAnnotation(t, "a.proto", 2, 1, 2, 1, "FIELD_ADDED")
+	removal := bufanalysistesting.NewFileAnnotation(t, "a.proto", 3, 1, 3, 1, "FIELD_REMOVED")
+	assert.Equal(t, BumpPatch, NewSuggestion(nil, nil, nil).Bump)
+	assert.Equal(t, BumpMinor, NewSuggestion(nil, []bufanalysis.FileAnnotation{addition}, nil).Bump)
+	assert.Equal(t, BumpMinor, NewSuggestion(nil, nil, []bufanalysis.FileAnnotation{removal}).Bump)
+	suggestion := NewSuggestion(
+		[]bufanalysis.FileAnnotation{breaking},
+		[]bufanalysis.FileAnnotation{addition},
+		[]bufanalysis.FileAnnotation{removal},
+	)
+	assert.Equal(t, BumpMajor, suggestion.Bump)
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, PrintSuggestion(buffer, suggestion, false))
+	assert.Equal(
+		t,
+		"major\n  breaking: a.proto:1:1:FIELD_NO_DELETE\n  addition: a.proto:2:1:FIELD_ADDED\n  removal: a.proto:3:1:FIELD_REMOVED\n",
+		buffer.String(),
+	)
+	buffer.Reset()
+	require.NoError(t, PrintSuggestion(buffer, suggestion, true))
+	assert.Contains(t, buffer.String(), `"bump":"major"`)
+}
+
+func testGetFiles(t *testing.T, relDirPath string) []protosource.File {
+	ctx := context.Background()
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(filepath.Join("testdata", relDirPath))
+	require.NoError(t, err)
+	module, err := bufmodule.NewModuleForBucket(ctx, readWriteBucket)
+	require.NoError(t, err)
+	moduleFileSet, err := bufmodulebuild.NewModuleFileSetBuilder(
+		zap.NewNop(),
+		bufmodule.NewNopModuleReader(),
+	).Build(
+		ctx,
+		module,
+	)
+	require.NoError(t, err)
+	image, annotations, err := bufimagebuild.NewBuilder(zap.NewNop()).Build(ctx, moduleFileSet)
+	require.NoError(t, err)
+	require.Empty(t, annotations)
+	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	require.NoError(t, err)
+	return files
+}
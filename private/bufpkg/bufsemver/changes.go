@@ -0,0 +1,93 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufsemver
+
+import (
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufchangelog"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+)
+
+var (
+	// files are not included as moving elements between files does not change the API
+	elementToTitle = map[string]string{
+		bufchangelog.ElementMessage:   "Message",
+		bufchangelog.ElementField:     "Field",
+		bufchangelog.ElementEnum:      "Enum",
+		bufchangelog.ElementEnumValue: "Enum value",
+		bufchangelog.ElementService:   "Service",
+		bufchangelog.ElementRPC:       "RPC",
+	}
+	elementToTypePrefix = map[string]string{
+		bufchangelog.ElementMessage:   "MESSAGE",
+		bufchangelog.ElementField:     "FIELD",
+		bufchangelog.ElementEnum:      "ENUM",
+		bufchangelog.ElementEnumValue: "ENUM_VALUE",
+		bufchangelog.ElementService:   "SERVICE",
+		bufchangelog.ElementRPC:       "RPC",
+	}
+	kindToTypeSuffix = map[bufchangelog.Kind]string{
+		bufchangelog.KindAdded:   "ADDED",
+		bufchangelog.KindRemoved: "REMOVED",
+	}
+)
+
+func getAdditionsAndRemovals(
+	previousFiles []protosource.File,
+	files []protosource.File,
+) ([]bufanalysis.FileAnnotation, []bufanalysis.FileAnnotation, error) {
+	changes, err := bufchangelog.GetChanges(previousFiles, files)
+	if err != nil {
+		return nil, nil, err
+	}
+	var additionFileAnnotations []bufanalysis.FileAnnotation
+	var removalFileAnnotations []bufanalysis.FileAnnotation
+	for _, change := range changes {
+		if _, ok := elementToTitle[change.Element]; !ok {
+			continue
+		}
+		switch change.Kind {
+		case bufchangelog.KindAdded:
+			additionFileAnnotations = append(additionFileAnnotations, newFileAnnotationForChange(change))
+		case bufchangelog.KindRemoved:
+			removalFileAnnotations = append(removalFileAnnotations, newFileAnnotationForChange(change))
+		}
+	}
+	return additionFileAnnotations, removalFileAnnotations, nil
+}
+
+func newFileAnnotationForChange(change *bufchangelog.Change) bufanalysis.FileAnnotation {
+	startLine := 0
+	startColumn := 0
+	endLine := 0
+	endColumn := 0
+	if location := change.Location; location != nil {
+		startLine = location.StartLine()
+		startColumn = location.StartColumn()
+		endLine = location.EndLine()
+		endColumn = location.EndColumn()
+	}
+	return bufanalysis.NewFileAnnotation(
+		change.Descriptor.File(),
+		startLine,
+		startColumn,
+		endLine,
+		endColumn,
+		elementToTypePrefix[change.Element]+"_"+kindToTypeSuffix[change.Kind],
+		fmt.Sprintf(`%s %q was %s.`, elementToTitle[change.Element], change.Name, change.Kind.String()),
+	)
+}
@@ -30,6 +30,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/protodescriptor"
 	"github.com/bufbuild/buf/private/pkg/protoencoding"
 	"github.com/bufbuild/buf/private/pkg/storage"
@@ -195,11 +196,20 @@ func NewPluginResponse(
 	}
 }
 
-// ValidatePluginResponses validates that each file is only defined by a single *PluginResponse.
+// ValidatePluginResponses validates that each file name is a relative path within
+// the plugin out, and that each file is only defined by a single *PluginResponse.
 func ValidatePluginResponses(pluginResponses []*PluginResponse) error {
 	seen := make(map[string]string)
 	for _, pluginResponse := range pluginResponses {
 		for _, file := range pluginResponse.Response.File {
+			if normalizedName, err := normalpath.NormalizeAndValidate(file.GetName()); err != nil || normalizedName == "." {
+				return fmt.Errorf(
+					"plugin %q generated file %q, which is not a relative path within its out %q",
+					pluginResponse.PluginName,
+					file.GetName(),
+					pluginResponse.PluginOut,
+				)
+			}
 			if file.GetInsertionPoint() != "" {
 				// We expect insertion points to write
 				// to files that already exist.
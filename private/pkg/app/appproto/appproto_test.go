@@ -21,6 +21,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
@@ -121,6 +122,29 @@ at varied indentation levels
 	})
 }
 
+func TestValidatePluginResponses(t *testing.T) {
+	t.Parallel()
+	newPluginResponse := func(pluginName string, fileName string) *PluginResponse {
+		return NewPluginResponse(
+			&pluginpb.CodeGeneratorResponse{
+				File: []*pluginpb.CodeGeneratorResponse_File{
+					{
+						Name: proto.String(fileName),
+					},
+				},
+			},
+			pluginName,
+			"gen",
+		)
+	}
+	require.NoError(t, ValidatePluginResponses([]*PluginResponse{newPluginResponse("a", "a/a.go"), newPluginResponse("b", "b/b.go")}))
+	assert.Error(t, ValidatePluginResponses([]*PluginResponse{newPluginResponse("a", "a/a.go"), newPluginResponse("b", "a/a.go")}))
+	assert.Error(t, ValidatePluginResponses([]*PluginResponse{newPluginResponse("a", "../a.go")}))
+	assert.Error(t, ValidatePluginResponses([]*PluginResponse{newPluginResponse("a", "a/../../a.go")}))
+	assert.Error(t, ValidatePluginResponses([]*PluginResponse{newPluginResponse("a", "/etc/a.go")}))
+	assert.Error(t, ValidatePluginResponses([]*PluginResponse{newPluginResponse("a", "")}))
+}
+
 func BenchmarkWriteInsertionPoint(b *testing.B) {
 	// \u205F is "Medium Mathematical Space"
 	whitespacePrefix := "\u205F\t\t\t"
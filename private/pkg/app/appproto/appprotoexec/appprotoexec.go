@@ -22,6 +22,7 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"time"
 
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
@@ -101,6 +102,36 @@ func GenerateWithPluginPath(pluginPath string) GenerateOption {
 	}
 }
 
+// GenerateWithTimeout returns a new GenerateOption that stops the plugin if it
+// does not complete within the timeout.
+//
+// See HandlerWithTimeout.
+func GenerateWithTimeout(timeout time.Duration) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.timeout = timeout
+	}
+}
+
+// GenerateWithEnvKeys returns a new GenerateOption that only passes the
+// environment variables with the given keys to the plugin.
+//
+// See HandlerWithEnvKeys.
+func GenerateWithEnvKeys(envKeys []string) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.envKeys = envKeys
+	}
+}
+
+// GenerateWithMaxOutputBytes returns a new GenerateOption that errors if the
+// plugin writes a CodeGeneratorResponse larger than maxOutputBytes.
+//
+// See HandlerWithMaxOutputBytes.
+func GenerateWithMaxOutputBytes(maxOutputBytes int64) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.maxOutputBytes = maxOutputBytes
+	}
+}
+
 // NewHandler returns a new Handler based on the plugin name and optional path.
 //
 // protocPath and pluginPath are optional.
@@ -121,29 +152,74 @@ func NewHandler(
 	for _, option := range options {
 		option(handlerOptions)
 	}
-	if handlerOptions.pluginPath != "" {
-		pluginPath, err := exec.LookPath(handlerOptions.pluginPath)
-		if err != nil {
-			return nil, err
-		}
-		return newBinaryHandler(logger, runner, pluginPath), nil
+	binaryPath, isProtoc, err := lookPath(pluginName, handlerOptions)
+	if err != nil {
+		return nil, err
 	}
-	pluginPath, err := exec.LookPath("protoc-gen-" + pluginName)
-	if err == nil {
-		return newBinaryHandler(logger, runner, pluginPath), nil
+	if isProtoc {
+		return newProtocProxyHandler(
+			logger,
+			storageosProvider,
+			runner,
+			binaryPath,
+			pluginName,
+			handlerOptions.timeout,
+			handlerOptions.envKeys,
+		), nil
 	}
-	// we always look for protoc-gen-X first, but if not, check the builtins
-	if _, ok := ProtocProxyPluginNames[pluginName]; ok {
-		if handlerOptions.protocPath == "" {
-			handlerOptions.protocPath = "protoc"
-		}
-		protocPath, err := exec.LookPath(handlerOptions.protocPath)
-		if err != nil {
-			return nil, err
-		}
-		return newProtocProxyHandler(logger, storageosProvider, runner, protocPath, pluginName), nil
+	return newBinaryHandler(
+		logger,
+		runner,
+		binaryPath,
+		handlerOptions.timeout,
+		handlerOptions.envKeys,
+		handlerOptions.maxOutputBytes,
+	), nil
+}
+
+// LookPath returns the path of the binary that a Handler returned by NewHandler
+// for the same plugin name and options would execute.
+//
+// This is the path of protoc for builtin plugins that are proxied through protoc.
+func LookPath(pluginName string, options ...HandlerOption) (string, error) {
+	handlerOptions := newHandlerOptions()
+	for _, option := range options {
+		option(handlerOptions)
+	}
+	binaryPath, _, err := lookPath(pluginName, handlerOptions)
+	return binaryPath, err
+}
+
+// ValidateVersionConstraint returns an error if the version constraint is invalid.
+//
+// A version constraint is a comma-separated list of comparisons that must all be
+// satisfied, such as ">= 1.28, < 2". The supported operators are =, !=, >, >=, <, and <=,
+// and a version without an operator must match exactly. Versions are of the form
+// "[v]major[.minor[.patch]][-suffix]", and the minor and patch versions default to 0.
+func ValidateVersionConstraint(versionConstraint string) error {
+	_, err := parseVersionConstraint(versionConstraint)
+	return err
+}
+
+// CheckVersion returns an error if the version of the binary that a Handler returned
+// by NewHandler for the same plugin name and options would execute does not satisfy
+// the version constraint.
+//
+// The version is read from the output of the plugin's --version flag, or from the
+// output of protoc --version for builtin plugins that are proxied through protoc.
+func CheckVersion(
+	ctx context.Context,
+	container app.EnvContainer,
+	runner command.Runner,
+	pluginName string,
+	versionConstraint string,
+	options ...HandlerOption,
+) error {
+	handlerOptions := newHandlerOptions()
+	for _, option := range options {
+		option(handlerOptions)
 	}
-	return nil, fmt.Errorf("could not find protoc plugin for name %s", pluginName)
+	return checkVersion(ctx, container, runner, pluginName, versionConstraint, handlerOptions)
 }
 
 // HandlerOption is an option for a new Handler.
@@ -167,11 +243,74 @@ func HandlerWithPluginPath(pluginPath string) HandlerOption {
 	}
 }
 
+// HandlerWithTimeout returns a new HandlerOption that stops the plugin if it
+// does not complete within the timeout.
+//
+// The default is to not have a timeout.
+func HandlerWithTimeout(timeout time.Duration) HandlerOption {
+	return func(handlerOptions *handlerOptions) {
+		handlerOptions.timeout = timeout
+	}
+}
+
+// HandlerWithEnvKeys returns a new HandlerOption that only passes the
+// environment variables with the given keys to the plugin.
+//
+// If envKeys is nil, all environment variables are passed, which is the default.
+func HandlerWithEnvKeys(envKeys []string) HandlerOption {
+	return func(handlerOptions *handlerOptions) {
+		handlerOptions.envKeys = envKeys
+	}
+}
+
+// HandlerWithMaxOutputBytes returns a new HandlerOption that errors if the plugin
+// writes a CodeGeneratorResponse larger than maxOutputBytes.
+//
+// This has no effect for plugins that are proxied through protoc.
+// The default is to not have a limit.
+func HandlerWithMaxOutputBytes(maxOutputBytes int64) HandlerOption {
+	return func(handlerOptions *handlerOptions) {
+		handlerOptions.maxOutputBytes = maxOutputBytes
+	}
+}
+
 type handlerOptions struct {
-	protocPath string
-	pluginPath string
+	protocPath     string
+	pluginPath     string
+	timeout        time.Duration
+	envKeys        []string
+	maxOutputBytes int64
 }
 
 func newHandlerOptions() *handlerOptions {
 	return &handlerOptions{}
 }
+
+// lookPath returns the path of the binary to execute for the plugin, and
+// whether this binary is protoc.
+func lookPath(pluginName string, handlerOptions *handlerOptions) (string, bool, error) {
+	if handlerOptions.pluginPath != "" {
+		pluginPath, err := exec.LookPath(handlerOptions.pluginPath)
+		if err != nil {
+			return "", false, err
+		}
+		return pluginPath, false, nil
+	}
+	pluginPath, err := exec.LookPath("protoc-gen-" + pluginName)
+	if err == nil {
+		return pluginPath, false, nil
+	}
+	// we always look for protoc-gen-X first, but if not, check the builtins
+	if _, ok := ProtocProxyPluginNames[pluginName]; ok {
+		protocPath := handlerOptions.protocPath
+		if protocPath == "" {
+			protocPath = "protoc"
+		}
+		protocPath, err := exec.LookPath(protocPath)
+		if err != nil {
+			return "", false, err
+		}
+		return protocPath, true, nil
+	}
+	return "", false, fmt.Errorf("could not find protoc plugin for name %s", pluginName)
+}
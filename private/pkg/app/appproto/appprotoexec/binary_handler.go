@@ -17,7 +17,10 @@ package appprotoexec
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"path/filepath"
+	"time"
 
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
@@ -29,20 +32,29 @@ import (
 )
 
 type binaryHandler struct {
-	logger     *zap.Logger
-	runner     command.Runner
-	pluginPath string
+	logger         *zap.Logger
+	runner         command.Runner
+	pluginPath     string
+	timeout        time.Duration
+	envKeys        []string
+	maxOutputBytes int64
 }
 
 func newBinaryHandler(
 	logger *zap.Logger,
 	runner command.Runner,
 	pluginPath string,
+	timeout time.Duration,
+	envKeys []string,
+	maxOutputBytes int64,
 ) *binaryHandler {
 	return &binaryHandler{
-		logger:     logger.Named("appprotoexec"),
-		runner:     runner,
-		pluginPath: pluginPath,
+		logger:         logger.Named("appprotoexec"),
+		runner:         runner,
+		pluginPath:     pluginPath,
+		timeout:        timeout,
+		envKeys:        envKeys,
+		maxOutputBytes: maxOutputBytes,
 	}
 }
 
@@ -60,16 +72,26 @@ func (h *binaryHandler) Handle(
 		return err
 	}
 	responseBuffer := bytes.NewBuffer(nil)
-	if err := h.runner.Run(
+	var stdout io.Writer = responseBuffer
+	var limitedWriter *limitedWriter
+	if h.maxOutputBytes > 0 {
+		limitedWriter = newLimitedWriter(responseBuffer, h.maxOutputBytes)
+		stdout = limitedWriter
+	}
+	if err := runPlugin(
 		ctx,
+		container,
+		h.runner,
 		h.pluginPath,
-		command.RunWithEnv(app.EnvironMap(container)),
+		h.timeout,
+		h.envKeys,
 		command.RunWithStdin(bytes.NewReader(requestData)),
-		command.RunWithStdout(responseBuffer),
-		command.RunWithStderr(container.Stderr()),
+		command.RunWithStdout(stdout),
 	); err != nil {
-		// TODO: strip binary path as well?
-		return handlePotentialTooManyFilesError(err)
+		if limitedWriter != nil && limitedWriter.Exceeded() {
+			return fmt.Errorf("plugin output exceeded the maximum of %d bytes", h.maxOutputBytes)
+		}
+		return err
 	}
 	response := &pluginpb.CodeGeneratorResponse{}
 	if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(responseBuffer.Bytes(), response); err != nil {
@@ -16,6 +16,7 @@ package appprotoexec
 
 import (
 	"context"
+	"time"
 
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
@@ -60,6 +61,9 @@ func (g *generator) Generate(
 		g.runner,
 		pluginName,
 		HandlerWithPluginPath(generateOptions.pluginPath),
+		HandlerWithTimeout(generateOptions.timeout),
+		HandlerWithEnvKeys(generateOptions.envKeys),
+		HandlerWithMaxOutputBytes(generateOptions.maxOutputBytes),
 	)
 	if err != nil {
 		return nil, err
@@ -75,7 +79,10 @@ func (g *generator) Generate(
 }
 
 type generateOptions struct {
-	pluginPath string
+	pluginPath     string
+	timeout        time.Duration
+	envKeys        []string
+	maxOutputBytes int64
 }
 
 func newGenerateOptions() *generateOptions {
@@ -21,6 +21,7 @@ import (
 	"io"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
@@ -44,6 +45,8 @@ type protocProxyHandler struct {
 	runner            command.Runner
 	protocPath        string
 	pluginName        string
+	timeout           time.Duration
+	envKeys           []string
 }
 
 func newProtocProxyHandler(
@@ -52,6 +55,8 @@ func newProtocProxyHandler(
 	runner command.Runner,
 	protocPath string,
 	pluginName string,
+	timeout time.Duration,
+	envKeys []string,
 ) *protocProxyHandler {
 	return &protocProxyHandler{
 		logger:            logger.Named("appprotoexec"),
@@ -59,6 +64,8 @@ func newProtocProxyHandler(
 		runner:            runner,
 		protocPath:        protocPath,
 		pluginName:        pluginName,
+		timeout:           timeout,
+		envKeys:           envKeys,
 	}
 }
 
@@ -132,17 +139,18 @@ func (h *protocProxyHandler) Handle(
 	if descriptorFilePath != "" && descriptorFilePath == app.DevStdinFilePath {
 		stdin = bytes.NewReader(fileDescriptorSetData)
 	}
-	if err := h.runner.Run(
+	if err := runPlugin(
 		ctx,
+		container,
+		h.runner,
 		h.protocPath,
+		h.timeout,
+		h.envKeys,
 		command.RunWithArgs(args...),
-		command.RunWithEnv(app.EnvironMap(container)),
 		command.RunWithStdin(stdin),
-		command.RunWithStderr(container.Stderr()),
 	); err != nil {
-		// TODO: strip binary path as well?
 		// We don't know if this is a system error or plugin error, so we assume system error
-		return handlePotentialTooManyFilesError(err)
+		return err
 	}
 	if featureProto3Optional {
 		responseWriter.SetFeatureProto3Optional()
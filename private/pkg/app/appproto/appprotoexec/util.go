@@ -15,11 +15,23 @@
 package appprotoexec
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
 )
 
+// maxPluginStderrTailBytes is the maximum number of bytes at the end of the
+// stderr of a plugin that are included in the error if the plugin fails.
+const maxPluginStderrTailBytes = 4096
+
 // handlePotentialTooManyFilesError checks if the error is a result of too many files
 // being open, and if so, modifies the output error with a help message.
 //
@@ -44,3 +56,138 @@ func isTooManyFilesError(err error) bool {
 	}
 	return false
 }
+
+// runPlugin runs the plugin binary with the timeout and the environment
+// variables with the given keys.
+//
+// The stderr of the plugin is written to the stderr of the container as it is
+// produced, and the end of it is included in the returned error if the plugin fails.
+func runPlugin(
+	ctx context.Context,
+	container app.EnvStderrContainer,
+	runner command.Runner,
+	pluginPath string,
+	timeout time.Duration,
+	envKeys []string,
+	options ...command.RunOption,
+) error {
+	runCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	stderrTailWriter := newTailWriter(maxPluginStderrTailBytes)
+	// The plugin is killed once runCtx is done, and Run returns once it has exited.
+	err := runner.Run(
+		runCtx,
+		pluginPath,
+		append(
+			options,
+			command.RunWithEnv(getPluginEnv(container, envKeys)),
+			command.RunWithStderr(io.MultiWriter(container.Stderr(), stderrTailWriter)),
+		)...,
+	)
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if runCtx.Err() != nil {
+		return fmt.Errorf("timed out after %v", timeout)
+	}
+	// TODO: strip binary path as well?
+	err = handlePotentialTooManyFilesError(err)
+	if stderr := strings.TrimSpace(stderrTailWriter.String()); stderr != "" {
+		return fmt.Errorf("%w: %s", err, stderr)
+	}
+	return err
+}
+
+// getPluginEnv returns the environment variables of the container with the given keys.
+//
+// If envKeys is nil, all environment variables are returned.
+func getPluginEnv(container app.EnvContainer, envKeys []string) map[string]string {
+	if envKeys == nil {
+		return app.EnvironMap(container)
+	}
+	env := make(map[string]string, len(envKeys))
+	for _, envKey := range envKeys {
+		if value := container.Env(envKey); value != "" {
+			env[envKey] = value
+		}
+	}
+	return env
+}
+
+// tailWriter is a writer that keeps the last limit bytes written.
+type tailWriter struct {
+	limit     int
+	data      []byte
+	truncated bool
+	lock      sync.Mutex
+}
+
+func newTailWriter(limit int) *tailWriter {
+	return &tailWriter{
+		limit: limit,
+	}
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.data = append(w.data, p...)
+	if len(w.data) > w.limit {
+		w.data = append([]byte(nil), w.data[len(w.data)-w.limit:]...)
+		w.truncated = true
+	}
+	return len(p), nil
+}
+
+// String returns the last limit bytes written, prefixed with "..." if
+// earlier bytes were dropped.
+func (w *tailWriter) String() string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.truncated {
+		return "..." + string(w.data)
+	}
+	return string(w.data)
+}
+
+// limitedWriter is a writer that errors once more than limit bytes are written.
+type limitedWriter struct {
+	writer   io.Writer
+	limit    int64
+	written  int64
+	exceeded bool
+	lock     sync.Mutex
+}
+
+func newLimitedWriter(writer io.Writer, limit int64) *limitedWriter {
+	return &limitedWriter{
+		writer: writer,
+		limit:  limit,
+	}
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.written+int64(len(p)) > w.limit {
+		w.exceeded = true
+		return 0, fmt.Errorf("exceeded the maximum of %d bytes", w.limit)
+	}
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// Exceeded returns true if more than limit bytes were attempted to be written.
+func (w *limitedWriter) Exceeded() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.exceeded
+}
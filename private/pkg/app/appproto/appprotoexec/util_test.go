@@ -0,0 +1,76 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appprotoexec
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPluginEnv(t *testing.T) {
+	t.Parallel()
+	container := app.NewEnvContainer(
+		map[string]string{
+			"HOME":   "/home/foo",
+			"PATH":   "/bin",
+			"SECRET": "secret",
+		},
+	)
+	assert.Equal(
+		t,
+		map[string]string{
+			"HOME":   "/home/foo",
+			"PATH":   "/bin",
+			"SECRET": "secret",
+		},
+		getPluginEnv(container, nil),
+	)
+	assert.Equal(
+		t,
+		map[string]string{
+			"PATH": "/bin",
+		},
+		getPluginEnv(container, []string{"PATH", "GOPATH"}),
+	)
+	assert.Empty(t, getPluginEnv(container, []string{}))
+}
+
+func TestLimitedWriter(t *testing.T) {
+	t.Parallel()
+	buffer := bytes.NewBuffer(nil)
+	limitedWriter := newLimitedWriter(buffer, 4)
+	_, err := limitedWriter.Write([]byte("abc"))
+	require.NoError(t, err)
+	assert.False(t, limitedWriter.Exceeded())
+	_, err = limitedWriter.Write([]byte("de"))
+	assert.Error(t, err)
+	assert.True(t, limitedWriter.Exceeded())
+	assert.Equal(t, "abc", buffer.String())
+}
+
+func TestTailWriter(t *testing.T) {
+	t.Parallel()
+	tailWriter := newTailWriter(4)
+	_, err := tailWriter.Write([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", tailWriter.String())
+	_, err = tailWriter.Write([]byte("def"))
+	require.NoError(t, err)
+	assert.Equal(t, "...cdef", tailWriter.String())
+}
@@ -0,0 +1,86 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build aix || darwin || dragonfly || freebsd || (js && wasm) || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd js,wasm linux netbsd openbsd solaris
+
+package appprotoexec
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPluginStderr(t *testing.T) {
+	t.Parallel()
+	stderr := bytes.NewBuffer(nil)
+	err := runPlugin(
+		context.Background(),
+		testNewEnvStderrContainer(stderr),
+		command.NewRunner(),
+		"sh",
+		0,
+		nil,
+		command.RunWithArgs("-c", "echo warning >&2"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "warning\n", stderr.String())
+	stderr.Reset()
+	err = runPlugin(
+		context.Background(),
+		testNewEnvStderrContainer(stderr),
+		command.NewRunner(),
+		"sh",
+		0,
+		nil,
+		command.RunWithArgs("-c", "echo failure >&2; exit 1"),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failure")
+	assert.Equal(t, "failure\n", stderr.String())
+}
+
+func TestRunPluginTimeout(t *testing.T) {
+	t.Parallel()
+	start := time.Now()
+	err := runPlugin(
+		context.Background(),
+		testNewEnvStderrContainer(bytes.NewBuffer(nil)),
+		command.NewRunner(),
+		"sleep",
+		10*time.Millisecond,
+		nil,
+		command.RunWithArgs("10"),
+	)
+	require.Error(t, err)
+	assert.Equal(t, "timed out after 10ms", err.Error())
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func testNewEnvStderrContainer(stderr *bytes.Buffer) app.EnvStderrContainer {
+	return struct {
+		app.EnvContainer
+		app.StderrContainer
+	}{
+		EnvContainer:    app.NewEnvContainer(map[string]string{"PATH": "/usr/bin:/bin"}),
+		StderrContainer: app.NewStderrContainer(stderr),
+	}
+}
@@ -15,11 +15,16 @@
 package appprotoexec
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/pluginpb"
 )
@@ -73,6 +78,220 @@ func parseVersionForCLIVersion(value string) (_ *pluginpb.Version, retErr error)
 	return newVersion(int32(major), int32(minor), int32(patch), suffix), nil
 }
 
+func checkVersion(
+	ctx context.Context,
+	container app.EnvContainer,
+	runner command.Runner,
+	pluginName string,
+	versionConstraintString string,
+	handlerOptions *handlerOptions,
+) error {
+	constraint, err := parseVersionConstraint(versionConstraintString)
+	if err != nil {
+		return err
+	}
+	binaryPath, isProtoc, err := lookPath(pluginName, handlerOptions)
+	if err != nil {
+		return err
+	}
+	if handlerOptions.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, handlerOptions.timeout)
+		defer cancel()
+	}
+	stdoutBuffer := bytes.NewBuffer(nil)
+	stderrBuffer := bytes.NewBuffer(nil)
+	if err := runner.Run(
+		ctx,
+		binaryPath,
+		command.RunWithArgs("--version"),
+		command.RunWithEnv(getPluginEnv(container, handlerOptions.envKeys)),
+		command.RunWithStdout(stdoutBuffer),
+		command.RunWithStderr(stderrBuffer),
+	); err != nil {
+		if stderr := strings.TrimSpace(stderrBuffer.String()); stderr != "" {
+			err = fmt.Errorf("%w: %s", err, stderr)
+		}
+		return fmt.Errorf("could not get version of %s: %w", binaryPath, handlePotentialTooManyFilesError(err))
+	}
+	var version *pluginpb.Version
+	if isProtoc {
+		version, err = parseVersionForCLIVersion(strings.TrimSpace(stdoutBuffer.String()))
+	} else {
+		output := stdoutBuffer.String()
+		if strings.TrimSpace(output) == "" {
+			// Some plugins print their version to stderr.
+			output = stderrBuffer.String()
+		}
+		version, err = parseVersionForPluginOutput(output)
+	}
+	if err != nil {
+		return fmt.Errorf("could not get version of %s: %w", binaryPath, err)
+	}
+	if !constraint.check(version) {
+		return fmt.Errorf(
+			"installed version %s of %s does not satisfy the version constraint %q",
+			versionString(version),
+			binaryPath,
+			constraint.value,
+		)
+	}
+	return nil
+}
+
+// pluginVersionRegexp matches a version in the output of a plugin's --version flag,
+// such as "v1.28.0" in "protoc-gen-go v1.28.0", or "1.2.0" in "protoc-gen-go-grpc 1.2.0".
+var pluginVersionRegexp = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?$`)
+
+// parseVersionForPluginOutput parses the version from the output of a plugin's
+// --version flag. The first whitespace-separated field that is a version is used.
+func parseVersionForPluginOutput(value string) (*pluginpb.Version, error) {
+	for _, field := range strings.Fields(value) {
+		if version, ok := parseVersionForPluginVersion(field); ok {
+			return version, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot parse plugin version from %q", strings.TrimSpace(value))
+}
+
+// parseVersionForPluginVersion parses a version of the form "[v]major[.minor[.patch]][-suffix]".
+//
+// The minor and patch versions default to 0.
+func parseVersionForPluginVersion(value string) (*pluginpb.Version, bool) {
+	matches := pluginVersionRegexp.FindStringSubmatch(value)
+	if matches == nil {
+		return nil, false
+	}
+	var components [3]int32
+	for i, match := range matches[1:4] {
+		if match == "" {
+			continue
+		}
+		component, err := strconv.ParseInt(match, 10, 32)
+		if err != nil {
+			return nil, false
+		}
+		components[i] = int32(component)
+	}
+	return newVersion(components[0], components[1], components[2], matches[4]), true
+}
+
+// compareVersions returns -1 if x is less than y, 1 if x is greater
+// than y, and 0 if they are equal.
+//
+// As in semantic versioning, a version with a suffix is less than
+// the same version without a suffix.
+func compareVersions(x *pluginpb.Version, y *pluginpb.Version) int {
+	for _, pair := range [][2]int32{
+		{x.GetMajor(), y.GetMajor()},
+		{x.GetMinor(), y.GetMinor()},
+		{x.GetPatch(), y.GetPatch()},
+	} {
+		switch {
+		case pair[0] < pair[1]:
+			return -1
+		case pair[0] > pair[1]:
+			return 1
+		}
+	}
+	switch xSuffix, ySuffix := x.GetSuffix(), y.GetSuffix(); {
+	case xSuffix == ySuffix:
+		return 0
+	case xSuffix == "":
+		return 1
+	case ySuffix == "":
+		return -1
+	case xSuffix < ySuffix:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// versionConstraint is a set of comparisons that a version must all satisfy.
+type versionConstraint struct {
+	value       string
+	comparisons []*versionComparison
+}
+
+// parseVersionConstraint parses a comma-separated list of comparisons,
+// such as ">= 1.28, < 2". A version without an operator must match exactly.
+func parseVersionConstraint(value string) (*versionConstraint, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, errors.New("empty version constraint")
+	}
+	constraint := &versionConstraint{
+		value: value,
+	}
+	for _, comparisonString := range strings.Split(value, ",") {
+		comparison, err := parseVersionComparison(strings.TrimSpace(comparisonString))
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %w", value, err)
+		}
+		constraint.comparisons = append(constraint.comparisons, comparison)
+	}
+	return constraint, nil
+}
+
+// check returns true if the version satisfies all of the comparisons.
+func (c *versionConstraint) check(version *pluginpb.Version) bool {
+	for _, comparison := range c.comparisons {
+		if !comparison.check(version) {
+			return false
+		}
+	}
+	return true
+}
+
+type versionComparison struct {
+	operator string
+	version  *pluginpb.Version
+}
+
+// versionComparisonOperators are the supported operators. Longer operators
+// are listed before their prefixes so that they are matched first.
+var versionComparisonOperators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+func parseVersionComparison(value string) (*versionComparison, error) {
+	operator := "="
+	for _, candidate := range versionComparisonOperators {
+		if strings.HasPrefix(value, candidate) {
+			operator = candidate
+			value = strings.TrimSpace(strings.TrimPrefix(value, candidate))
+			break
+		}
+	}
+	if operator == "==" {
+		operator = "="
+	}
+	version, ok := parseVersionForPluginVersion(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid version %q", value)
+	}
+	return &versionComparison{
+		operator: operator,
+		version:  version,
+	}, nil
+}
+
+func (c *versionComparison) check(version *pluginpb.Version) bool {
+	compare := compareVersions(version, c.version)
+	switch c.operator {
+	case ">=":
+		return compare >= 0
+	case "<=":
+		return compare <= 0
+	case "!=":
+		return compare != 0
+	case ">":
+		return compare > 0
+	case "<":
+		return compare < 0
+	default:
+		return compare == 0
+	}
+}
+
 func versionString(version *pluginpb.Version) string {
 	value := fmt.Sprintf("%d.%d.%d", version.GetMajor(), version.GetMinor(), version.GetPatch())
 	if version.Suffix != nil {
@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
@@ -75,3 +76,46 @@ func testParseVersionForCLIVersionError(
 	_, err := parseVersionForCLIVersion(value)
 	assert.Error(t, err)
 }
+
+func TestParseVersionForPluginOutput(t *testing.T) {
+	t.Parallel()
+	testParseVersionForPluginOutput(t, "protoc-gen-go v1.28.0\n", "1.28.0")
+	testParseVersionForPluginOutput(t, "protoc-gen-go-grpc 1.2.0", "1.2.0")
+	testParseVersionForPluginOutput(t, "protoc-gen-foo version 2.1.0-rc.1", "2.1.0-rc.1")
+	testParseVersionForPluginOutput(t, "3", "3.0.0")
+	_, err := parseVersionForPluginOutput("protoc-gen-foo devel")
+	assert.Error(t, err)
+}
+
+func TestVersionConstraint(t *testing.T) {
+	t.Parallel()
+	testVersionConstraint(t, ">= 1.28", newVersion(1, 28, 0, ""), true)
+	testVersionConstraint(t, ">= 1.28", newVersion(1, 29, 1, ""), true)
+	testVersionConstraint(t, ">= 1.28", newVersion(1, 27, 1, ""), false)
+	testVersionConstraint(t, ">= 1.28", newVersion(1, 28, 0, "rc.1"), false)
+	testVersionConstraint(t, ">=1.28.0, <2", newVersion(2, 0, 0, ""), false)
+	testVersionConstraint(t, ">=1.28.0, <2", newVersion(1, 99, 0, ""), true)
+	testVersionConstraint(t, "v1.28.0", newVersion(1, 28, 0, ""), true)
+	testVersionConstraint(t, "= 1.28.0", newVersion(1, 28, 1, ""), false)
+	testVersionConstraint(t, "!= 1.28.0", newVersion(1, 28, 1, ""), true)
+	testVersionConstraint(t, "> 1.2.0-rc.1", newVersion(1, 2, 0, ""), true)
+	testVersionConstraint(t, "<= 1.2", newVersion(1, 2, 0, ""), true)
+	_, err := parseVersionConstraint("")
+	assert.Error(t, err)
+	_, err = parseVersionConstraint(">= one")
+	assert.Error(t, err)
+	_, err = parseVersionConstraint(">= 1.28,")
+	assert.Error(t, err)
+}
+
+func testParseVersionForPluginOutput(t *testing.T, output string, expected string) {
+	version, err := parseVersionForPluginOutput(output)
+	require.NoError(t, err)
+	assert.Equal(t, expected, versionString(version))
+}
+
+func testVersionConstraint(t *testing.T, value string, version *pluginpb.Version, expected bool) {
+	constraint, err := parseVersionConstraint(value)
+	require.NoError(t, err)
+	assert.Equal(t, expected, constraint.check(version), "%s %s", value, versionString(version))
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"io"
 
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/pluginpb"
@@ -32,13 +33,34 @@ type ResponseWriter interface {
 
 	// AddResponse adds the response to the writer, switching on the file extension.
 	// If there is a .jar extension, this generates a jar. If there is a .zip
-	// extension, this generates a zip. If there is no extension, this outputs
-	// to the directory.
+	// extension, this generates a zip. If there is a .tar or .tar.gz extension,
+	// this generates a tarball. Otherwise, this outputs to the directory.
 	AddResponse(
 		ctx context.Context,
 		response *pluginpb.CodeGeneratorResponse,
 		pluginOut string,
+		options ...AddResponseOption,
 	) error
+	// Diff compares the responses with the files on disk instead of writing them.
+	//
+	// The paths of the files that Close would write or delete because they differ
+	// from the files on disk are returned. If diffWriter is not nil, a unified diff
+	// for these files is written to it. Only the files that would be written or
+	// deleted are compared, so other files in the output directories are ignored.
+	//
+	// No further calls can be made to the ResponseWriter after this call.
+	Diff(ctx context.Context, runner command.Runner, diffWriter io.Writer) ([]string, error)
+}
+
+// ManifestFilePath is the path of the manifest within an output directory that
+// lists the files written to the directory, if AddResponseWithClean is used.
+const ManifestFilePath = ".buf-gen-manifest"
+
+// IsArchivePath returns true if AddResponse writes the response for the plugin
+// out to an archive instead of a directory.
+func IsArchivePath(pluginOut string) bool {
+	_, ok := getArchiveType(pluginOut)
+	return ok
 }
 
 // NewResponseWriter returns a new ResponseWriter.
@@ -64,3 +86,35 @@ func ResponseWriterWithCreateOutDirIfNotExists() ResponseWriterOption {
 		responseWriterOptions.createOutDirIfNotExists = true
 	}
 }
+
+// ResponseWriterWithPartialInput returns a new ResponseWriterOption that says
+// that the responses were generated for a subset of the input files, for
+// example because only some paths were targeted.
+//
+// The files in the manifest of the previous run may have been generated from
+// the other input files, so AddResponseWithClean never deletes them, and they
+// are kept in the new manifest so that a later run for all the input files
+// can delete them if they are no longer generated.
+func ResponseWriterWithPartialInput() ResponseWriterOption {
+	return func(responseWriterOptions *responseWriterOptions) {
+		responseWriterOptions.partialInput = true
+	}
+}
+
+// AddResponseOption is an option for AddResponse.
+type AddResponseOption func(*addResponseOptions)
+
+// AddResponseWithClean returns a new AddResponseOption that deletes the files
+// that were written to the output directory by the previous run, but are no
+// longer written.
+//
+// The files written are recorded in a manifest at ManifestFilePath within the
+// output directory. Files that are not listed in the manifest of the previous
+// run are never deleted. If there is no manifest, no files are deleted.
+//
+// This has no effect for archive outputs, which are always overwritten.
+func AddResponseWithClean() AddResponseOption {
+	return func(addResponseOptions *addResponseOptions) {
+		addResponseOptions.clean = true
+	}
+}
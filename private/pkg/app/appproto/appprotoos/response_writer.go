@@ -15,38 +15,64 @@
 package appprotoos
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/diff"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storagearchive"
 	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
+// manifestHeader is written at the top of every manifest.
+const manifestHeader = `# Code generated by buf. DO NOT EDIT.
+# The generated files in this directory, used to delete files that are no longer generated.
+`
+
+const (
+	archiveTypeZip archiveType = iota + 1
+	archiveTypeJar
+	archiveTypeTar
+	archiveTypeTarGz
+)
+
 // Constants used to create .jar files.
 var (
-	manifestPath    = normalpath.Join("META-INF", "MANIFEST.MF")
-	manifestContent = []byte(`Manifest-Version: 1.0
+	jarManifestPath    = normalpath.Join("META-INF", "MANIFEST.MF")
+	jarManifestContent = []byte(`Manifest-Version: 1.0
 Created-By: 1.6.0 (protoc)
 
 `)
 )
 
+// archiveType is the type of archive a plugin out is written to.
+type archiveType int
+
 type responseWriter struct {
 	logger            *zap.Logger
 	storageosProvider storageos.Provider
 	responseWriter    appproto.ResponseWriter
 	// If set, create directories if they don't already exist.
 	createOutDirIfNotExists bool
+	// If set, the responses were generated for a subset of the input files,
+	// so the files in the previous manifests are kept when cleaning.
+	partialInput bool
 	// Cache the readWriteBuckets by their respective output paths.
 	// These builders are transformed to storage.ReadBuckets and written
 	// to disk once the responseWriter is flushed.
@@ -63,10 +89,15 @@ type responseWriter struct {
 	// $ protoc example.proto --insertion-point-receiver_out=. --insertion-point-writer_out=$(pwd)
 	//
 	readWriteBuckets map[string]storage.ReadWriteBucket
+	// The output directories to clean when flushing.
+	cleanOutDirPaths map[string]struct{}
 	// Cache the functions used to flush all of the responses to disk.
 	// This holds all of the buckets in-memory so that we only write
 	// the results to disk if all of the responses are successful.
 	closers []func() error
+	// Cache the functions used to diff all of the responses against
+	// the files on disk, in the same order as the closers.
+	differs []func(ctx context.Context, runner command.Runner, diffWriter io.Writer) ([]string, error)
 	lock    sync.RWMutex
 }
 
@@ -84,7 +115,9 @@ func newResponseWriter(
 		storageosProvider:       storageosProvider,
 		responseWriter:          appproto.NewResponseWriter(logger),
 		createOutDirIfNotExists: responseWriterOptions.createOutDirIfNotExists,
+		partialInput:            responseWriterOptions.partialInput,
 		readWriteBuckets:        make(map[string]storage.ReadWriteBucket),
+		cleanOutDirPaths:        make(map[string]struct{}),
 	}
 }
 
@@ -92,7 +125,12 @@ func (w *responseWriter) AddResponse(
 	ctx context.Context,
 	response *pluginpb.CodeGeneratorResponse,
 	pluginOut string,
+	options ...AddResponseOption,
 ) error {
+	addResponseOptions := newAddResponseOptions()
+	for _, option := range options {
+		option(addResponseOptions)
+	}
 	w.lock.Lock()
 	defer w.lock.Unlock()
 	return w.addResponse(
@@ -107,6 +145,7 @@ func (w *responseWriter) AddResponse(
 		// --insertion-point-receiver_out=insertion --insertion-point-writer_out=./insertion/
 		filepath.Clean(pluginOut),
 		w.createOutDirIfNotExists,
+		addResponseOptions.clean,
 	)
 }
 
@@ -124,10 +163,35 @@ func (w *responseWriter) Close() error {
 			return err
 		}
 	}
-	// Re-initialize the cached values to be safe.
+	w.reset()
+	return nil
+}
+
+func (w *responseWriter) Diff(
+	ctx context.Context,
+	runner command.Runner,
+	diffWriter io.Writer,
+) ([]string, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	var changedPaths []string
+	for _, diffFunc := range w.differs {
+		outChangedPaths, err := diffFunc(ctx, runner, diffWriter)
+		if err != nil {
+			return nil, err
+		}
+		changedPaths = append(changedPaths, outChangedPaths...)
+	}
+	w.reset()
+	return changedPaths, nil
+}
+
+// reset re-initializes the cached values to be safe.
+func (w *responseWriter) reset() {
 	w.readWriteBuckets = make(map[string]storage.ReadWriteBucket)
+	w.cleanOutDirPaths = make(map[string]struct{})
 	w.closers = nil
-	return nil
+	w.differs = nil
 }
 
 func (w *responseWriter) addResponse(
@@ -135,39 +199,33 @@ func (w *responseWriter) addResponse(
 	response *pluginpb.CodeGeneratorResponse,
 	pluginOut string,
 	createOutDirIfNotExists bool,
+	clean bool,
 ) error {
-	switch filepath.Ext(pluginOut) {
-	case ".jar":
-		return w.writeZip(
-			ctx,
-			response,
-			pluginOut,
-			true,
-			createOutDirIfNotExists,
-		)
-	case ".zip":
-		return w.writeZip(
-			ctx,
-			response,
-			pluginOut,
-			false,
-			createOutDirIfNotExists,
-		)
-	default:
-		return w.writeDirectory(
+	if archiveType, ok := getArchiveType(pluginOut); ok {
+		return w.writeArchive(
 			ctx,
 			response,
 			pluginOut,
+			archiveType,
 			createOutDirIfNotExists,
 		)
 	}
+	if clean {
+		w.cleanOutDirPaths[pluginOut] = struct{}{}
+	}
+	return w.writeDirectory(
+		ctx,
+		response,
+		pluginOut,
+		createOutDirIfNotExists,
+	)
 }
 
-func (w *responseWriter) writeZip(
+func (w *responseWriter) writeArchive(
 	ctx context.Context,
 	response *pluginpb.CodeGeneratorResponse,
 	outFilePath string,
-	includeManifest bool,
+	archiveType archiveType,
 	createOutDirIfNotExists bool,
 ) (retErr error) {
 	outDirPath := filepath.Dir(outFilePath)
@@ -184,28 +242,7 @@ func (w *responseWriter) writeZip(
 		}
 		return nil
 	}
-	// OK to use os.Stat instead of os.Lstat here.
-	fileInfo, err := os.Stat(outDirPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			if createOutDirIfNotExists {
-				if err := os.MkdirAll(outDirPath, 0755); err != nil {
-					return err
-				}
-			} else {
-				return err
-			}
-		}
-		return err
-	} else if !fileInfo.IsDir() {
-		return fmt.Errorf("not a directory: %s", outDirPath)
-	}
 	readWriteBucket := storagemem.NewReadWriteBucket()
-	if includeManifest {
-		if err := storage.PutPath(ctx, readWriteBucket, manifestPath, manifestContent); err != nil {
-			return err
-		}
-	}
 	if err := w.responseWriter.WriteResponse(
 		ctx,
 		readWriteBucket,
@@ -218,8 +255,23 @@ func (w *responseWriter) writeZip(
 	// can write to the same files (re: insertion points).
 	w.readWriteBuckets[outFilePath] = readWriteBucket
 	w.closers = append(w.closers, func() (retErr error) {
+		// The directory is only created when flushing, so that
+		// nothing is written to disk when diffing.
+		//
+		// OK to use os.Stat instead of os.Lstat here.
+		fileInfo, err := os.Stat(outDirPath)
+		if err != nil {
+			if !os.IsNotExist(err) || !createOutDirIfNotExists {
+				return err
+			}
+			if err := os.MkdirAll(outDirPath, 0755); err != nil {
+				return err
+			}
+		} else if !fileInfo.IsDir() {
+			return fmt.Errorf("not a directory: %s", outDirPath)
+		}
 		// We're done writing all of the content into this
-		// readWriteBucket, so we zip it when we flush.
+		// readWriteBucket, so we archive it when we flush.
 		file, err := os.Create(outFilePath)
 		if err != nil {
 			return err
@@ -227,8 +279,14 @@ func (w *responseWriter) writeZip(
 		defer func() {
 			retErr = multierr.Append(retErr, file.Close())
 		}()
-		// protoc does not compress.
-		return storagearchive.Zip(ctx, readWriteBucket, file, false)
+		return writeArchiveTo(ctx, readWriteBucket, archiveType, file)
+	})
+	w.differs = append(w.differs, func(ctx context.Context, runner command.Runner, diffWriter io.Writer) ([]string, error) {
+		buffer := bytes.NewBuffer(nil)
+		if err := writeArchiveTo(ctx, readWriteBucket, archiveType, buffer); err != nil {
+			return nil, err
+		}
+		return diffFile(ctx, runner, diffWriter, outFilePath, buffer.Bytes(), true)
 	})
 	return nil
 }
@@ -278,18 +336,326 @@ func (w *responseWriter) writeDirectory(
 		if err != nil {
 			return err
 		}
+		// The previous manifest has to be read before any files are written.
+		var previousManifestPaths []string
+		_, clean := w.cleanOutDirPaths[outDirPath]
+		if clean {
+			previousManifestPaths, err = readManifest(ctx, osReadWriteBucket)
+			if err != nil {
+				return err
+			}
+		}
 		if _, err := storage.Copy(ctx, readWriteBucket, osReadWriteBucket); err != nil {
 			return err
 		}
+		if clean {
+			return w.cleanDirectory(ctx, readWriteBucket, osReadWriteBucket, outDirPath, previousManifestPaths)
+		}
 		return nil
 	})
+	w.differs = append(w.differs, func(ctx context.Context, runner command.Runner, diffWriter io.Writer) ([]string, error) {
+		_, clean := w.cleanOutDirPaths[outDirPath]
+		return diffDirectory(ctx, runner, diffWriter, readWriteBucket, outDirPath, clean, w.partialInput)
+	})
 	return nil
 }
 
+// cleanDirectory deletes the files in the previous manifest that were not
+// written this time, and writes the new manifest.
+//
+// If the responses were generated for a subset of the input files, the files
+// in the previous manifest may have been generated from the other input files,
+// so they are never deleted and are carried over to the new manifest.
+func (w *responseWriter) cleanDirectory(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	osReadWriteBucket storage.ReadWriteBucket,
+	outDirPath string,
+	previousManifestPaths []string,
+) error {
+	paths, err := storage.AllPaths(ctx, readBucket, "")
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+	if w.partialInput {
+		return writeManifest(ctx, osReadWriteBucket, mergeManifestPaths(paths, previousManifestPaths))
+	}
+	pathMap := stringutil.SliceToMap(paths)
+	for _, previousPath := range previousManifestPaths {
+		if _, ok := pathMap[previousPath]; ok {
+			continue
+		}
+		if err := osReadWriteBucket.Delete(ctx, previousPath); err != nil {
+			if storage.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		w.logger.Debug("deleted_stale_file", zap.String("out", outDirPath), zap.String("path", previousPath))
+		deleteEmptyParentDirs(outDirPath, previousPath)
+	}
+	return writeManifest(ctx, osReadWriteBucket, paths)
+}
+
+// diffDirectory diffs the files in the bucket against the files in the output
+// directory, and returns the external paths of the files that differ.
+//
+// Only the files in the bucket are compared, along with the manifest and the
+// files that would be deleted if clean is set.
+func diffDirectory(
+	ctx context.Context,
+	runner command.Runner,
+	diffWriter io.Writer,
+	readBucket storage.ReadBucket,
+	outDirPath string,
+	clean bool,
+	partialInput bool,
+) ([]string, error) {
+	paths, err := storage.AllPaths(ctx, readBucket, "")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	pathToData := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		data, err := storage.ReadPath(ctx, readBucket, path)
+		if err != nil {
+			return nil, err
+		}
+		pathToData[path] = data
+	}
+	diffPaths := append([]string(nil), paths...)
+	if clean {
+		manifestData, err := readFileIfExists(filepath.Join(outDirPath, ManifestFilePath))
+		if err != nil {
+			return nil, err
+		}
+		previousManifestPaths := parseManifest(manifestData)
+		manifestPaths := paths
+		if partialInput {
+			manifestPaths = mergeManifestPaths(paths, previousManifestPaths)
+		} else {
+			for _, previousPath := range previousManifestPaths {
+				if _, ok := pathToData[previousPath]; !ok {
+					// This file would be deleted, so it is compared with no data.
+					diffPaths = append(diffPaths, previousPath)
+				}
+			}
+		}
+		pathToData[ManifestFilePath] = newManifest(manifestPaths)
+		diffPaths = append(diffPaths, ManifestFilePath)
+		sort.Strings(diffPaths)
+	}
+	var changedPaths []string
+	for _, path := range diffPaths {
+		fileChangedPaths, err := diffFile(
+			ctx,
+			runner,
+			diffWriter,
+			filepath.Join(outDirPath, normalpath.Unnormalize(path)),
+			pathToData[path],
+			false,
+		)
+		if err != nil {
+			return nil, err
+		}
+		changedPaths = append(changedPaths, fileChangedPaths...)
+	}
+	return changedPaths, nil
+}
+
+// diffFile diffs the data against the file at the path, and returns the
+// path if they differ.
+//
+// If data is nil and the file does not exist, they do not differ.
+func diffFile(
+	ctx context.Context,
+	runner command.Runner,
+	diffWriter io.Writer,
+	filePath string,
+	data []byte,
+	binary bool,
+) ([]string, error) {
+	currentData, err := readFileIfExists(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(currentData, data) {
+		return nil, nil
+	}
+	if diffWriter != nil {
+		if binary {
+			if _, err := fmt.Fprintf(diffWriter, "Binary file %s differs\n", filePath); err != nil {
+				return nil, err
+			}
+		} else {
+			diffData, err := diff.Diff(
+				ctx,
+				runner,
+				currentData,
+				data,
+				filePath,
+				filePath,
+				diff.DiffWithSuppressTimestamps(),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := diffWriter.Write(diffData); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return []string{filePath}, nil
+}
+
+// readManifest reads the paths in the manifest of the bucket.
+//
+// Returns no paths if there is no manifest.
+func readManifest(ctx context.Context, readBucket storage.ReadBucket) ([]string, error) {
+	data, err := storage.ReadPath(ctx, readBucket, ManifestFilePath)
+	if err != nil {
+		if storage.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseManifest(data), nil
+}
+
+// writeManifest writes the manifest with the sorted paths to the bucket.
+func writeManifest(ctx context.Context, writeBucket storage.WriteBucket, paths []string) error {
+	return storage.PutPath(ctx, writeBucket, ManifestFilePath, newManifest(paths))
+}
+
+// mergeManifestPaths returns the sorted union of the paths written this time
+// and the paths in the previous manifest.
+func mergeManifestPaths(paths []string, previousManifestPaths []string) []string {
+	return stringutil.MapToSortedSlice(
+		stringutil.SliceToMap(append(append([]string(nil), paths...), previousManifestPaths...)),
+	)
+}
+
+func parseManifest(data []byte) []string {
+	var paths []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			paths = append(paths, line)
+		}
+	}
+	return paths
+}
+
+func newManifest(paths []string) []byte {
+	var builder strings.Builder
+	builder.WriteString(manifestHeader)
+	for _, path := range paths {
+		builder.WriteString(path)
+		builder.WriteString("\n")
+	}
+	return []byte(builder.String())
+}
+
+// readFileIfExists reads the file, returning nil if it does not exist.
+func readFileIfExists(filePath string) ([]byte, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// deleteEmptyParentDirs deletes the parent directories of the path within the
+// output directory that are empty.
+func deleteEmptyParentDirs(outDirPath string, path string) {
+	for dirPath := normalpath.Dir(path); dirPath != "."; dirPath = normalpath.Dir(dirPath) {
+		// os.Remove fails for directories that are not empty.
+		if err := os.Remove(filepath.Join(outDirPath, normalpath.Unnormalize(dirPath))); err != nil {
+			return
+		}
+	}
+}
+
 type responseWriterOptions struct {
 	createOutDirIfNotExists bool
+	partialInput            bool
 }
 
 func newResponseWriterOptions() *responseWriterOptions {
 	return &responseWriterOptions{}
 }
+
+type addResponseOptions struct {
+	clean bool
+}
+
+func newAddResponseOptions() *addResponseOptions {
+	return &addResponseOptions{}
+}
+
+// writeArchiveTo writes the files in the bucket to the writer as an archive.
+func writeArchiveTo(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	archiveType archiveType,
+	writer io.Writer,
+) (retErr error) {
+	switch archiveType {
+	case archiveTypeZip:
+		// protoc does not compress.
+		return storagearchive.Zip(ctx, readBucket, writer, false)
+	case archiveTypeJar:
+		// The manifest has to be the first entry of a jar, otherwise it is
+		// not found by java.util.jar.JarInputStream. A manifest written by
+		// the plugin takes precedence over the default manifest.
+		manifestReadWriteBucket := storagemem.NewReadWriteBucket()
+		data, err := storage.ReadPath(ctx, readBucket, jarManifestPath)
+		if err != nil {
+			if !storage.IsNotExist(err) {
+				return err
+			}
+			data = jarManifestContent
+		}
+		if err := storage.PutPath(ctx, manifestReadWriteBucket, jarManifestPath, data); err != nil {
+			return err
+		}
+		return storagearchive.Zip(
+			ctx,
+			storage.MultiReadBucketSkipMultipleLocations(manifestReadWriteBucket, readBucket),
+			writer,
+			false,
+		)
+	case archiveTypeTar:
+		return storagearchive.Tar(ctx, readBucket, writer)
+	case archiveTypeTarGz:
+		gzipWriter := gzip.NewWriter(writer)
+		defer func() {
+			retErr = multierr.Append(retErr, gzipWriter.Close())
+		}()
+		return storagearchive.Tar(ctx, readBucket, gzipWriter)
+	default:
+		return fmt.Errorf("unknown archive type: %v", archiveType)
+	}
+}
+
+// getArchiveType returns the archiveType for the plugin out.
+//
+// Returns false if the plugin out is a directory.
+func getArchiveType(pluginOut string) (archiveType, bool) {
+	switch {
+	case strings.HasSuffix(pluginOut, ".zip"):
+		return archiveTypeZip, true
+	case strings.HasSuffix(pluginOut, ".jar"):
+		return archiveTypeJar, true
+	case strings.HasSuffix(pluginOut, ".tar"):
+		return archiveTypeTar, true
+	case strings.HasSuffix(pluginOut, ".tar.gz"):
+		return archiveTypeTarGz, true
+	default:
+		return 0, false
+	}
+}
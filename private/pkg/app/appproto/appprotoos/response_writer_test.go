@@ -0,0 +1,239 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appprotoos
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagearchive"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestResponseWriterClean(t *testing.T) {
+	t.Parallel()
+	outDirPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outDirPath, "handwritten.txt"), []byte("handwritten"), 0600))
+	testWriteResponse(t, outDirPath, true, "a/a.txt", "b/b.txt")
+	testAssertFilesExist(t, outDirPath, "a/a.txt", "b/b.txt", "handwritten.txt", ManifestFilePath)
+	testWriteResponse(t, outDirPath, true, "a/a.txt", "c/c.txt")
+	testAssertFilesExist(t, outDirPath, "a/a.txt", "c/c.txt", "handwritten.txt")
+	testAssertFilesNotExist(t, outDirPath, "b/b.txt", "b")
+	manifestData, err := os.ReadFile(filepath.Join(outDirPath, ManifestFilePath))
+	require.NoError(t, err)
+	assert.Equal(t, manifestHeader+"a/a.txt\nc/c.txt\n", string(manifestData))
+}
+
+func TestResponseWriterCleanPartialInput(t *testing.T) {
+	t.Parallel()
+	outDirPath := t.TempDir()
+	testWriteResponse(t, outDirPath, true, "a/a.txt", "b/b.txt")
+	// Only a is generated, as with --path, so b must not be deleted.
+	responseWriter := NewResponseWriter(zap.NewNop(), storageos.NewProvider(), ResponseWriterWithPartialInput())
+	require.NoError(t, responseWriter.AddResponse(context.Background(), testNewResponse("a/a.txt"), outDirPath, AddResponseWithClean()))
+	changedPaths, err := responseWriter.Diff(context.Background(), command.NewRunner(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, changedPaths)
+	responseWriter = NewResponseWriter(zap.NewNop(), storageos.NewProvider(), ResponseWriterWithPartialInput())
+	require.NoError(t, responseWriter.AddResponse(context.Background(), testNewResponse("a/a.txt", "c/c.txt"), outDirPath, AddResponseWithClean()))
+	require.NoError(t, responseWriter.Close())
+	testAssertFilesExist(t, outDirPath, "a/a.txt", "b/b.txt", "c/c.txt")
+	manifestData, err := os.ReadFile(filepath.Join(outDirPath, ManifestFilePath))
+	require.NoError(t, err)
+	assert.Equal(t, manifestHeader+"a/a.txt\nb/b.txt\nc/c.txt\n", string(manifestData))
+	// The next run for all of the input deletes the files that are no longer generated.
+	testWriteResponse(t, outDirPath, true, "a/a.txt")
+	testAssertFilesExist(t, outDirPath, "a/a.txt")
+	testAssertFilesNotExist(t, outDirPath, "b/b.txt", "c/c.txt")
+}
+
+func TestResponseWriterNoClean(t *testing.T) {
+	t.Parallel()
+	outDirPath := t.TempDir()
+	testWriteResponse(t, outDirPath, false, "a/a.txt", "b/b.txt")
+	testWriteResponse(t, outDirPath, false, "a/a.txt")
+	testAssertFilesExist(t, outDirPath, "a/a.txt", "b/b.txt")
+	testAssertFilesNotExist(t, outDirPath, ManifestFilePath)
+}
+
+func TestResponseWriterCleanInsertionPoint(t *testing.T) {
+	t.Parallel()
+	outDirPath := t.TempDir()
+	testWriteResponse(t, outDirPath, true, "a.txt", "b.txt")
+	// The second response inserts into a.txt, which must not be deleted.
+	responseWriter := NewResponseWriter(zap.NewNop(), storageos.NewProvider())
+	require.NoError(
+		t,
+		responseWriter.AddResponse(
+			context.Background(),
+			testNewResponse("a.txt"),
+			outDirPath,
+			AddResponseWithClean(),
+		),
+	)
+	require.NoError(
+		t,
+		responseWriter.AddResponse(
+			context.Background(),
+			&pluginpb.CodeGeneratorResponse{
+				File: []*pluginpb.CodeGeneratorResponse_File{
+					{
+						Name:           proto.String("a.txt"),
+						InsertionPoint: proto.String("point"),
+						Content:        proto.String("inserted"),
+					},
+				},
+			},
+			outDirPath,
+		),
+	)
+	require.NoError(t, responseWriter.Close())
+	testAssertFilesExist(t, outDirPath, "a.txt")
+	testAssertFilesNotExist(t, outDirPath, "b.txt")
+}
+
+func TestResponseWriterDiff(t *testing.T) {
+	t.Parallel()
+	outDirPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outDirPath, "handwritten.txt"), []byte("handwritten"), 0600))
+	testWriteResponse(t, outDirPath, true, "a.txt", "b.txt")
+	// Nothing changed.
+	responseWriter := NewResponseWriter(zap.NewNop(), storageos.NewProvider())
+	require.NoError(t, responseWriter.AddResponse(context.Background(), testNewResponse("a.txt", "b.txt"), outDirPath, AddResponseWithClean()))
+	changedPaths, err := responseWriter.Diff(context.Background(), command.NewRunner(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, changedPaths)
+	// b.txt is deleted, c.txt is added, and the manifest changes.
+	responseWriter = NewResponseWriter(zap.NewNop(), storageos.NewProvider())
+	require.NoError(t, responseWriter.AddResponse(context.Background(), testNewResponse("a.txt", "c.txt"), outDirPath, AddResponseWithClean()))
+	diffBuffer := bytes.NewBuffer(nil)
+	changedPaths, err = responseWriter.Diff(context.Background(), command.NewRunner(), diffBuffer)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			filepath.Join(outDirPath, ManifestFilePath),
+			filepath.Join(outDirPath, "b.txt"),
+			filepath.Join(outDirPath, "c.txt"),
+		},
+		changedPaths,
+	)
+	assert.Contains(t, diffBuffer.String(), "+c.txt")
+	assert.Contains(t, diffBuffer.String(), "-b.txt")
+	// Nothing was written.
+	testAssertFilesExist(t, outDirPath, "a.txt", "b.txt", "handwritten.txt")
+	testAssertFilesNotExist(t, outDirPath, "c.txt")
+}
+
+func TestResponseWriterJar(t *testing.T) {
+	t.Parallel()
+	outFilePath := filepath.Join(t.TempDir(), "out.jar")
+	responseWriter := NewResponseWriter(zap.NewNop(), storageos.NewProvider())
+	require.NoError(t, responseWriter.AddResponse(context.Background(), testNewResponse("com/a.java", "com/b.java"), outFilePath))
+	require.NoError(t, responseWriter.Close())
+	zipReader, err := zip.OpenReader(outFilePath)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, zipReader.Close())
+	}()
+	var names []string
+	for _, file := range zipReader.File {
+		names = append(names, file.Name)
+	}
+	// The manifest must be the first entry.
+	assert.Equal(t, []string{"META-INF/MANIFEST.MF", "com/a.java", "com/b.java"}, names)
+}
+
+func TestResponseWriterTarGz(t *testing.T) {
+	t.Parallel()
+	outFilePath := filepath.Join(t.TempDir(), "out.tar.gz")
+	responseWriter := NewResponseWriter(zap.NewNop(), storageos.NewProvider())
+	require.NoError(t, responseWriter.AddResponse(context.Background(), testNewResponse("a/a.txt", "b.txt"), outFilePath))
+	require.NoError(t, responseWriter.Close())
+	file, err := os.Open(outFilePath)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, file.Close())
+	}()
+	gzipReader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	require.NoError(t, storagearchive.Untar(context.Background(), gzipReader, readWriteBucket, nil, 0))
+	data, err := storage.ReadPath(context.Background(), readWriteBucket, "a/a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "// @@protoc_insertion_point(point)\n", string(data))
+	exists, err := storage.Exists(context.Background(), readWriteBucket, "b.txt")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func testWriteResponse(t *testing.T, outDirPath string, clean bool, names ...string) {
+	var options []AddResponseOption
+	if clean {
+		options = append(options, AddResponseWithClean())
+	}
+	responseWriter := NewResponseWriter(zap.NewNop(), storageos.NewProvider())
+	require.NoError(
+		t,
+		responseWriter.AddResponse(
+			context.Background(),
+			testNewResponse(names...),
+			outDirPath,
+			options...,
+		),
+	)
+	require.NoError(t, responseWriter.Close())
+}
+
+func testNewResponse(names ...string) *pluginpb.CodeGeneratorResponse {
+	response := &pluginpb.CodeGeneratorResponse{}
+	for _, name := range names {
+		response.File = append(
+			response.File,
+			&pluginpb.CodeGeneratorResponse_File{
+				Name:    proto.String(name),
+				Content: proto.String("// @@protoc_insertion_point(point)\n"),
+			},
+		)
+	}
+	return response
+}
+
+func testAssertFilesExist(t *testing.T, outDirPath string, paths ...string) {
+	for _, path := range paths {
+		_, err := os.Stat(filepath.Join(outDirPath, filepath.FromSlash(path)))
+		assert.NoError(t, err, path)
+	}
+}
+
+func testAssertFilesNotExist(t *testing.T, outDirPath string, paths ...string) {
+	for _, path := range paths {
+		_, err := os.Stat(filepath.Join(outDirPath, filepath.FromSlash(path)))
+		assert.True(t, os.IsNotExist(err), path)
+	}
+}
@@ -37,7 +37,15 @@ import (
 // being cloned).
 // We can fetch directly from an origin URL, but without any remote set git LFS
 // will fail to fetch so we need to pick something.
-const bufCloneOrigin = "bufCloneOrigin"
+const (
+	bufCloneOrigin = "bufCloneOrigin"
+	// bufCloneMergeBaseHeadRef is the local ref that HEAD is fetched to
+	// when resolving a merge base.
+	bufCloneMergeBaseHeadRef = "refs/" + bufCloneOrigin + "/head"
+	// bufCloneMergeBaseBranchRef is the local ref that the merge base branch
+	// is fetched to when resolving a merge base.
+	bufCloneMergeBaseBranchRef = "refs/" + bufCloneOrigin + "/branch"
+)
 
 type cloner struct {
 	logger            *zap.Logger
@@ -143,15 +151,15 @@ func (c *cloner) CloneToBucket(
 		}
 		gitConfigAuthArgs = append(gitConfigAuthArgs, extraArgs...)
 	}
-	fetchRef, worktreeRef, checkoutRef := getRefspecsForName(options.Name)
+	fetchRefs, worktreeRef, checkoutRef := getRefspecsForName(options.Name)
 	fetchArgs := append(
 		gitConfigAuthArgs,
 		"--git-dir="+bareDir.AbsPath(),
 		"fetch",
 		"--depth", depthArg,
 		bufCloneOrigin,
-		fetchRef,
 	)
+	fetchArgs = append(fetchArgs, fetchRefs...)
 
 	if strings.HasPrefix(url, "ssh://") {
 		envContainer, err = c.getEnvContainerWithGitSSHCommand(envContainer)
@@ -170,6 +178,13 @@ func (c *cloner) CloneToBucket(
 		return newGitCommandError(err, buffer, bareDir)
 	}
 
+	if mergeBaseBranch := getMergeBaseBranchForName(options.Name); mergeBaseBranch != "" {
+		worktreeRef, err = c.getMergeBase(ctx, envContainer, bareDir, mergeBaseBranch)
+		if err != nil {
+			return err
+		}
+	}
+
 	buffer.Reset()
 	args := append(
 		gitConfigAuthArgs,
@@ -336,12 +351,67 @@ func getSSHKnownHostsFilePaths(sshKnownHostsFiles string) []string {
 	return filePaths
 }
 
+// getMergeBase returns the merge base of the fetched HEAD and the fetched branch.
+//
+// Both must have been fetched to bufCloneMergeBaseHeadRef and bufCloneMergeBaseBranchRef.
+func (c *cloner) getMergeBase(
+	ctx context.Context,
+	envContainer app.EnvContainer,
+	bareDir tmp.Dir,
+	branch string,
+) (string, error) {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	if err := c.runner.Run(
+		ctx,
+		"git",
+		command.RunWithArgs(
+			"--git-dir="+bareDir.AbsPath(),
+			"merge-base",
+			bufCloneMergeBaseHeadRef,
+			bufCloneMergeBaseBranchRef,
+		),
+		command.RunWithEnv(app.EnvironMap(envContainer)),
+		command.RunWithStdout(stdout),
+		command.RunWithStderr(stderr),
+	); err != nil {
+		if stderr.Len() == 0 {
+			// git merge-base exits with a non-zero code and no output if
+			// no merge base is found, which is usually due to a shallow fetch.
+			return "", fmt.Errorf("could not find the merge base of HEAD and %q, the depth may need to be increased", branch)
+		}
+		return "", newGitCommandError(err, stderr, bareDir)
+	}
+	mergeBase := strings.TrimSpace(stdout.String())
+	if mergeBase == "" {
+		return "", fmt.Errorf("could not find the merge base of HEAD and %q", branch)
+	}
+	c.logger.Debug("git_merge_base", zap.String("branch", branch), zap.String("merge_base", mergeBase))
+	return mergeBase, nil
+}
+
+func getMergeBaseBranchForName(gitName Name) string {
+	if gitName == nil {
+		return ""
+	}
+	return gitName.mergeBaseBranch()
+}
+
 // getRefspecsForName decides the refspecs to use in the subsequent git fetch,
 // git worktree add and git checkout. When checkoutRefspec is empty, Name
 // explicitly refer to a named ref and the checkout isn't a necessary step.
-func getRefspecsForName(gitName Name) (fetchRefSpec string, worktreeRefSpec string, checkoutRefspec string) {
+func getRefspecsForName(gitName Name) (fetchRefSpecs []string, worktreeRefSpec string, checkoutRefspec string) {
 	if gitName == nil {
-		return "HEAD", "FETCH_HEAD", ""
+		return []string{"HEAD"}, "FETCH_HEAD", ""
+	}
+	if gitName.mergeBaseBranch() != "" {
+		// Fetch both HEAD and the branch to local refs so that the merge base
+		// can be computed after the fetch. The worktree refspec is the merge
+		// base itself, which is resolved by the caller.
+		return []string{
+			"HEAD:" + bufCloneMergeBaseHeadRef,
+			gitName.mergeBaseBranch() + ":" + bufCloneMergeBaseBranchRef,
+		}, "", ""
 	}
 	if gitName.cloneBranch() != "" && gitName.checkout() != "" {
 		// When doing branch/tag clones, make sure we use a
@@ -350,9 +420,9 @@ func getRefspecsForName(gitName Name) (fetchRefSpec string, worktreeRefSpec stri
 		// for example:
 		//   branch=origin/main,ref=origin/main~1
 		fetchRefSpec := gitName.cloneBranch() + ":" + gitName.cloneBranch()
-		return fetchRefSpec, "FETCH_HEAD", gitName.checkout()
+		return []string{fetchRefSpec}, "FETCH_HEAD", gitName.checkout()
 	} else if gitName.cloneBranch() != "" {
-		return gitName.cloneBranch(), "FETCH_HEAD", ""
+		return []string{gitName.cloneBranch()}, "FETCH_HEAD", ""
 	} else if gitName.checkout() != "" {
 		// After fetch we won't have checked out any refs. This
 		// will cause `refs=` containing "HEAD" to fail, as HEAD
@@ -360,9 +430,9 @@ func getRefspecsForName(gitName Name) (fetchRefSpec string, worktreeRefSpec stri
 		// instead refers to the current commit checked out. By
 		// checking out "FETCH_HEAD" before checking out the
 		// user supplied ref, we behave similarly to git clone.
-		return "HEAD", "FETCH_HEAD", gitName.checkout()
+		return []string{"HEAD"}, "FETCH_HEAD", gitName.checkout()
 	} else {
-		return "HEAD", "FETCH_HEAD", ""
+		return []string{"HEAD"}, "FETCH_HEAD", ""
 	}
 }
 
@@ -30,6 +30,9 @@ type Name interface {
 	cloneBranch() string
 	// If checkout returns a non-empty string, a checkout of the value will be performed after cloning.
 	checkout() string
+	// If mergeBaseBranch returns a non-empty string, the merge base of HEAD and the value
+	// will be checked out after cloning.
+	mergeBaseBranch() string
 }
 
 // NewBranchName returns a new Name for the branch.
@@ -52,6 +55,13 @@ func NewRefNameWithBranch(ref string, branch string) Name {
 	return newRefWithBranch(ref, branch)
 }
 
+// NewMergeBaseName returns a new Name for the merge base of HEAD and the given branch.
+//
+// This is the equivalent of checking out the result of "git merge-base HEAD branch".
+func NewMergeBaseName(branch string) Name {
+	return newMergeBase(branch)
+}
+
 // Cloner clones git repositories to buckets.
 type Cloner interface {
 	// CloneToBucket clones the repository to the bucket.
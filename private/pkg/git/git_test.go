@@ -145,6 +145,39 @@ func TestGitCloner(t *testing.T) {
 		assert.True(t, storage.IsNotExist(err))
 	})
 
+	t.Run("HEAD~1", func(t *testing.T) {
+		t.Parallel()
+		readBucket := readBucketForName(ctx, t, runner, workDir, 50, NewRefName("HEAD~1"), false)
+
+		content, err := storage.ReadPath(ctx, readBucket, "test.proto")
+		require.NoError(t, err)
+		assert.Equal(t, "// commit 1", string(content))
+		_, err = readBucket.Stat(ctx, "nonexistent")
+		assert.True(t, storage.IsNotExist(err))
+	})
+
+	t.Run("merge-base_origin/main", func(t *testing.T) {
+		t.Parallel()
+		readBucket := readBucketForName(ctx, t, runner, workDir, 50, NewMergeBaseName("origin/main"), false)
+
+		content, err := storage.ReadPath(ctx, readBucket, "test.proto")
+		require.NoError(t, err)
+		assert.Equal(t, "// commit 1", string(content), "expected the fork point of local-branch and origin/main to be checked out")
+		_, err = readBucket.Stat(ctx, "nonexistent")
+		assert.True(t, storage.IsNotExist(err))
+	})
+
+	t.Run("merge-base_main", func(t *testing.T) {
+		t.Parallel()
+		readBucket := readBucketForName(ctx, t, runner, workDir, 50, NewMergeBaseName("main"), false)
+
+		content, err := storage.ReadPath(ctx, readBucket, "test.proto")
+		require.NoError(t, err)
+		assert.Equal(t, "// commit 1", string(content))
+		_, err = readBucket.Stat(ctx, "nonexistent")
+		assert.True(t, storage.IsNotExist(err))
+	})
+
 	t.Run("commit-local", func(t *testing.T) {
 		t.Parallel()
 		revParseBytes, err := command.RunStdout(ctx, container, runner, "git", "-C", workDir, "rev-parse", "HEAD~")
@@ -0,0 +1,48 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+type mergeBase struct {
+	branch string
+}
+
+func newMergeBase(branch string) *mergeBase {
+	return &mergeBase{
+		branch: branch,
+	}
+}
+
+func (r *mergeBase) cloneBranch() string {
+	return ""
+}
+
+func (r *mergeBase) checkout() string {
+	return ""
+}
+
+func (r *mergeBase) mergeBaseBranch() string {
+	if r == nil {
+		return ""
+	}
+	return r.branch
+}
+
+func (r *mergeBase) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + r.mergeBaseBranch() + `"`), nil
+}
+
+func (r *mergeBase) String() string {
+	return r.mergeBaseBranch()
+}
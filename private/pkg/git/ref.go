@@ -35,6 +35,10 @@ func (r *ref) checkout() string {
 	return r.ref
 }
 
+func (r *ref) mergeBaseBranch() string {
+	return ""
+}
+
 // Used for logging
 func (r *ref) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + r.checkout() + `"`), nil
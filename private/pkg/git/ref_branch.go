@@ -42,6 +42,10 @@ func (r *refWithBranch) checkout() string {
 	return r.ref
 }
 
+func (r *refWithBranch) mergeBaseBranch() string {
+	return ""
+}
+
 // Used for logging
 func (r *refWithBranch) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
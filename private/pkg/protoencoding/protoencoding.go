@@ -74,6 +74,14 @@ func NewJSONMarshalerUseProtoNames(resolver Resolver) Marshaler {
 	return newJSONMarshaler(resolver, "", true)
 }
 
+// NewTxtMarshaler returns a new Marshaler for the protobuf text format.
+//
+// This has the potential to be unstable over time.
+// resolver can be nil if unknown and are only needed for extensions.
+func NewTxtMarshaler(resolver Resolver) Marshaler {
+	return newTxtMarshaler(resolver)
+}
+
 // Unmarshaler unmarshals Messages.
 type Unmarshaler interface {
 	Unmarshal(data []byte, message proto.Message) error
@@ -92,3 +100,10 @@ func NewWireUnmarshaler(resolver Resolver) Unmarshaler {
 func NewJSONUnmarshaler(resolver Resolver) Unmarshaler {
 	return newJSONUnmarshaler(resolver)
 }
+
+// NewTxtUnmarshaler returns a new Unmarshaler for the protobuf text format.
+//
+// resolver can be nil if unknown and are only needed for extensions.
+func NewTxtUnmarshaler(resolver Resolver) Unmarshaler {
+	return newTxtUnmarshaler(resolver)
+}
@@ -0,0 +1,42 @@
+// Copyright 2020-2022 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protoencoding
+
+import (
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+type txtMarshaler struct {
+	resolver Resolver
+}
+
+func newTxtMarshaler(resolver Resolver) Marshaler {
+	return &txtMarshaler{
+		resolver: resolver,
+	}
+}
+
+func (m *txtMarshaler) Marshal(message proto.Message) ([]byte, error) {
+	if err := reparseUnrecognized(m.resolver, message.ProtoReflect()); err != nil {
+		return nil, err
+	}
+	options := prototext.MarshalOptions{
+		Multiline: true,
+		Indent:    "  ",
+		Resolver:  m.resolver,
+	}
+	return options.Marshal(message)
+}
@@ -24,6 +24,7 @@ type enum struct {
 	reservedEnumRanges []EnumRange
 	reservedNames      []ReservedName
 	parent             Message
+	deprecated         bool
 }
 
 func newEnum(
@@ -32,6 +33,7 @@ func newEnum(
 	allowAlias bool,
 	allowAliasPath []int32,
 	parent Message,
+	deprecated bool,
 ) *enum {
 	return &enum{
 		namedDescriptor:           namedDescriptor,
@@ -39,6 +41,7 @@ func newEnum(
 		allowAlias:                allowAlias,
 		allowAliasPath:            allowAliasPath,
 		parent:                    parent,
+		deprecated:                deprecated,
 	}
 }
 
@@ -54,6 +57,10 @@ func (e *enum) AllowAliasLocation() Location {
 	return e.getLocation(e.allowAliasPath)
 }
 
+func (e *enum) Deprecated() bool {
+	return e.deprecated
+}
+
 func (e *enum) ReservedEnumRanges() []EnumRange {
 	return e.reservedEnumRanges
 }
@@ -20,6 +20,7 @@ type enumValue struct {
 
 	enum       Enum
 	number     int
+	deprecated bool
 	numberPath []int32
 }
 
@@ -28,6 +29,7 @@ func newEnumValue(
 	optionExtensionDescriptor optionExtensionDescriptor,
 	enum Enum,
 	number int,
+	deprecated bool,
 	numberPath []int32,
 ) *enumValue {
 	return &enumValue{
@@ -35,6 +37,7 @@ func newEnumValue(
 		optionExtensionDescriptor: optionExtensionDescriptor,
 		enum:                      enum,
 		number:                    number,
+		deprecated:                deprecated,
 		numberPath:                numberPath,
 	}
 }
@@ -47,6 +50,10 @@ func (e *enumValue) Number() int {
 	return e.number
 }
 
+func (e *enumValue) Deprecated() bool {
+	return e.deprecated
+}
+
 func (e *enumValue) NumberLocation() Location {
 	return e.getLocation(e.numberPath)
 }
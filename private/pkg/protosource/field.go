@@ -33,6 +33,7 @@ type field struct {
 	jsType         FieldOptionsJSType
 	cType          FieldOptionsCType
 	packed         *bool
+	deprecated     bool
 	numberPath     []int32
 	typePath       []int32
 	typeNamePath   []int32
@@ -58,6 +59,7 @@ func newField(
 	jsType FieldOptionsJSType,
 	cType FieldOptionsCType,
 	packed *bool,
+	deprecated bool,
 	numberPath []int32,
 	typePath []int32,
 	typeNamePath []int32,
@@ -82,6 +84,7 @@ func newField(
 		jsType:                    jsType,
 		cType:                     cType,
 		packed:                    packed,
+		deprecated:                deprecated,
 		numberPath:                numberPath,
 		typePath:                  typePath,
 		typeNamePath:              typeNamePath,
@@ -146,6 +149,10 @@ func (f *field) Packed() *bool {
 	return f.packed
 }
 
+func (f *field) Deprecated() bool {
+	return f.deprecated
+}
+
 func (f *field) NumberLocation() Location {
 	return f.getLocation(f.numberPath)
 }
@@ -137,6 +137,10 @@ func (f *file) CcEnableArenas() bool {
 	return f.fileDescriptor.GetOptions().GetCcEnableArenas()
 }
 
+func (f *file) Deprecated() bool {
+	return f.fileDescriptor.GetOptions().GetDeprecated()
+}
+
 func (f *file) PackageLocation() Location {
 	return f.getLocationByPathKey(packagePathKey)
 }
@@ -373,6 +377,7 @@ func (f *file) populateEnum(
 		enumDescriptorProto.GetOptions().GetAllowAlias(),
 		getEnumAllowAliasPath(enumIndex, nestedMessageIndexes...),
 		parent,
+		enumDescriptorProto.GetOptions().GetDeprecated(),
 	)
 
 	for enumValueIndex, enumValueDescriptorProto := range enumDescriptorProto.GetValue() {
@@ -395,6 +400,7 @@ func (f *file) populateEnum(
 			),
 			enum,
 			int(enumValueDescriptorProto.GetNumber()),
+			enumValueDescriptorProto.GetOptions().GetDeprecated(),
 			getEnumValueNumberPath(enumIndex, enumValueIndex, nestedMessageIndexes...),
 		)
 		enum.addValue(enumValue)
@@ -461,6 +467,7 @@ func (f *file) populateMessage(
 		descriptorProto.GetOptions().GetMapEntry(),
 		descriptorProto.GetOptions().GetMessageSetWireFormat(),
 		descriptorProto.GetOptions().GetNoStandardDescriptorAccessor(),
+		descriptorProto.GetOptions().GetDeprecated(),
 		getMessageMessageSetWireFormatPath(topLevelMessageIndex, nestedMessageIndexes...),
 		getMessageNoStandardDescriptorAccessorPath(topLevelMessageIndex, nestedMessageIndexes...),
 	)
@@ -548,6 +555,7 @@ func (f *file) populateMessage(
 			jsType,
 			cType,
 			packed,
+			fieldDescriptorProto.GetOptions().GetDeprecated(),
 			getMessageFieldNumberPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageFieldTypePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageFieldTypeNamePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
@@ -621,6 +629,7 @@ func (f *file) populateMessage(
 			jsType,
 			cType,
 			packed,
+			fieldDescriptorProto.GetOptions().GetDeprecated(),
 			getMessageExtensionNumberPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageExtensionTypePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageExtensionTypeNamePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
@@ -730,6 +739,7 @@ func (f *file) populateService(
 		newOptionExtensionDescriptor(
 			serviceDescriptorProto.GetOptions(),
 		),
+		serviceDescriptorProto.GetOptions().GetDeprecated(),
 	)
 	for methodIndex, methodDescriptorProto := range serviceDescriptorProto.GetMethod() {
 		methodNamedDescriptor, err := newNamedDescriptor(
@@ -758,6 +768,7 @@ func (f *file) populateService(
 			strings.TrimPrefix(methodDescriptorProto.GetOutputType(), "."),
 			methodDescriptorProto.GetClientStreaming(),
 			methodDescriptorProto.GetServerStreaming(),
+			methodDescriptorProto.GetOptions().GetDeprecated(),
 			getMethodInputTypePath(serviceIndex, methodIndex),
 			getMethodOutputTypePath(serviceIndex, methodIndex),
 			idempotencyLevel,
@@ -824,6 +835,7 @@ func (f *file) populateExtension(
 		jsType,
 		cType,
 		packed,
+		fieldDescriptorProto.GetOptions().GetDeprecated(),
 		getFileExtensionNumberPath(fieldIndex),
 		getFileExtensionTypePath(fieldIndex),
 		getFileExtensionTypeNamePath(fieldIndex),
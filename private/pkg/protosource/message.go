@@ -30,6 +30,7 @@ type message struct {
 	isMapEntry                       bool
 	messageSetWireFormat             bool
 	noStandardDescriptorAccessor     bool
+	deprecated                       bool
 	messageSetWireFormatPath         []int32
 	noStandardDescriptorAccessorPath []int32
 }
@@ -41,6 +42,7 @@ func newMessage(
 	isMapEntry bool,
 	messageSetWireFormat bool,
 	noStandardDescriptorAccessor bool,
+	deprecated bool,
 	messageSetWireFormatPath []int32,
 	noStandardDescriptorAccessorPath []int32,
 ) *message {
@@ -51,6 +53,7 @@ func newMessage(
 		isMapEntry:                       isMapEntry,
 		messageSetWireFormat:             messageSetWireFormat,
 		noStandardDescriptorAccessor:     noStandardDescriptorAccessor,
+		deprecated:                       deprecated,
 		messageSetWireFormatPath:         messageSetWireFormatPath,
 		noStandardDescriptorAccessorPath: noStandardDescriptorAccessorPath,
 	}
@@ -112,6 +115,10 @@ func (m *message) NoStandardDescriptorAccessor() bool {
 	return m.noStandardDescriptorAccessor
 }
 
+func (m *message) Deprecated() bool {
+	return m.deprecated
+}
+
 func (m *message) MessageSetWireFormatLocation() Location {
 	return m.getLocation(m.messageSetWireFormatPath)
 }
@@ -25,6 +25,7 @@ type method struct {
 	outputTypeName       string
 	clientStreaming      bool
 	serverStreaming      bool
+	deprecated           bool
 	inputTypePath        []int32
 	outputTypePath       []int32
 	idempotencyLevel     MethodOptionsIdempotencyLevel
@@ -39,6 +40,7 @@ func newMethod(
 	outputTypeName string,
 	clientStreaming bool,
 	serverStreaming bool,
+	deprecated bool,
 	inputTypePath []int32,
 	outputTypePath []int32,
 	idempotencyLevel MethodOptionsIdempotencyLevel,
@@ -58,6 +60,7 @@ func newMethod(
 		outputTypeName:            outputTypeName,
 		clientStreaming:           clientStreaming,
 		serverStreaming:           serverStreaming,
+		deprecated:                deprecated,
 		inputTypePath:             inputTypePath,
 		outputTypePath:            outputTypePath,
 		idempotencyLevel:          idempotencyLevel,
@@ -85,6 +88,10 @@ func (m *method) ServerStreaming() bool {
 	return m.serverStreaming
 }
 
+func (m *method) Deprecated() bool {
+	return m.deprecated
+}
+
 func (m *method) InputTypeLocation() Location {
 	return m.getLocation(m.inputTypePath)
 }
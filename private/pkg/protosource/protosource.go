@@ -224,6 +224,7 @@ type File interface {
 	PyGenericServices() bool
 	PhpGenericServices() bool
 	CcEnableArenas() bool
+	Deprecated() bool
 
 	SyntaxLocation() Location
 	PackageLocation() Location
@@ -309,6 +310,7 @@ type Enum interface {
 
 	AllowAlias() bool
 	AllowAliasLocation() Location
+	Deprecated() bool
 
 	// Will return nil if this is a top-level Enum
 	Parent() Message
@@ -321,6 +323,7 @@ type EnumValue interface {
 
 	Enum() Enum
 	Number() int
+	Deprecated() bool
 
 	NumberLocation() Location
 }
@@ -346,6 +349,7 @@ type Message interface {
 
 	MessageSetWireFormat() bool
 	NoStandardDescriptorAccessor() bool
+	Deprecated() bool
 	MessageSetWireFormatLocation() Location
 	NoStandardDescriptorAccessorLocation() Location
 }
@@ -372,6 +376,7 @@ type Field interface {
 	Packed() *bool
 	// Empty string unless the field is part of an extension
 	Extendee() string
+	Deprecated() bool
 
 	NumberLocation() Location
 	TypeLocation() Location
@@ -398,6 +403,7 @@ type Service interface {
 	OptionExtensionDescriptor
 
 	Methods() []Method
+	Deprecated() bool
 }
 
 // Method is a method descriptor.
@@ -410,6 +416,7 @@ type Method interface {
 	OutputTypeName() string
 	ClientStreaming() bool
 	ServerStreaming() bool
+	Deprecated() bool
 	InputTypeLocation() Location
 	OutputTypeLocation() Location
 
@@ -524,6 +531,230 @@ func ForEachMessage(f func(Message) error, containerDescriptor ContainerDescript
 	return nil
 }
 
+// ForEachFilePair calls f on each pair of Files in the previous Files and the Files
+// with the same path.
+//
+// Files only in the previous Files are passed with a nil File, and Files only in
+// the Files are passed with a nil previous File.
+//
+// Returns error and stops iterating if f returns error.
+// Returns error if file paths are not unique.
+func ForEachFilePair(f func(previousFile File, file File) error, previousFiles []File, files []File) error {
+	previousFilePathToFile, err := FilePathToFile(previousFiles...)
+	if err != nil {
+		return err
+	}
+	filePathToFile, err := FilePathToFile(files...)
+	if err != nil {
+		return err
+	}
+	for previousFilePath, previousFile := range previousFilePathToFile {
+		if err := f(previousFile, filePathToFile[previousFilePath]); err != nil {
+			return err
+		}
+	}
+	for filePath, file := range filePathToFile {
+		if _, ok := previousFilePathToFile[filePath]; !ok {
+			if err := f(nil, file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ForEachEnumPair calls f on each pair of Enums in the previous Files and the Files
+// with the same full name.
+//
+// Enums only in the previous Files are passed with a nil Enum, and Enums only in
+// the Files are passed with a nil previous Enum.
+//
+// Returns error and stops iterating if f returns error.
+// Returns error if Enums do not have unique full names within the Files.
+func ForEachEnumPair(f func(previousEnum Enum, enum Enum) error, previousFiles []File, files []File) error {
+	previousFullNameToEnum, err := FullNameToEnum(previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToEnum, err := FullNameToEnum(files...)
+	if err != nil {
+		return err
+	}
+	for previousFullName, previousEnum := range previousFullNameToEnum {
+		if err := f(previousEnum, fullNameToEnum[previousFullName]); err != nil {
+			return err
+		}
+	}
+	for fullName, enum := range fullNameToEnum {
+		if _, ok := previousFullNameToEnum[fullName]; !ok {
+			if err := f(nil, enum); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ForEachEnumValuePair calls f on each pair of EnumValues in the previous Enum and
+// the Enum with the same name.
+//
+// EnumValues only in the previous Enum are passed with a nil EnumValue, and EnumValues
+// only in the Enum are passed with a nil previous EnumValue.
+//
+// Returns error and stops iterating if f returns error.
+// Returns error if EnumValues do not have unique names within the Enums.
+func ForEachEnumValuePair(f func(previousEnumValue EnumValue, enumValue EnumValue) error, previousEnum Enum, enum Enum) error {
+	previousNameToEnumValue, err := NameToEnumValue(previousEnum)
+	if err != nil {
+		return err
+	}
+	nameToEnumValue, err := NameToEnumValue(enum)
+	if err != nil {
+		return err
+	}
+	for previousName, previousEnumValue := range previousNameToEnumValue {
+		if err := f(previousEnumValue, nameToEnumValue[previousName]); err != nil {
+			return err
+		}
+	}
+	for name, enumValue := range nameToEnumValue {
+		if _, ok := previousNameToEnumValue[name]; !ok {
+			if err := f(nil, enumValue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ForEachMessagePair calls f on each pair of Messages in the previous Files and the Files
+// with the same full name, including nested Messages.
+//
+// Messages only in the previous Files are passed with a nil Message, and Messages only in
+// the Files are passed with a nil previous Message.
+//
+// Returns error and stops iterating if f returns error.
+// Returns error if Messages do not have unique full names within the Files.
+func ForEachMessagePair(f func(previousMessage Message, message Message) error, previousFiles []File, files []File) error {
+	previousFullNameToMessage, err := FullNameToMessage(previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToMessage, err := FullNameToMessage(files...)
+	if err != nil {
+		return err
+	}
+	for previousFullName, previousMessage := range previousFullNameToMessage {
+		if err := f(previousMessage, fullNameToMessage[previousFullName]); err != nil {
+			return err
+		}
+	}
+	for fullName, message := range fullNameToMessage {
+		if _, ok := previousFullNameToMessage[fullName]; !ok {
+			if err := f(nil, message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ForEachFieldPair calls f on each pair of Fields in the previous Message and
+// the Message with the same number.
+//
+// Fields only in the previous Message are passed with a nil Field, and Fields
+// only in the Message are passed with a nil previous Field.
+//
+// Returns error and stops iterating if f returns error.
+// Returns error if Fields do not have unique numbers within the Messages.
+func ForEachFieldPair(f func(previousField Field, field Field) error, previousMessage Message, message Message) error {
+	previousNumberToField, err := NumberToMessageField(previousMessage)
+	if err != nil {
+		return err
+	}
+	numberToField, err := NumberToMessageField(message)
+	if err != nil {
+		return err
+	}
+	for previousNumber, previousField := range previousNumberToField {
+		if err := f(previousField, numberToField[previousNumber]); err != nil {
+			return err
+		}
+	}
+	for number, field := range numberToField {
+		if _, ok := previousNumberToField[number]; !ok {
+			if err := f(nil, field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ForEachServicePair calls f on each pair of Services in the previous Files and the Files
+// with the same full name.
+//
+// Services only in the previous Files are passed with a nil Service, and Services only in
+// the Files are passed with a nil previous Service.
+//
+// Returns error and stops iterating if f returns error.
+// Returns error if Services do not have unique full names within the Files.
+func ForEachServicePair(f func(previousService Service, service Service) error, previousFiles []File, files []File) error {
+	previousFullNameToService, err := FullNameToService(previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToService, err := FullNameToService(files...)
+	if err != nil {
+		return err
+	}
+	for previousFullName, previousService := range previousFullNameToService {
+		if err := f(previousService, fullNameToService[previousFullName]); err != nil {
+			return err
+		}
+	}
+	for fullName, service := range fullNameToService {
+		if _, ok := previousFullNameToService[fullName]; !ok {
+			if err := f(nil, service); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ForEachMethodPair calls f on each pair of Methods in the previous Service and
+// the Service with the same name.
+//
+// Methods only in the previous Service are passed with a nil Method, and Methods
+// only in the Service are passed with a nil previous Method.
+//
+// Returns error and stops iterating if f returns error.
+// Returns error if Methods do not have unique names within the Services.
+func ForEachMethodPair(f func(previousMethod Method, method Method) error, previousService Service, service Service) error {
+	previousNameToMethod, err := NameToMethod(previousService)
+	if err != nil {
+		return err
+	}
+	nameToMethod, err := NameToMethod(service)
+	if err != nil {
+		return err
+	}
+	for previousName, previousMethod := range previousNameToMethod {
+		if err := f(previousMethod, nameToMethod[previousName]); err != nil {
+			return err
+		}
+	}
+	for name, method := range nameToMethod {
+		if _, ok := previousNameToMethod[name]; !ok {
+			if err := f(nil, method); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // NestedNameToEnum maps the Enums in the ContainerDescriptor to a map from
 // nested name to Enum.
 //
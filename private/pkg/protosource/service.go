@@ -18,16 +18,19 @@ type service struct {
 	namedDescriptor
 	optionExtensionDescriptor
 
-	methods []Method
+	methods    []Method
+	deprecated bool
 }
 
 func newService(
 	namedDescriptor namedDescriptor,
 	optionExtensionDescriptor optionExtensionDescriptor,
+	deprecated bool,
 ) *service {
 	return &service{
 		namedDescriptor:           namedDescriptor,
 		optionExtensionDescriptor: optionExtensionDescriptor,
+		deprecated:                deprecated,
 	}
 }
 
@@ -35,6 +38,10 @@ func (m *service) Methods() []Method {
 	return m.methods
 }
 
+func (m *service) Deprecated() bool {
+	return m.deprecated
+}
+
 func (m *service) addMethod(method Method) {
 	m.methods = append(m.methods, method)
 }
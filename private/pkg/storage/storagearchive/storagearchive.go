@@ -56,8 +56,7 @@ func Tar(
 					Typeflag: tar.TypeReg,
 					Name:     readObject.Path(),
 					Size:     int64(len(data)),
-					// If we ever use this outside of testing, we will want to do something about this
-					Mode: 0644,
+					Mode:     0644,
 				},
 			); err != nil {
 				return err